@@ -0,0 +1,184 @@
+// Package apierror 定义接口统一使用的错误模型。
+//
+// 处理函数只返回领域错误（未找到、参数校验、冲突、存储），由 Write 映射为
+// HTTP 状态码和稳定的 JSON 结构 {code, message, details, request_id, trace_id}。
+// message 按 Accept-Language 选择中文或英文，内部错误（如 SQL 文本）只写日志，
+// 不会出现在响应中，而是与请求 ID 一起写入日志。
+package apierror
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Kind 是错误的领域分类，决定 HTTP 状态码。
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindStorage
+	KindUnauthorized
+	KindForbidden
+	KindTooLarge
+	KindRateLimited
+)
+
+// Status 返回该分类对应的 HTTP 状态码。
+func (k Kind) Status() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error 是携带机器可读错误码的领域错误。
+type Error struct {
+	Kind    Kind
+	Code    string // 稳定的错误码，如 "author_not_found"
+	Details any    // 可公开的补充信息，如出错字段
+	Err     error  // 内部原因，只写日志
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Err)
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// WithDetails 返回附带 details 的副本。
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithCause 返回记录了内部原因的副本，原因只写日志。
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// NotFound 表示请求的资源不存在。
+func NotFound(code string, details any) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Details: details}
+}
+
+// Validation 表示请求参数不合法。
+func Validation(code string, details any) *Error {
+	return &Error{Kind: KindValidation, Code: code, Details: details}
+}
+
+// Conflict 表示请求与现有数据冲突，如名称重复。
+func Conflict(code string, details any) *Error {
+	return &Error{Kind: KindConflict, Code: code, Details: details}
+}
+
+// Unauthorized 表示未认证或凭据无效。
+func Unauthorized(code string, details any) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Details: details}
+}
+
+// Forbidden 表示已认证但没有权限。
+func Forbidden(code string, details any) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Details: details}
+}
+
+// TooLarge 表示请求体超过大小限制。
+func TooLarge(code string, details any) *Error {
+	return &Error{Kind: KindTooLarge, Code: code, Details: details}
+}
+
+// RateLimited 表示请求过于频繁，调用方应同时设置 Retry-After。
+func RateLimited(code string, details any) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Details: details}
+}
+
+// Storage 包装数据库等存储层错误，原始错误不会返回给客户端。
+func Storage(err error) *Error {
+	return &Error{Kind: KindStorage, Code: CodeStorage, Err: err}
+}
+
+// Envelope 是所有错误响应的 JSON 结构。
+type Envelope struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// RequestIDKey 是 gin.Context 中保存请求 ID 的键。
+const RequestIDKey = "request_id"
+
+// TraceIDKey 是 gin.Context 中保存追踪 ID 的键，未启用追踪时为空。
+const TraceIDKey = "trace_id"
+
+// RequestID 返回当前请求的 ID，依次取上下文、X-Request-ID 请求头，都没有时生成一个。
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	id := c.GetHeader("X-Request-ID")
+	if id == "" {
+		id = NewRequestID()
+	}
+	c.Set(RequestIDKey, id)
+	return id
+}
+
+// NewRequestID 生成一个随机的请求 ID。
+func NewRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Write 把 err 转换为错误响应并终止后续处理。非 *Error 的错误按内部错误处理。
+func Write(c *gin.Context, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Kind: KindInternal, Code: CodeInternal, Err: err}
+	}
+
+	requestID := RequestID(c)
+	traceID := c.GetString(TraceIDKey)
+	if e.Err != nil {
+		slog.Error("Request failed", "request_id", requestID, "trace_id", traceID, "method", c.Request.Method,
+			"route", c.FullPath(), "code", e.Code, "error", e.Err)
+	}
+
+	c.AbortWithStatusJSON(e.Kind.Status(), Envelope{
+		Code:      e.Code,
+		Message:   Message(e.Code, Lang(c.GetHeader("Accept-Language"))),
+		Details:   e.Details,
+		RequestID: requestID,
+		TraceID:   traceID,
+	})
+}
@@ -0,0 +1,96 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLang(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", LangZH},
+		{"en", LangEN},
+		{"EN-us", LangEN},
+		{"zh-CN,zh;q=0.9,en;q=0.8", LangZH},
+		{"en-US,en;q=0.9,zh-CN;q=0.8", LangEN},
+		{"fr-FR,fr;q=0.9,en;q=0.8", LangEN},
+		{"zh;q=0.5, en;q=0.8", LangEN},
+		{"en;q=0, zh;q=0.1", LangZH},
+		{"en;q=0", LangZH},
+		{"ja, fr", LangZH},
+		{"*", LangZH},
+		{"en;q=abc", LangEN},
+		{" , en ; q=0.7 ,", LangEN},
+	}
+	for _, tt := range tests {
+		if got := Lang(tt.header); got != tt.want {
+			t.Errorf("Lang(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		code, lang, want string
+	}{
+		{CodePoemNotFound, LangZH, "未找到诗"},
+		{CodePoemNotFound, LangEN, "Poem not found"},
+		{CodePoemNotFound, "fr", "未找到诗"},
+		{"unregistered_code", LangEN, "unregistered_code"},
+	}
+	for _, tt := range tests {
+		if got := Message(tt.code, tt.lang); got != tt.want {
+			t.Errorf("Message(%q, %q) = %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+	for code, m := range messages {
+		if m[LangZH] == "" || m[LangEN] == "" {
+			t.Errorf("%s: missing translation %v", code, m)
+		}
+	}
+}
+
+// Write 按 Accept-Language 选择文案，内部原因不出现在响应中
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err      error
+		lang     string
+		status   int
+		code     string
+		message  string
+		internal string
+	}{
+		{NotFound(CodeAuthorNotFound, nil), "en", http.StatusNotFound, CodeAuthorNotFound, "Author not found", ""},
+		{NotFound(CodeAuthorNotFound, nil), "zh-TW", http.StatusNotFound, CodeAuthorNotFound, "未找到作者", ""},
+		{Storage(errors.New("no such table: Poems")), "en", http.StatusInternalServerError, CodeStorage, "Storage error, please try again later", "no such table"},
+		{errors.New("boom"), "", http.StatusInternalServerError, CodeInternal, "服务器内部错误", "boom"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Accept-Language", tt.lang)
+		c.Request.Header.Set("X-Request-ID", "req-1")
+		Write(c, tt.err)
+
+		var got Envelope
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tt.status || got.Code != tt.code || got.Message != tt.message || got.RequestID != "req-1" {
+			t.Errorf("Write(%v) with %q: %d %+v", tt.err, tt.lang, w.Code, got)
+		}
+		if tt.internal != "" && strings.Contains(w.Body.String(), tt.internal) {
+			t.Errorf("Write(%v): response leaks the internal error: %s", tt.err, w.Body)
+		}
+	}
+}
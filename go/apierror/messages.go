@@ -0,0 +1,129 @@
+package apierror
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 错误码。新增错误码时需要同时在 messages 中补充中英文文案。
+const (
+	CodeInternal           = "internal_error"
+	CodeStorage            = "storage_error"
+	CodeRouteNotFound      = "route_not_found"
+	CodeInvalidJSON        = "invalid_json"
+	CodeInvalidID          = "invalid_id"
+	CodeMissingParam       = "missing_param"
+	CodeInvalidParam       = "invalid_param"
+	CodeAuthorNotFound     = "author_not_found"
+	CodePoemNotFound       = "poem_not_found"
+	CodeAuthorExists       = "author_exists"
+	CodeConflict           = "conflict"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnknownAuthor      = "unknown_author"
+	CodeAuthorHasPoems     = "author_has_poems"
+	CodeNotInTrash         = "not_in_trash"
+	CodeAuthorDeleted      = "author_deleted"
+	CodeAuthorInTrash      = "author_in_trash"
+	CodeRevisionNotFound   = "revision_not_found"
+	CodeUnauthenticated    = "unauthenticated"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeForbidden          = "forbidden"
+	CodeNoChanges          = "no_changes"
+	CodeSubmissionNotFound = "submission_not_found"
+	CodeSubmissionReviewed = "submission_reviewed"
+	CodeRateLimited        = "rate_limited"
+	CodeBodyTooLarge       = "body_too_large"
+	CodeQueryTooLong       = "query_too_long"
+	CodeQueryTooComplex    = "query_too_complex"
+	CodeImportRejected     = "import_rejected"
+)
+
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+// DefaultLang 是未指定或无法识别 Accept-Language 时使用的语言。
+const DefaultLang = LangZH
+
+var messages = map[string]map[string]string{
+	CodeInternal:           {LangZH: "服务器内部错误", LangEN: "Internal server error"},
+	CodeStorage:            {LangZH: "数据存储错误，请稍后重试", LangEN: "Storage error, please try again later"},
+	CodeRouteNotFound:      {LangZH: "接口不存在", LangEN: "Route not found"},
+	CodeInvalidJSON:        {LangZH: "请求体不是合法的 JSON", LangEN: "Request body is not valid JSON"},
+	CodeInvalidID:          {LangZH: "ID 格式错误", LangEN: "Invalid ID"},
+	CodeMissingParam:       {LangZH: "缺少必填参数", LangEN: "Missing required parameter"},
+	CodeInvalidParam:       {LangZH: "参数错误", LangEN: "Invalid parameter"},
+	CodeAuthorNotFound:     {LangZH: "未找到作者", LangEN: "Author not found"},
+	CodePoemNotFound:       {LangZH: "未找到诗", LangEN: "Poem not found"},
+	CodeAuthorExists:       {LangZH: "作者已存在", LangEN: "Author already exists"},
+	CodeConflict:           {LangZH: "数据冲突", LangEN: "Conflict with existing data"},
+	CodeValidationFailed:   {LangZH: "字段校验未通过", LangEN: "Validation failed"},
+	CodeUnknownAuthor:      {LangZH: "author_id 对应的作者不存在", LangEN: "author_id does not refer to an existing author"},
+	CodeNotInTrash:         {LangZH: "回收站中没有该条目", LangEN: "Item is not in the trash"},
+	CodeUnauthenticated:    {LangZH: "需要登录：请提供 API Key 或 Bearer 令牌", LangEN: "Authentication required: provide an API key or bearer token"},
+	CodeInvalidCredentials: {LangZH: "API Key 或令牌无效", LangEN: "Invalid API key or token"},
+	CodeForbidden:          {LangZH: "没有执行该操作的权限", LangEN: "Insufficient role for this operation"},
+	CodeRevisionNotFound:   {LangZH: "未找到修订记录", LangEN: "Revision not found"},
+	CodeAuthorDeleted:      {LangZH: "作者已被删除，请先恢复作者", LangEN: "Author is deleted; restore the author first"},
+	CodeAuthorInTrash:      {LangZH: "同名作者在回收站中，请恢复或清除该作者", LangEN: "An author with this name is in the trash; restore or purge it"},
+	CodeAuthorHasPoems:     {LangZH: "作者仍有诗作，请指定 poems=cascade 或 poems=reassign", LangEN: "Author still has poems; use poems=cascade or poems=reassign"},
+	CodeNoChanges:          {LangZH: "提交的内容与现有记录相同", LangEN: "Submission does not change the current record"},
+	CodeSubmissionNotFound: {LangZH: "未找到投稿", LangEN: "Submission not found"},
+	CodeRateLimited:        {LangZH: "请求过于频繁，请稍后重试", LangEN: "Too many requests, please retry later"},
+	CodeBodyTooLarge:       {LangZH: "请求体过大", LangEN: "Request body too large"},
+	CodeQueryTooLong:       {LangZH: "查询参数过长", LangEN: "Query parameter too long"},
+	CodeSubmissionReviewed: {LangZH: "投稿已审核，不能重复处理", LangEN: "Submission has already been reviewed"},
+	CodeQueryTooComplex:    {LangZH: "查询过于复杂，请减少嵌套或每页条数", LangEN: "Query is too complex, reduce nesting or page sizes"},
+	CodeImportRejected:     {LangZH: "导入数据有错误或冲突，未做任何修改", LangEN: "Import has invalid or conflicting records; nothing was changed"},
+}
+
+// Message 返回错误码在指定语言下的文案，未登记的错误码原样返回。
+func Message(code, lang string) string {
+	m, ok := messages[code]
+	if !ok {
+		return code
+	}
+	if s, ok := m[lang]; ok {
+		return s
+	}
+	return m[DefaultLang]
+}
+
+// Lang 从 Accept-Language 请求头中按权重选出支持的语言（zh 或 en）。
+func Lang(header string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		tags = append(tags, tag{name, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if t.q <= 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(t.lang, "-")
+		if base == LangZH || base == LangEN {
+			return base
+		}
+	}
+	return DefaultLang
+}
@@ -28,6 +28,10 @@ if cursor.fetchone() is None:
         title TEXT,
         author_id INTEGER,
         content TEXT,
+        source_id TEXT,
+        source_file TEXT,
+        source_index INTEGER,
+        source_notes TEXT,
         FOREIGN KEY (author_id) REFERENCES Authors (author_id)
     )
     ''')
@@ -66,20 +70,39 @@ cursor.execute('SELECT name, author_id FROM Authors')
 for row in cursor.fetchall():
     author_id_map[row[0]] = row[1]
 
-# 导入诗数据
-poetry_files = [f for f in os.listdir('全唐诗') if f.startswith('poet.tang.') and f.endswith('.json')]
+# 旧数据库的诗表没有出处列时补上（与 Go 服务的迁移相同）
+poem_columns = [row[1] for row in cursor.execute("PRAGMA table_info(Poems)")]
+for column, definition in [('source_id', 'TEXT'), ('source_file', 'TEXT'), ('source_index', 'INTEGER'), ('source_notes', 'TEXT')]:
+    if column not in poem_columns:
+        cursor.execute(f'ALTER TABLE Poems ADD COLUMN {column} {definition}')
+cursor.execute('CREATE UNIQUE INDEX IF NOT EXISTS idx_poems_source_id ON Poems (source_id)')
+
+# 导入诗数据，按文件编号顺序，最后是补录的诗
+# 每首诗记录原始数据中的 id、文件名、在文件中的序号和补录的说明（notes）
+poetry_files = sorted(
+    (f for f in os.listdir('全唐诗') if f.startswith('poet.tang.') and f.endswith('.json')),
+    key=lambda f: int(f[len('poet.tang.'):-len('.json')]),
+)
+if os.path.exists(os.path.join('全唐诗', '唐诗补录.json')):
+    poetry_files.append('唐诗补录.json')
 for file_name in poetry_files:
     file_path = os.path.join('全唐诗', file_name)
     with open(file_path, 'r', encoding='utf-8') as f:
         poems = json.load(f)
-        for poem in poems:
+        for index, poem in enumerate(poems):
             author_name = poem['author']
             author_id = author_id_map.get(author_name)
             if author_id:
                 content = '\n'.join(poem['paragraphs'])
+                source_id = (poem.get('id') or '').lower() or None
+                # notes 在 poet.tang.*.json 中为列表，在 唐诗补录.json 中为字符串
+                notes = poem.get('notes') or None
+                if isinstance(notes, list):
+                    notes = '\n'.join(notes)
                 cursor.execute('''
-                INSERT INTO Poems (title, author_id, content) VALUES (?, ?, ?)
-                ''', (poem['title'], author_id, content))
+                INSERT INTO Poems (title, author_id, content, source_id, source_file, source_index, source_notes)
+                VALUES (?, ?, ?, ?, ?, ?, ?)
+                ''', (poem['title'], author_id, content, source_id, file_name, index, notes))
 
 # 提交诗数据
 conn.commit()
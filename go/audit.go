@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 审计记录的操作类型
+const (
+	auditCreate  = "create"
+	auditUpdate  = "update"
+	auditDelete  = "delete"
+	auditRestore = "restore"
+	auditPurge   = "purge"
+)
+
+// requestAudit 是发起写操作的来源，随每条审计记录保存。
+type requestAudit struct {
+	Actor     string
+	Route     string // 如 "PATCH /authors/:id"，命令行操作为命令名
+	ClientIP  string
+	RequestID string
+}
+
+// auditOf 返回当前请求的审计来源。
+func auditOf(c *gin.Context) requestAudit {
+	return requestAudit{
+		Actor:     actor(c),
+		Route:     c.Request.Method + " " + c.FullPath(),
+		ClientIP:  c.ClientIP(),
+		RequestID: apierror.RequestID(c),
+	}
+}
+
+// cliAudit 返回命令行操作的审计来源。
+func cliAudit(command string) requestAudit {
+	return requestAudit{Actor: "cli", Route: command}
+}
+
+// record 在 tx 中追加一条审计记录，before/after 为 nil 时记为 NULL（如新建前、删除后）。
+// 审计记录与数据修改在同一事务中提交，不会出现有修改无记录的情况。
+func (a requestAudit) record(tx *tracedTx, action, entity string, id int64, before, after any) error {
+	b, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	af, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.exec("insert_audit", `INSERT INTO audit_log (created_at, actor, route, action, entity, entity_id, before, after, client_ip, request_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		now(), a.Actor, a.Route, action, entity, id, b, af, a.ClientIP, a.RequestID)
+	if err != nil {
+		return fmt.Errorf("insert audit record: %w", err)
+	}
+	return nil
+}
+
+func auditJSON(v any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// AuditRecord 是一条审计记录。
+type AuditRecord struct {
+	AuditID   int             `json:"audit_id"`
+	CreatedAt string          `json:"created_at"`
+	Actor     string          `json:"actor"`
+	Route     string          `json:"route"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIP  string          `json:"client_ip"`
+	RequestID string          `json:"request_id"`
+}
+
+// auditFilter 把 ?entity=&actor=&since= 转换为 WHERE 条件。
+func auditFilter(c *gin.Context) (string, []any, error) {
+	var conds []string
+	var args []any
+	if entity := c.Query("entity"); entity != "" {
+		conds = append(conds, "entity = ?")
+		args = append(args, entity)
+	}
+	if actor := c.Query("actor"); actor != "" {
+		conds = append(conds, "actor = ?")
+		args = append(args, actor)
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return "", nil, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "since", "format": "RFC3339"})
+		}
+		conds = append(conds, "created_at >= ?")
+		args = append(args, t.UTC().Format(time.RFC3339))
+	}
+	if len(conds) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+const auditColumns = `SELECT audit_id, created_at, actor, route, action, entity, entity_id,
+	before, after, COALESCE(client_ip, ''), COALESCE(request_id, '') FROM audit_log`
+
+func scanAudit(row rowScanner) (AuditRecord, error) {
+	var r AuditRecord
+	var before, after sql.NullString
+	if err := row.Scan(&r.AuditID, &r.CreatedAt, &r.Actor, &r.Route, &r.Action, &r.Entity, &r.EntityID,
+		&before, &after, &r.ClientIP, &r.RequestID); err != nil {
+		return r, fmt.Errorf("scan audit record: %w", err)
+	}
+	r.Before, r.After = rawJSON(before), rawJSON(after)
+	return r, nil
+}
+
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s.String)
+}
+
+// 查询审计记录（分页，最新的在前），可按 entity、actor、since 过滤
+func getAudit(c *gin.Context) {
+	where, args, err := auditFilter(c)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	const pageSize = 50
+	offset := (page - 1) * pageSize
+
+	var total int
+	if err := queryRow(c.Request.Context(), "count_audit", "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&total); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("count audit log: %w", err)))
+		return
+	}
+
+	rows, err := query(c.Request.Context(), "list_audit", auditColumns+where+" ORDER BY audit_id DESC LIMIT ? OFFSET ?", append(args, pageSize, offset)...)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("query audit log: %w", err)))
+		return
+	}
+	defer rows.Close()
+
+	records := []AuditRecord{}
+	for rows.Next() {
+		r, err := scanAudit(rows)
+		if err != nil {
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Write(c, apierror.Storage(err))
+		return
+	}
+
+	setResultCount(c, len(records))
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"data":      records,
+	})
+}
+
+// 导出审计记录为 NDJSON（每行一条，按时间先后），过滤条件同 getAudit
+func exportAudit(c *gin.Context) {
+	where, args, err := auditFilter(c)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	if where == "" {
+		where = " WHERE TRUE"
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	c.Status(http.StatusOK)
+	extendWriteDeadline(c)
+
+	// 分批读取、逐行写出，不在内存中保留全部记录；响应已开始后出错时记录日志并中断连接
+	w := bufio.NewWriter(c.Writer)
+	enc := json.NewEncoder(w)
+	count := 0
+	scan := func(row rowScanner) (AuditRecord, int, error) {
+		r, err := scanAudit(row)
+		return r, r.AuditID, err
+	}
+	err = eachBatch(c.Request.Context(), "export_audit", "audit log", auditColumns+where, "audit_id", args, scan, func(r AuditRecord) error {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	setResultCount(c, count)
+	if err != nil {
+		abortResponse(c, err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"poetry/apierror"
+)
+
+// 角色按权限从低到高排列，高权限角色拥有低权限角色的全部权限
+const (
+	roleReader = "reader"
+	roleEditor = "editor"
+	roleAdmin  = "admin"
+)
+
+var roleLevels = map[string]int{roleReader: 1, roleEditor: 2, roleAdmin: 3}
+
+// Identity 是通过认证的调用方。
+type Identity struct {
+	UserID int
+	Name   string
+	Role   string
+	Via    string // "api_key" 或 "jwt"
+	KeyID  int    // 使用的 API Key，或签发 JWT 时使用的 API Key；命令行签发的 JWT 为 0
+}
+
+const identityKey = "identity"
+
+// apiKeyPrefix 标识本服务签发的 API Key，便于在日志和代码仓库中识别泄露的密钥
+const apiKeyPrefix = "pk_"
+
+// tokenTTL 是 POST /auth/token 签发的 JWT 有效期
+const tokenTTL = 24 * time.Hour
+
+// keyTouchInterval 内重复使用同一个 API Key 时不再更新 last_used_at，避免每个请求都写数据库
+const keyTouchInterval = time.Minute
+
+// jwtSecret 是签发和校验 JWT（HS256）的密钥，启动时由 loadJWTSecret 设置
+var jwtSecret []byte
+
+type tokenClaims struct {
+	Role  string `json:"role"`
+	KeyID int    `json:"key_id,omitempty"` // 换取令牌的 API Key，吊销后令牌随之失效
+	jwt.RegisteredClaims
+}
+
+// loadJWTSecret 读取 JWT 密钥：优先使用环境变量 POETRY_JWT_SECRET，
+// 否则使用数据库旁的 jwt_secret 文件，文件不存在时生成一个。
+func loadJWTSecret() error {
+	if s := os.Getenv("POETRY_JWT_SECRET"); s != "" {
+		jwtSecret = []byte(s)
+		return nil
+	}
+	const path = "./jwt_secret"
+	if b, err := os.ReadFile(path); err == nil {
+		jwtSecret = []byte(strings.TrimSpace(string(b)))
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	secret := randomHex(32)
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	jwtSecret = []byte(secret)
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate 解析请求中的凭据（X-API-Key 或 Authorization: Bearer <jwt>），
+// 成功时把 Identity 写入上下文。未携带凭据的请求按匿名处理，由 requireRole 决定是否放行。
+func authenticate(c *gin.Context) {
+	var (
+		id  *Identity
+		err error
+	)
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		id, err = identityFromAPIKey(c.Request.Context(), key)
+	} else if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		id, err = identityFromJWT(c.Request.Context(), token)
+	}
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer realm="poetry"`)
+		apierror.Write(c, err)
+		return
+	}
+	if id != nil {
+		c.Set(identityKey, id)
+	}
+	c.Next()
+}
+
+// hasCredentials 报告请求是否携带了凭据，供认证之前的限流使用。
+func hasCredentials(c *gin.Context) bool {
+	return c.GetHeader("X-API-Key") != "" || strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+func identityFromAPIKey(ctx context.Context, key string) (*Identity, error) {
+	var id Identity
+	var lastUsed string
+	err := queryRow(ctx, "api_key_lookup", `SELECT k.key_id, COALESCE(k.last_used_at, ''), u.user_id, u.name, u.role
+		FROM api_keys k JOIN users u ON u.user_id = k.user_id
+		WHERE k.key_hash = ? AND k.revoked_at IS NULL`, hashAPIKey(key)).Scan(&id.KeyID, &lastUsed, &id.UserID, &id.Name, &id.Role)
+	if err == sql.ErrNoRows {
+		return nil, apierror.Unauthorized(apierror.CodeInvalidCredentials, nil)
+	}
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query api key: %w", err))
+	}
+	// 时间都是 UTC 的 RFC 3339 格式，可以直接按字符串比较
+	if lastUsed < time.Now().Add(-keyTouchInterval).UTC().Format(time.RFC3339) {
+		if _, err := exec(ctx, "touch_api_key", "UPDATE api_keys SET last_used_at = ? WHERE key_id = ?", now(), id.KeyID); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("touch api key %d: %w", id.KeyID, err))
+		}
+	}
+	id.Via = "api_key"
+	return &id, nil
+}
+
+func identityFromJWT(ctx context.Context, token string) (*Identity, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, apierror.Unauthorized(apierror.CodeInvalidCredentials, nil).WithCause(err)
+	}
+
+	// 以数据库中的用户为准，角色变更或用户删除后旧令牌随之失效；用 API Key 换取的令牌在 Key 吊销后也失效
+	id := Identity{KeyID: claims.KeyID}
+	if claims.KeyID != 0 {
+		err = queryRow(ctx, "token_key_lookup", `SELECT u.user_id, u.name, u.role
+			FROM users u JOIN api_keys k ON k.user_id = u.user_id
+			WHERE u.name = ? AND k.key_id = ? AND k.revoked_at IS NULL`, claims.Subject, claims.KeyID).Scan(&id.UserID, &id.Name, &id.Role)
+	} else {
+		err = queryRow(ctx, "user_lookup", "SELECT user_id, name, role FROM users WHERE name = ?", claims.Subject).Scan(&id.UserID, &id.Name, &id.Role)
+	}
+	if err == sql.ErrNoRows {
+		return nil, apierror.Unauthorized(apierror.CodeInvalidCredentials, nil)
+	}
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query user %q: %w", claims.Subject, err))
+	}
+	id.Via = "jwt"
+	return &id, nil
+}
+
+// issueToken 签发 JWT，keyID 为换取令牌的 API Key，命令行签发时为 0。
+func issueToken(name, role string, keyID int, ttl time.Duration) (string, time.Time, error) {
+	expires := time.Now().Add(ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		Role:  role,
+		KeyID: keyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   name,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	})
+	signed, err := token.SignedString(jwtSecret)
+	return signed, expires, err
+}
+
+// identity 返回当前请求的调用方，匿名请求返回 nil。
+func identity(c *gin.Context) *Identity {
+	if v, ok := c.Get(identityKey); ok {
+		return v.(*Identity)
+	}
+	return nil
+}
+
+// requireRole 要求调用方已认证且角色不低于 role。
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := identity(c)
+		if id == nil {
+			c.Header("WWW-Authenticate", `Bearer realm="poetry"`)
+			apierror.Write(c, apierror.Unauthorized(apierror.CodeUnauthenticated, nil))
+			return
+		}
+		if roleLevels[id.Role] < roleLevels[role] {
+			apierror.Write(c, apierror.Forbidden(apierror.CodeForbidden, gin.H{"required_role": role, "role": id.Role}))
+			return
+		}
+		c.Next()
+	}
+}
+
+// 用 API Key 换取短期 JWT，供浏览器端使用
+func createToken(c *gin.Context) {
+	id := identity(c)
+	if id.Via != "api_key" {
+		apierror.Write(c, apierror.Unauthorized(apierror.CodeInvalidCredentials, gin.H{"hint": "use X-API-Key"}))
+		return
+	}
+	token, expires, err := issueToken(id.Name, id.Role, id.KeyID, tokenTTL)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expires.UTC().Format(time.RFC3339),
+		"role":       id.Role,
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// getWith 以 header 中的凭据请求 path，返回状态码
+func getWith(t *testing.T, srv *httptest.Server, path, header, value string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(header, value)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func lastUsed(t *testing.T) string {
+	t.Helper()
+	var at string
+	if err := db.QueryRow("SELECT COALESCE(last_used_at, '') FROM api_keys WHERE key_id = 1").Scan(&at); err != nil {
+		t.Fatal(err)
+	}
+	return at
+}
+
+// TestAPIKeyTouchThrottled 检查 last_used_at 每个 keyTouchInterval 最多更新一次
+func TestAPIKeyTouchThrottled(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+
+	if code := getWith(t, srv, "/api/authors/1", "X-API-Key", testAPIKey); code != http.StatusOK {
+		t.Fatalf("first request: status %d", code)
+	}
+	if lastUsed(t) == "" {
+		t.Fatal("last_used_at not set by the first request")
+	}
+
+	recent := time.Now().Add(-keyTouchInterval / 2).UTC().Format(time.RFC3339)
+	stale := time.Now().Add(-2 * keyTouchInterval).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		stored  string
+		touched bool
+	}{
+		{"recent", recent, false},
+		{"stale", stale, true},
+	}
+	for _, tt := range tests {
+		if _, err := db.Exec("UPDATE api_keys SET last_used_at = ? WHERE key_id = 1", tt.stored); err != nil {
+			t.Fatal(err)
+		}
+		if code := getWith(t, srv, "/api/authors/1", "X-API-Key", testAPIKey); code != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.name, code)
+		}
+		if got := lastUsed(t); (got != tt.stored) != tt.touched {
+			t.Errorf("%s: last_used_at %s, stored %s, want touched %v", tt.name, got, tt.stored, tt.touched)
+		}
+	}
+}
+
+// TestTokenRevokedWithKey 检查用 API Key 换取的 JWT 在 Key 吊销后失效，命令行签发的 JWT 不受影响
+func TestTokenRevokedWithKey(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	doJSON(t, srv, http.MethodPost, "/api/auth/token", nil, http.StatusOK, &resp)
+	cli, _, err := issueToken("editor", roleEditor, 0, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := getWith(t, srv, "/api/authors/1", "Authorization", "Bearer "+resp.Token); code != http.StatusOK {
+		t.Fatalf("token before revoke: status %d", code)
+	}
+
+	if err := revokeKey([]string{"-id", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name, header, value string
+		status              int
+	}{
+		{"api key", "X-API-Key", testAPIKey, http.StatusUnauthorized},
+		{"exchanged token", "Authorization", "Bearer " + resp.Token, http.StatusUnauthorized},
+		{"cli token", "Authorization", "Bearer " + cli, http.StatusOK},
+	} {
+		if code := getWith(t, srv, "/api/authors/1", tt.header, tt.value); code != tt.status {
+			t.Errorf("%s after revoke: status %d, want %d", tt.name, code, tt.status)
+		}
+	}
+}
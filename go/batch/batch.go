@@ -0,0 +1,93 @@
+// Package batch 合并并发的按键读取，把多次单条查询变为一次批量查询（dataloader）。
+//
+// 同一时间窗口内对 Load 的调用被收集起来，窗口结束或攒够 MaxBatch 个键时调用一次 Fetch。
+// 结果按键缓存在 Loader 中，同一个键只读取一次，因此 Loader 应按请求创建，不跨请求共享。
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fetch 批量读取 keys 对应的值。结果中没有的键视为不存在，Load 返回零值。
+type Fetch[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type result[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+// Loader 是并发安全的批量读取器。
+type Loader[K comparable, V any] struct {
+	Wait     time.Duration // 收集键的时间窗口
+	MaxBatch int           // 每批最多的键数，0 表示不限
+
+	fetch   Fetch[K, V]
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+	timer   *time.Timer
+}
+
+// New 返回用 fetch 批量读取、收集 wait 时间内的键的读取器。
+func New[K comparable, V any](fetch Fetch[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{Wait: wait, MaxBatch: maxBatch, fetch: fetch, results: map[K]*result[V]{}}
+}
+
+// Load 返回 key 的值，与同一窗口内的其他键合并为一次读取。
+// ctx 用于批量读取本身，取消后 Load 立即返回，但已开始的读取不会中断。
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.pending = append(l.pending, key)
+		switch {
+		case l.MaxBatch > 0 && len(l.pending) >= l.MaxBatch:
+			l.dispatch(ctx)
+		case l.timer == nil:
+			l.timer = time.AfterFunc(l.Wait, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				l.dispatch(ctx)
+			})
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch 在新的 goroutine 中读取当前收集的键，调用方需持有锁
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	keys := l.pending
+	if len(keys) == 0 {
+		return
+	}
+	l.pending = nil
+	batch := make(map[K]*result[V], len(keys))
+	for _, k := range keys {
+		batch[k] = l.results[k]
+	}
+
+	go func() {
+		values, err := l.fetch(ctx, keys)
+		for k, r := range batch {
+			r.value, r.err = values[k], err
+			close(r.done)
+		}
+	}()
+}
@@ -0,0 +1,119 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 记录每次批量读取的键，值为键的两倍，键为负数时读取出错
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+var errNegative = errors.New("negative key")
+
+func (r *recorder) fetch(_ context.Context, keys []int) (map[int]int, error) {
+	r.mu.Lock()
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	r.batches = append(r.batches, sorted)
+	r.mu.Unlock()
+
+	values := map[int]int{}
+	for _, k := range keys {
+		if k < 0 {
+			return nil, errNegative
+		}
+		if k != 0 {
+			values[k] = 2 * k
+		}
+	}
+	return values, nil
+}
+
+// loadAll 并发读取 keys，返回每个键的值和错误
+func loadAll(ctx context.Context, l *Loader[int, int], keys []int) ([]int, []error) {
+	values := make([]int, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(ctx, k)
+		}()
+	}
+	wg.Wait()
+	return values, errs
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxBatch int
+		keys     []int
+		values   []int
+		errs     []error
+		batches  []int // 每批的键数，按从小到大排列
+	}{
+		{"one batch", 0, []int{1, 2, 3}, []int{2, 4, 6}, []error{nil, nil, nil}, []int{3}},
+		{"duplicate keys", 0, []int{1, 1, 2}, []int{2, 2, 4}, []error{nil, nil, nil}, []int{2}},
+		{"missing key", 0, []int{0, 1}, []int{0, 2}, []error{nil, nil}, []int{2}},
+		{"max batch", 2, []int{1, 2, 3, 4, 5}, []int{2, 4, 6, 8, 10}, []error{nil, nil, nil, nil, nil}, []int{1, 2, 2}},
+		{"error fails the batch", 0, []int{-1, 1}, []int{0, 0}, []error{errNegative, errNegative}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r recorder
+			l := New(r.fetch, 20*time.Millisecond, tt.maxBatch)
+			values, errs := loadAll(context.Background(), l, tt.keys)
+			if !slices.Equal(values, tt.values) {
+				t.Errorf("values %v, want %v", values, tt.values)
+			}
+			for i, err := range errs {
+				if !errors.Is(err, tt.errs[i]) {
+					t.Errorf("key %d: error %v, want %v", tt.keys[i], err, tt.errs[i])
+				}
+			}
+			var sizes []int
+			for _, b := range r.batches {
+				sizes = append(sizes, len(b))
+			}
+			slices.Sort(sizes)
+			if !slices.Equal(sizes, tt.batches) {
+				t.Errorf("batches %v, want sizes %v", r.batches, tt.batches)
+			}
+		})
+	}
+}
+
+// 已读取的键不再读取，新的键在下一个窗口中读取
+func TestLoadCached(t *testing.T) {
+	var r recorder
+	l := New(r.fetch, 10*time.Millisecond, 0)
+	ctx := context.Background()
+	loadAll(ctx, l, []int{1, 2})
+	values, _ := loadAll(ctx, l, []int{2, 3})
+	if !slices.Equal(values, []int{4, 6}) {
+		t.Errorf("values %v, want [4 6]", values)
+	}
+	if len(r.batches) != 2 || !slices.Equal(r.batches[1], []int{3}) {
+		t.Errorf("batches %v, want [[1 2] [3]]", r.batches)
+	}
+}
+
+// ctx 取消后 Load 立即返回
+func TestLoadCanceled(t *testing.T) {
+	var r recorder
+	l := New(r.fetch, time.Hour, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Load(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Load after cancel: %v, want context.Canceled", err)
+	}
+}
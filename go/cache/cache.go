@@ -0,0 +1,151 @@
+// Package cache 实现带过期时间的 LRU 缓存。
+//
+// 缓存最多保存 Size 个条目，超出时淘汰最久未使用的条目；条目写入 TTL 后过期，
+// 过期条目在下次读取时删除。nil 的 *Cache 表示关闭缓存：读取总是未命中，写入被忽略。
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// Cache 是并发安全的 LRU 缓存。
+type Cache[K comparable, V any] struct {
+	Size int
+	TTL  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // 最近使用的在前
+	items   map[K]*list.Element
+	version uint64
+	now     func() time.Time
+}
+
+// New 返回最多保存 size 个条目、条目 ttl 后过期的缓存。size 不大于 0 时返回 nil，即不缓存。
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	return &Cache[K, V]{Size: size, TTL: ttl, order: list.New(), items: map[K]*list.Element{}, now: time.Now}
+}
+
+// Get 返回 key 对应的未过期的值。
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Version 返回当前的失效版本，每次 Delete 或 Clear 后加一。
+// 读取数据前取得版本，写入时交给 SetIfUnchanged，可以避免把失效前读到的旧数据写回缓存。
+func (c *Cache[K, V]) Version() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Set 写入 key 的值，必要时淘汰最久未使用的条目。
+func (c *Cache[K, V]) Set(key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value)
+}
+
+// SetIfUnchanged 在 version 之后没有发生过失效时写入，返回是否写入。
+func (c *Cache[K, V]) SetIfUnchanged(key K, value V, version uint64) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return false
+	}
+	c.set(key, value)
+	return true
+}
+
+func (c *Cache[K, V]) set(key K, value V) {
+	expires := c.now().Add(c.TTL)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.Size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete 删除指定的条目。
+func (c *Cache[K, V]) Delete(keys ...K) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// Clear 删除全部条目。
+func (c *Cache[K, V]) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.order.Init()
+	clear(c.items)
+}
+
+// Len 返回当前的条目数，包括尚未删除的过期条目。
+func (c *Cache[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove 删除一个条目，调用方需持有锁。
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// newTest 返回容量为 size、TTL 为一分钟的缓存，以及让时钟前进的函数
+func newTest(size int) (*Cache[string, int], func(time.Duration)) {
+	c := New[string, int](size, time.Minute)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestEviction(t *testing.T) {
+	// 每个操作：set 写入 key，get 读取 key，advance 让时钟前进
+	type op struct {
+		kind    string
+		key     string
+		advance time.Duration
+	}
+	tests := []struct {
+		name string
+		ops  []op
+		want map[string]bool // 最后能否读到各个 key
+	}{
+		{"least recently used evicted", []op{
+			{"set", "a", 0}, {"set", "b", 0}, {"set", "c", 0}, {"set", "d", 0},
+		}, map[string]bool{"a": false, "b": true, "c": true, "d": true}},
+		{"get refreshes recency", []op{
+			{"set", "a", 0}, {"set", "b", 0}, {"set", "c", 0}, {"get", "a", 0}, {"set", "d", 0},
+		}, map[string]bool{"a": true, "b": false, "c": true, "d": true}},
+		{"set existing refreshes recency", []op{
+			{"set", "a", 0}, {"set", "b", 0}, {"set", "c", 0}, {"set", "a", 0}, {"set", "d", 0},
+		}, map[string]bool{"a": true, "b": false, "c": true, "d": true}},
+		{"expired after ttl", []op{
+			{"set", "a", 0}, {"advance", "", 30 * time.Second}, {"set", "b", 0}, {"advance", "", 30 * time.Second},
+		}, map[string]bool{"a": false, "b": true}},
+		{"get does not extend ttl", []op{
+			{"set", "a", 0}, {"advance", "", 50 * time.Second}, {"get", "a", 0}, {"advance", "", 10 * time.Second},
+		}, map[string]bool{"a": false}},
+		{"set resets ttl", []op{
+			{"set", "a", 0}, {"advance", "", 50 * time.Second}, {"set", "a", 0}, {"advance", "", 50 * time.Second},
+		}, map[string]bool{"a": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, advance := newTest(3)
+			for i, o := range tt.ops {
+				switch o.kind {
+				case "set":
+					c.Set(o.key, i)
+				case "get":
+					c.Get(o.key)
+				case "advance":
+					advance(o.advance)
+				}
+			}
+			for key, want := range tt.want {
+				if _, ok := c.Get(key); ok != want {
+					t.Errorf("Get(%s): %v, want %v", key, ok, want)
+				}
+			}
+			if c.Len() > 3 {
+				t.Errorf("Len %d above size 3", c.Len())
+			}
+		})
+	}
+}
+
+func TestSetIfUnchanged(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *Cache[string, int])
+		stored     bool
+	}{
+		{"no invalidation", func(*Cache[string, int]) {}, true},
+		{"unrelated set", func(c *Cache[string, int]) { c.Set("b", 2) }, true},
+		{"delete", func(c *Cache[string, int]) { c.Delete("b") }, false},
+		{"clear", func(c *Cache[string, int]) { c.Clear() }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTest(3)
+			v := c.Version()
+			tt.invalidate(c)
+			if ok := c.SetIfUnchanged("a", 1, v); ok != tt.stored {
+				t.Errorf("SetIfUnchanged: %v, want %v", ok, tt.stored)
+			}
+			if _, ok := c.Get("a"); ok != tt.stored {
+				t.Errorf("Get after SetIfUnchanged: %v, want %v", ok, tt.stored)
+			}
+			// 取得新版本后可以写入
+			if !c.SetIfUnchanged("a", 1, c.Version()) {
+				t.Error("SetIfUnchanged with current version: false")
+			}
+		})
+	}
+}
+
+// nil 的缓存表示关闭缓存
+func TestNil(t *testing.T) {
+	c := New[string, int](0, time.Minute)
+	if c != nil {
+		t.Fatalf("New with size 0: %v, want nil", c)
+	}
+	c.Set("a", 1)
+	if _, ok := c.Get("a"); ok {
+		t.Error("nil cache: Get hit")
+	}
+	if c.SetIfUnchanged("a", 1, c.Version()) {
+		t.Error("nil cache: SetIfUnchanged stored")
+	}
+	c.Delete("a")
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("nil cache: Len %d", c.Len())
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// includeQuery 返回 ?include=，inc 为 nil 时使用服务端的默认值
+func includeQuery(inc *Include) url.Values {
+	if inc == nil {
+		return nil
+	}
+	return url.Values{"include": {inc.String()}}
+}
+
+// ListAuthors 返回第 page 页作者，每页 6 位。inc 指定附带的诗作预览和诗作总数，nil 时不附带。
+func (c *Client) ListAuthors(ctx context.Context, page int, inc *Include) (*Page[Author], error) {
+	var p Page[Author]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/authors", query: pageQuery(page, includeQuery(inc))}, &p)
+	return &p, err
+}
+
+// Authors 逐页返回全部作者。
+func (c *Client) Authors(ctx context.Context, inc *Include) iter.Seq2[Author, error] {
+	return All(func(page int) (*Page[Author], error) {
+		return c.ListAuthors(ctx, page, inc)
+	})
+}
+
+// AuthorsByNumber 返回按 ID 排列的前 n 位作者。
+func (c *Client) AuthorsByNumber(ctx context.Context, n int, inc *Include) (*AuthorsByNumber, error) {
+	var r AuthorsByNumber
+	err := c.do(ctx, request{method: http.MethodGet, path: "/authors/num/" + itoa(n), query: includeQuery(inc)}, &r)
+	return &r, err
+}
+
+// GetAuthor 返回一位作者。
+func (c *Client) GetAuthor(ctx context.Context, id int) (*Author, error) {
+	var a Author
+	err := c.do(ctx, request{method: http.MethodGet, path: "/authors/" + itoa(id)}, &a)
+	return &a, err
+}
+
+// CreateAuthor 创建作者，需要 editor 角色。
+func (c *Client) CreateAuthor(ctx context.Context, in AuthorInput) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPost, path: "/authors", body: in}, &r)
+	return &r, err
+}
+
+// UpdateAuthor 更新作者，comment 随修订记录保存，需要 editor 角色。
+func (c *Client) UpdateAuthor(ctx context.Context, id int, in AuthorInput, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPut, path: "/authors/" + itoa(id), query: commentQuery(comment), body: in}, &r)
+	return &r, err
+}
+
+// PatchAuthor 按 JSON Merge Patch 修改作者的部分字段，需要 editor 角色。
+func (c *Client) PatchAuthor(ctx context.Context, id int, patch Patch, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPatch, path: "/authors/" + itoa(id), query: commentQuery(comment),
+		body: patch, contentType: mergePatchType}, &r)
+	return &r, err
+}
+
+// DeleteAuthor 把作者移入回收站，需要 editor 角色。poems 为 PoemsRestrict、PoemsCascade 或 PoemsReassign，
+// 为空时按 PoemsRestrict 处理；PoemsReassign 时诗作转给作者 to。
+func (c *Client) DeleteAuthor(ctx context.Context, id int, poems string, to int) (*DeleteAuthorResult, error) {
+	q := url.Values{}
+	if poems != "" {
+		q.Set("poems", poems)
+	}
+	if poems == PoemsReassign {
+		q.Set("to", strconv.Itoa(to))
+	}
+	var r DeleteAuthorResult
+	err := c.do(ctx, request{method: http.MethodDelete, path: "/authors/" + itoa(id), query: q}, &r)
+	return &r, err
+}
+
+// ListAuthorPoems 返回作者的第 page 页诗作，每页 6 首。
+func (c *Client) ListAuthorPoems(ctx context.Context, id, page int) (*Page[Poem], error) {
+	var p Page[Poem]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/authors/" + itoa(id) + "/poems", query: pageQuery(page, nil)}, &p)
+	return &p, err
+}
+
+// AuthorPoems 逐页返回作者的全部诗作。
+func (c *Client) AuthorPoems(ctx context.Context, id int) iter.Seq2[Poem, error] {
+	return All(func(page int) (*Page[Poem], error) {
+		return c.ListAuthorPoems(ctx, id, page)
+	})
+}
@@ -0,0 +1,298 @@
+// Package client 是唐诗数据库接口的 Go 客户端。
+//
+// 每个接口对应一个带类型的方法，如 ListAuthors、GetPoem、SearchPoems、DataStats；
+// 常用的分页列表另有返回迭代器的方法（如 Authors），其他分页接口可以交给 All 逐页读取。所有方法都接受 context，
+// 取消或超时后立即返回。
+//
+// 遇到 429 时按 Retry-After 等待后重试；遇到 5xx 或网络错误时，幂等的请求
+// （GET、PUT、DELETE）按指数退避重试，POST 和 PATCH 不重试，避免重复写入。
+// 接口返回的错误为 *APIError，可用 errors.As 取出错误码。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 重试的默认值
+const (
+	defaultMaxRetries = 3
+	defaultRetryWait  = 200 * time.Millisecond // 第一次重试前的等待时间，之后每次翻倍
+	maxRetryWait      = 10 * time.Second
+)
+
+// Client 调用唐诗数据库接口，可以并发使用。
+type Client struct {
+	BaseURL    string       // 接口地址，含 /api 前缀，如 http://localhost:8080/api
+	HTTPClient *http.Client // 为 nil 时使用 http.DefaultClient
+	APIKey     string       // 通过 X-API-Key 认证
+	Token      string       // 通过 Authorization: Bearer 认证，与 APIKey 同时设置时优先
+	Language   string       // Accept-Language，决定错误信息的语言，如 "en"
+
+	MaxRetries int           // 最多重试次数，0 表示不重试
+	RetryWait  time.Duration // 第一次重试前的等待时间，之后每次翻倍
+}
+
+// New 返回访问 baseURL 的客户端，baseURL 含 /api 前缀。
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		MaxRetries: defaultMaxRetries,
+		RetryWait:  defaultRetryWait,
+	}
+}
+
+// APIError 是接口返回的错误，对应服务端的 {code, message, details, request_id, trace_id}。
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"` // 稳定的错误码，如 "author_not_found"
+	Message    string `json:"message"`
+	Details    any    `json:"details,omitempty"`
+	RequestID  string `json:"request_id"`
+	TraceID    string `json:"trace_id,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// IsNotFound 判断 err 是否为 404。
+func IsNotFound(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// Page 是分页接口的一页数据。
+type Page[T any] struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"` // 全部条数
+	Data     []T `json:"data"`
+}
+
+// HasNext 判断是否还有下一页。
+func (p *Page[T]) HasNext() bool {
+	return p.Page*p.PageSize < p.Total && len(p.Data) > 0
+}
+
+// All 从第一页起逐页调用 fetch，依次返回每一条数据，出错时返回错误并结束。例如：
+//
+//	for p, err := range client.All(func(page int) (*client.Page[client.Poem], error) {
+//		return c.SearchPoems(ctx, "明月", page)
+//	}) {
+//		...
+//	}
+func All[T any](fetch func(page int) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			p, err := fetch(page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, v := range p.Data {
+				if !yield(v, nil) {
+					return
+				}
+			}
+			if !p.HasNext() {
+				return
+			}
+		}
+	}
+}
+
+// request 是一次接口调用
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        any
+	contentType string // 默认 application/json
+}
+
+// do 发送请求并把 2xx 响应解析到 out，out 为 nil 时丢弃响应体。
+func (c *Client) do(ctx context.Context, r request, out any) error {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", r.method, r.path, err)
+	}
+	return nil
+}
+
+// send 发送请求并按需重试，返回 2xx 响应，调用方负责关闭响应体。
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, fmt.Errorf("encode %s %s request: %w", r.method, r.path, err)
+		}
+	}
+	target := c.BaseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, r.method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req, r, body != nil)
+
+		resp, err := c.httpClient().Do(req)
+		if err == nil && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		var wait time.Duration
+		if err != nil {
+			if ctx.Err() != nil || !idempotent(r.method) {
+				return nil, err
+			}
+		} else {
+			err = readError(resp)
+			if !c.retryable(r.method, resp.StatusCode) {
+				return nil, err
+			}
+			wait = retryAfter(resp)
+		}
+		if attempt >= c.MaxRetries {
+			return nil, err
+		}
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		if sleep(ctx, wait) != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) setHeaders(req *http.Request, r request, hasBody bool) {
+	req.Header.Set("Accept", "application/json")
+	if hasBody {
+		ctype := r.contentType
+		if ctype == "" {
+			ctype = "application/json"
+		}
+		req.Header.Set("Content-Type", ctype)
+	}
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.APIKey != "":
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.Language != "" {
+		req.Header.Set("Accept-Language", c.Language)
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// retryable 判断响应是否值得重试：429 的请求没有被处理，总是可以重试；5xx 只重试幂等的请求
+func (c *Client) retryable(method string, status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500 && idempotent(method)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff 返回第 attempt 次重试前的等待时间：RetryWait 按次数翻倍，加上最多一半的随机抖动
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.RetryWait << attempt
+	if d <= 0 || d > maxRetryWait {
+		d = maxRetryWait
+	}
+	return d + rand.N(d/2+1)
+}
+
+// retryAfter 解析 Retry-After 的秒数，没有时返回 0
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, maxRetryWait)
+}
+
+// readError 把非 2xx 响应转换为 *APIError 并关闭响应体
+func readError(resp *http.Response) error {
+	defer resp.Body.Close()
+	e := &APIError{StatusCode: resp.StatusCode}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(b, e) != nil || e.Code == "" {
+		e.Code = "http_" + strconv.Itoa(resp.StatusCode)
+		e.Message = strings.TrimSpace(string(b))
+		if e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return e
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// pageQuery 返回带 page 参数的查询条件，page 小于 1 时由服务端按第一页处理
+func pageQuery(page int, q url.Values) url.Values {
+	if q == nil {
+		q = url.Values{}
+	}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	return q
+}
+
+// commentQuery 返回 ?comment=，comment 为空时返回 nil
+func commentQuery(comment string) url.Values {
+	if comment == "" {
+		return nil
+	}
+	return url.Values{"comment": {comment}}
+}
+
+func itoa(id int) string {
+	return strconv.Itoa(id)
+}
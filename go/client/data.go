@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateToken 用 APIKey 换取 JWT，之后可以设置到 Token 上使用。
+func (c *Client) CreateToken(ctx context.Context) (*Token, error) {
+	var t Token
+	err := c.do(ctx, request{method: http.MethodPost, path: "/auth/token"}, &t)
+	return &t, err
+}
+
+// SearchAuthors 按名字模糊搜索作者，返回第 page 页。inc 为 nil 时附带诗作预览和诗作总数。
+func (c *Client) SearchAuthors(ctx context.Context, name string, page int, inc *Include) (*Page[Author], error) {
+	q := includeQuery(inc)
+	if q == nil {
+		q = url.Values{}
+	}
+	q.Set("name", name)
+	var p Page[Author]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/search/authors", query: pageQuery(page, q)}, &p)
+	return &p, err
+}
+
+// SearchPoems 按标题或内容模糊搜索诗作，返回第 page 页。
+func (c *Client) SearchPoems(ctx context.Context, text string, page int) (*Page[Poem], error) {
+	var p Page[Poem]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/search/poems", query: pageQuery(page, url.Values{"name": {text}})}, &p)
+	return &p, err
+}
+
+// DataStats 返回作者数、诗作数和字数。
+func (c *Client) DataStats(ctx context.Context) (*Stats, error) {
+	var r struct {
+		Data Stats `json:"data"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/data/stats"}, &r)
+	return &r.Data, err
+}
+
+// DataChart 返回各作者的诗作数和字数（/data/echart/two），按诗作数从多到少排列。
+func (c *Client) DataChart(ctx context.Context) (*Chart, error) {
+	var r struct {
+		Data Chart `json:"data"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/data/echart/two"}, &r)
+	return &r.Data, err
+}
+
+// DataTable 返回各作者的朝代、诗作数和字数，按诗作数从多到少排列。
+func (c *Client) DataTable(ctx context.Context) (*Table, error) {
+	var r struct {
+		Data Table `json:"data"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/data/table"}, &r)
+	return &r.Data, err
+}
+
+// QualityReport 返回数据质量报告。check 不为空时只执行该项检查，limit 为每项列出的记录数，0 时使用服务端默认值。
+func (c *Client) QualityReport(ctx context.Context, check string, limit int) (*QualityReport, error) {
+	q := url.Values{}
+	if check != "" {
+		q.Set("check", check)
+	}
+	if limit > 0 {
+		q.Set("limit", itoa(limit))
+	}
+	var r QualityReport
+	err := c.do(ctx, request{method: http.MethodGet, path: "/reports/quality", query: q}, &r)
+	return &r, err
+}
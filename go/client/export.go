@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (f ExportFilter) query(format string) url.Values {
+	q := url.Values{}
+	if format != "" {
+		q.Set("format", format)
+	}
+	if f.AuthorID > 0 {
+		q.Set("author_id", strconv.Itoa(f.AuthorID))
+	}
+	if f.Author != "" {
+		q.Set("author", f.Author)
+	}
+	if f.Dynasty != "" {
+		q.Set("dynasty", f.Dynasty)
+	}
+	if f.Form != "" {
+		q.Set("form", f.Form)
+	}
+	if f.Tag != "" {
+		q.Set("tag", f.Tag)
+	}
+	return q
+}
+
+// ExportPoems 按 format 导出符合条件的全部诗作，返回未解析的响应体，调用方负责关闭。
+// format 为 FormatNDJSON、FormatCSV、FormatJSON 或 FormatChinesePoetry，为空时按 FormatNDJSON 处理。
+func (c *Client) ExportPoems(ctx context.Context, format string, f ExportFilter) (io.ReadCloser, error) {
+	return c.export(ctx, "/export/poems", format, f)
+}
+
+// ExportAuthors 按 format 导出符合条件的全部作者，规则同 ExportPoems。
+func (c *Client) ExportAuthors(ctx context.Context, format string, f ExportFilter) (io.ReadCloser, error) {
+	return c.export(ctx, "/export/authors", format, f)
+}
+
+func (c *Client) export(ctx context.Context, path, format string, f ExportFilter) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path, query: f.query(format)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// ExportedPoems 按 poem_id 顺序流式读取符合条件的全部诗作。
+// 读取中途出错时不会重试，已返回的记录不会重复；服务端中途出错时连接被中断，返回 io.ErrUnexpectedEOF。
+func (c *Client) ExportedPoems(ctx context.Context, f ExportFilter) iter.Seq2[ExportPoem, error] {
+	return exportRecords[ExportPoem](ctx, c, "/export/poems", f)
+}
+
+// ExportedAuthors 按 author_id 顺序流式读取符合条件的全部作者，规则同 ExportedPoems。
+func (c *Client) ExportedAuthors(ctx context.Context, f ExportFilter) iter.Seq2[ExportAuthor, error] {
+	return exportRecords[ExportAuthor](ctx, c, "/export/authors", f)
+}
+
+// exportRecords 以 NDJSON 格式导出并逐条解析
+func exportRecords[T any](ctx context.Context, c *Client, path string, f ExportFilter) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		body, err := c.export(ctx, path, FormatNDJSON, f)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer body.Close()
+
+		// 不用 dec.More()：连接被中断时它同样返回 false，截断的导出会被当作完整的结果
+		dec := json.NewDecoder(body)
+		for {
+			var rec T
+			err := dec.Decode(&rec)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(zero, fmt.Errorf("decode %s: %w", path, err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 连接在两条记录之间被中断时，逐条读取同样报告 unexpected EOF
+func TestExportedPoemsTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, `{"poem_id":1,"title":"静夜思"}`+"\n"+`{"poem_id":2,"title":"春望"}`+"\n")
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer srv.Close()
+
+	var ids []int
+	var last error
+	for p, err := range New(srv.URL).ExportedPoems(context.Background(), ExportFilter{}) {
+		if err != nil {
+			last = err
+			break
+		}
+		ids = append(ids, p.PoemID)
+	}
+	if len(ids) != 2 || !errors.Is(last, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v then %v, want 2 poems and unexpected EOF", ids, last)
+	}
+}
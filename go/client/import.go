@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Import 批量导入诗作，需要 editor 角色。dryRun 为 true 时只校验并返回处理报告，不修改数据；
+// 为 false 时全部记录在同一事务中写入，有未通过校验或冲突的记录时不写入，返回错误码为 import_rejected 的
+// *APIError，同时返回被拒绝的报告。
+func (c *Client) Import(ctx context.Context, records []ImportRecord, dryRun bool, comment string) (*ImportReport, error) {
+	q := url.Values{"dry_run": {strconv.FormatBool(dryRun)}}
+	if comment != "" {
+		q.Set("comment", comment)
+	}
+	var r ImportReport
+	err := c.do(ctx, request{method: http.MethodPost, path: "/import", query: q, body: records}, &r)
+	var e *APIError
+	if errors.As(err, &e) && e.Code == "import_rejected" {
+		// 被拒绝的报告在错误的 details 中
+		if b, merr := json.Marshal(e.Details); merr == nil && json.Unmarshal(b, &r) == nil {
+			return &r, err
+		}
+	}
+	return &r, err
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// mergePatchType 是 PATCH 请求体的类型
+const mergePatchType = "application/merge-patch+json"
+
+// ListPoems 返回第 page 页诗作，每页 6 首。
+func (c *Client) ListPoems(ctx context.Context, page int) (*Page[Poem], error) {
+	var p Page[Poem]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/poems", query: pageQuery(page, nil)}, &p)
+	return &p, err
+}
+
+// Poems 逐页返回全部诗作。
+func (c *Client) Poems(ctx context.Context) iter.Seq2[Poem, error] {
+	return All(func(page int) (*Page[Poem], error) {
+		return c.ListPoems(ctx, page)
+	})
+}
+
+// GetPoem 返回一首诗作。
+func (c *Client) GetPoem(ctx context.Context, id int) (*Poem, error) {
+	var p Poem
+	err := c.do(ctx, request{method: http.MethodGet, path: "/poems/" + itoa(id)}, &p)
+	return &p, err
+}
+
+// GetPoemBySource 按原始数据中的 UUID 读取诗作。
+func (c *Client) GetPoemBySource(ctx context.Context, uuid string) (*Poem, error) {
+	var p Poem
+	err := c.do(ctx, request{method: http.MethodGet, path: "/poems/by-source/" + url.PathEscape(uuid)}, &p)
+	return &p, err
+}
+
+// CreatePoem 创建诗作，需要 editor 角色。
+func (c *Client) CreatePoem(ctx context.Context, in PoemInput) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPost, path: "/poems", body: in}, &r)
+	return &r, err
+}
+
+// UpdatePoem 更新诗作，comment 随修订记录保存，需要 editor 角色。
+func (c *Client) UpdatePoem(ctx context.Context, id int, in PoemInput, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPut, path: "/poems/" + itoa(id), query: commentQuery(comment), body: in}, &r)
+	return &r, err
+}
+
+// PatchPoem 按 JSON Merge Patch 修改诗作的部分字段，需要 editor 角色。
+func (c *Client) PatchPoem(ctx context.Context, id int, patch Patch, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPatch, path: "/poems/" + itoa(id), query: commentQuery(comment),
+		body: patch, contentType: mergePatchType}, &r)
+	return &r, err
+}
+
+// DeletePoem 把诗作移入回收站，需要 editor 角色。
+func (c *Client) DeletePoem(ctx context.Context, id int) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodDelete, path: "/poems/" + itoa(id)}, &r)
+	return &r, err
+}
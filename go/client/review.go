@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// ListAuthorRevisions 返回作者的第 page 页修订，最新的在前。
+func (c *Client) ListAuthorRevisions(ctx context.Context, id, page int) (*Page[Revision], error) {
+	return c.listRevisions(ctx, "/authors/"+itoa(id), page)
+}
+
+// ListPoemRevisions 返回诗作的第 page 页修订，最新的在前。
+func (c *Client) ListPoemRevisions(ctx context.Context, id, page int) (*Page[Revision], error) {
+	return c.listRevisions(ctx, "/poems/"+itoa(id), page)
+}
+
+func (c *Client) listRevisions(ctx context.Context, entity string, page int) (*Page[Revision], error) {
+	var p Page[Revision]
+	err := c.do(ctx, request{method: http.MethodGet, path: entity + "/revisions", query: pageQuery(page, nil)}, &p)
+	return &p, err
+}
+
+// RevertAuthor 把作者回退到修订 rev 之前的状态，需要 editor 角色。
+func (c *Client) RevertAuthor(ctx context.Context, id, rev int, comment string) (*Result, error) {
+	return c.revert(ctx, "/authors/"+itoa(id), rev, comment)
+}
+
+// RevertPoem 把诗作回退到修订 rev 之前的状态，需要 editor 角色。
+func (c *Client) RevertPoem(ctx context.Context, id, rev int, comment string) (*Result, error) {
+	return c.revert(ctx, "/poems/"+itoa(id), rev, comment)
+}
+
+func (c *Client) revert(ctx context.Context, entity string, rev int, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPost, path: entity + "/revisions/" + itoa(rev) + "/revert", query: commentQuery(comment)}, &r)
+	return &r, err
+}
+
+// ListTrash 返回回收站的第 page 页，typ 为 TrashAuthors 或 TrashPoems，为空时列出全部，需要 editor 角色。
+func (c *Client) ListTrash(ctx context.Context, typ string, page int) (*Page[TrashItem], error) {
+	q := url.Values{}
+	if typ != "" {
+		q.Set("type", typ)
+	}
+	var p Page[TrashItem]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/trash", query: pageQuery(page, q)}, &p)
+	return &p, err
+}
+
+// Restore 从回收站恢复作者或诗作，typ 为 TrashAuthors 或 TrashPoems，需要 editor 角色。
+func (c *Client) Restore(ctx context.Context, typ string, id int) (*RestoreResult, error) {
+	var r RestoreResult
+	err := c.do(ctx, request{method: http.MethodPost, path: "/trash/" + url.PathEscape(typ) + "/" + itoa(id) + "/restore"}, &r)
+	return &r, err
+}
+
+// ListSubmissions 返回第 page 页投稿。编辑可以看到全部投稿，其他用户只能看到自己的投稿。
+func (c *Client) ListSubmissions(ctx context.Context, f SubmissionFilter, page int) (*Page[Submission], error) {
+	q := url.Values{}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Kind != "" {
+		q.Set("kind", f.Kind)
+	}
+	if f.SubmittedBy != "" {
+		q.Set("submitted_by", f.SubmittedBy)
+	}
+	var p Page[Submission]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/submissions", query: pageQuery(page, q)}, &p)
+	return &p, err
+}
+
+// Submissions 逐页返回符合条件的投稿。
+func (c *Client) Submissions(ctx context.Context, f SubmissionFilter) iter.Seq2[Submission, error] {
+	return All(func(page int) (*Page[Submission], error) {
+		return c.ListSubmissions(ctx, f, page)
+	})
+}
+
+// GetSubmission 返回一条投稿及其与当前记录的差异。
+func (c *Client) GetSubmission(ctx context.Context, id int) (*Submission, error) {
+	var s Submission
+	err := c.do(ctx, request{method: http.MethodGet, path: "/submissions/" + itoa(id)}, &s)
+	return &s, err
+}
+
+// SubmitPoem 投稿一首新诗，comment 为投稿说明。
+func (c *Client) SubmitPoem(ctx context.Context, in PoemInput, comment string) (*SubmissionReceipt, error) {
+	return c.submit(ctx, "/submissions/poems", in, comment)
+}
+
+// SubmitPoemCorrection 投稿诗作勘误，patch 只能修改 title 和 content。
+func (c *Client) SubmitPoemCorrection(ctx context.Context, id int, patch Patch, comment string) (*SubmissionReceipt, error) {
+	return c.submit(ctx, "/submissions/poems/"+itoa(id), patch, comment)
+}
+
+// SubmitAuthorBio 投稿作者小传。
+func (c *Client) SubmitAuthorBio(ctx context.Context, id int, description, comment string) (*SubmissionReceipt, error) {
+	return c.submit(ctx, "/submissions/authors/"+itoa(id), map[string]string{"description": description}, comment)
+}
+
+func (c *Client) submit(ctx context.Context, path string, body any, comment string) (*SubmissionReceipt, error) {
+	var r SubmissionReceipt
+	err := c.do(ctx, request{method: http.MethodPost, path: path, query: commentQuery(comment), body: body}, &r)
+	return &r, err
+}
+
+// ApproveSubmission 审核通过投稿并写入正式数据，需要 editor 角色。
+func (c *Client) ApproveSubmission(ctx context.Context, id int, comment string) (*ReviewResult, error) {
+	var r ReviewResult
+	err := c.do(ctx, request{method: http.MethodPost, path: "/submissions/" + itoa(id) + "/approve", query: commentQuery(comment)}, &r)
+	return &r, err
+}
+
+// RejectSubmission 驳回投稿，必须填写原因 comment，需要 editor 角色。
+func (c *Client) RejectSubmission(ctx context.Context, id int, comment string) (*Result, error) {
+	var r Result
+	err := c.do(ctx, request{method: http.MethodPost, path: "/submissions/" + itoa(id) + "/reject", query: commentQuery(comment)}, &r)
+	return &r, err
+}
+
+func (f AuditFilter) query() url.Values {
+	q := url.Values{}
+	if f.Entity != "" {
+		q.Set("entity", f.Entity)
+	}
+	if f.Actor != "" {
+		q.Set("actor", f.Actor)
+	}
+	if f.Since != "" {
+		q.Set("since", f.Since)
+	}
+	return q
+}
+
+// ListAudit 返回第 page 页审计记录，最新的在前，每页 50 条，需要 admin 角色。
+func (c *Client) ListAudit(ctx context.Context, f AuditFilter, page int) (*Page[AuditRecord], error) {
+	var p Page[AuditRecord]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/admin/audit", query: pageQuery(page, f.query())}, &p)
+	return &p, err
+}
+
+// ExportAudit 按时间先后流式读取全部符合条件的审计记录，需要 admin 角色。
+// 读取中途出错时不会重试，已返回的记录不会重复。
+func (c *Client) ExportAudit(ctx context.Context, f AuditFilter) iter.Seq2[AuditRecord, error] {
+	return func(yield func(AuditRecord, error) bool) {
+		r := request{method: http.MethodGet, path: "/admin/audit/export", query: f.query()}
+		resp, err := c.send(ctx, r)
+		if err != nil {
+			yield(AuditRecord{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for dec.More() {
+			var rec AuditRecord
+			if err := dec.Decode(&rec); err != nil {
+				yield(AuditRecord{}, fmt.Errorf("decode audit export: %w", err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
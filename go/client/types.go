@@ -0,0 +1,357 @@
+package client
+
+import "encoding/json"
+
+// Author 是作者。
+type Author struct {
+	AuthorID    int    `json:"author_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Poems       []Poem `json:"poems"`       // 作者的前 6 首诗作，需 Include.Poems
+	TotalPoems  int    `json:"total_poems"` // 作者的诗作总数，需 Include.Total
+	ImgURL      string `json:"imgUrl"`      // 头像地址
+}
+
+// Poem 是诗作。
+type Poem struct {
+	PoemID   int         `json:"poem_id"`
+	Title    string      `json:"title"`
+	AuthorID int         `json:"author_id"`
+	Content  string      `json:"content"`
+	Source   *PoemSource `json:"source"` // 原始数据中的出处，通过接口新建的诗作为 nil
+}
+
+// PoemSource 是诗作在 chinese-poetry 原始数据中的出处。
+type PoemSource struct {
+	ID    string `json:"id"`
+	File  string `json:"file"`
+	Index int    `json:"index"`
+	Notes string `json:"notes"`
+}
+
+// AuthorInput 是创建或更新作者的请求体。
+type AuthorInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ImgURL      string `json:"imgUrl,omitempty"`
+}
+
+// PoemInput 是创建、更新或投稿诗作的请求体。
+type PoemInput struct {
+	Title    string `json:"title"`
+	AuthorID int    `json:"author_id"`
+	Content  string `json:"content"`
+}
+
+// Patch 是 JSON Merge Patch 请求体：只修改出现的字段，值为 nil 的字段清空。
+type Patch map[string]any
+
+// Include 是作者列表附带的关联数据。
+type Include struct {
+	Poems bool // 每位作者的前 6 首诗作
+	Total bool // 每位作者的诗作总数
+}
+
+func (inc Include) String() string {
+	switch {
+	case inc.Poems && inc.Total:
+		return "poems,total"
+	case inc.Poems:
+		return "poems"
+	case inc.Total:
+		return "total"
+	}
+	return ""
+}
+
+// AuthorsByNumber 是前 number 位作者。
+type AuthorsByNumber struct {
+	RequestedNumber int      `json:"requested_number"`
+	TotalAvailable  int      `json:"total_available"`
+	Data            []Author `json:"data"`
+}
+
+// Result 是写操作的响应。
+type Result struct {
+	Message    string `json:"message"`
+	RevisionID int64  `json:"revision_id,omitempty"` // 更新和回退生成的修订，字段没有变化时为 0
+}
+
+// DeleteAuthorResult 是删除作者的响应。
+type DeleteAuthorResult struct {
+	Message         string `json:"message"`
+	PoemsDeleted    int64  `json:"poems_deleted,omitempty"`
+	PoemsReassigned int64  `json:"poems_reassigned,omitempty"`
+	To              int    `json:"to,omitempty"`
+}
+
+// 删除作者时对其诗作的处理方式
+const (
+	PoemsRestrict = "restrict" // 作者还有诗作时拒绝删除
+	PoemsCascade  = "cascade"  // 一并删除
+	PoemsReassign = "reassign" // 转给另一位作者
+)
+
+// Edit 是字符级差异中的一段。
+type Edit struct {
+	Op   string `json:"op"` // equal、insert 或 delete
+	Text string `json:"text"`
+}
+
+// Revision 是作者或诗作的一次修订。
+type Revision struct {
+	RevisionID int               `json:"revision_id"`
+	Entity     string            `json:"entity"`
+	EntityID   int               `json:"entity_id"`
+	Actor      string            `json:"actor"`
+	CreatedAt  string            `json:"created_at"`
+	Comment    string            `json:"comment"`
+	Previous   map[string]any    `json:"previous"`
+	Data       map[string]any    `json:"data"`
+	Diff       map[string][]Edit `json:"diff"`
+}
+
+// 回收站类型
+const (
+	TrashAuthors = "authors"
+	TrashPoems   = "poems"
+)
+
+// TrashItem 是回收站中的作者或诗作。
+type TrashItem struct {
+	Type      string `json:"type"` // TrashAuthors 或 TrashPoems
+	ID        int    `json:"id"`
+	Title     string `json:"title"` // 作者名或诗题
+	DeletedAt string `json:"deleted_at"`
+	DeletedBy string `json:"deleted_by"`
+}
+
+// RestoreResult 是从回收站恢复的响应。
+type RestoreResult struct {
+	Message       string `json:"message"`
+	PoemsRestored int64  `json:"poems_restored,omitempty"` // 随作者一并恢复的诗作数
+}
+
+// 投稿类型与状态
+const (
+	KindNewPoem        = "new_poem"
+	KindPoemCorrection = "poem_correction"
+	KindAuthorBio      = "author_bio"
+
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusAll      = "all" // 只用于列表过滤
+)
+
+// Submission 是投稿。
+type Submission struct {
+	SubmissionID  int               `json:"submission_id"`
+	Kind          string            `json:"kind"`
+	EntityID      *int              `json:"entity_id"` // 新诗在审核通过后才有 ID
+	Changes       map[string]any    `json:"changes"`
+	Comment       string            `json:"comment"`
+	Status        string            `json:"status"`
+	SubmittedBy   string            `json:"submitted_by"`
+	SubmittedAt   string            `json:"submitted_at"`
+	ReviewedBy    *string           `json:"reviewed_by"`
+	ReviewedAt    *string           `json:"reviewed_at"`
+	ReviewComment *string           `json:"review_comment"`
+	RevisionID    *int64            `json:"revision_id,omitempty"`
+	Diff          map[string][]Edit `json:"diff"`
+}
+
+// SubmissionFilter 是投稿列表的过滤条件，零值为全部待审核的投稿。
+type SubmissionFilter struct {
+	Status      string // 默认 StatusPending
+	Kind        string
+	SubmittedBy string
+}
+
+// SubmissionReceipt 是投稿的响应。
+type SubmissionReceipt struct {
+	Message      string `json:"message"`
+	SubmissionID int    `json:"submission_id"`
+	Status       string `json:"status"`
+}
+
+// ReviewResult 是审核通过的响应。
+type ReviewResult struct {
+	Message    string `json:"message"`
+	EntityID   int    `json:"entity_id"`
+	RevisionID int64  `json:"revision_id,omitempty"`
+}
+
+// AuditRecord 是一条审计记录。
+type AuditRecord struct {
+	AuditID   int             `json:"audit_id"`
+	CreatedAt string          `json:"created_at"`
+	Actor     string          `json:"actor"`
+	Route     string          `json:"route"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIP  string          `json:"client_ip"`
+	RequestID string          `json:"request_id"`
+}
+
+// AuditFilter 是审计记录的过滤条件。
+type AuditFilter struct {
+	Entity string // author 或 poem
+	Actor  string
+	Since  string // RFC3339 时间
+}
+
+// 导出格式
+const (
+	FormatNDJSON        = "ndjson" // 每行一条，默认
+	FormatCSV           = "csv"    // 首行为列名
+	FormatJSON          = "json"   // 数组
+	FormatChinesePoetry = "chinese-poetry"
+)
+
+// ExportFilter 是导出的过滤条件，均为精确匹配，零值不过滤。
+type ExportFilter struct {
+	AuthorID int
+	Author   string // 作者名
+	Dynasty  string
+	Form     string // 体裁，如 七言绝句
+	Tag      string // 《唐诗三百首》的标签
+}
+
+// ExportPoem 是导出的一首诗作。
+type ExportPoem struct {
+	PoemID   int         `json:"poem_id"`
+	Title    string      `json:"title"`
+	AuthorID int         `json:"author_id"`
+	Author   string      `json:"author"`
+	Dynasty  string      `json:"dynasty"`
+	Form     string      `json:"form"`
+	Tags     []string    `json:"tags"`
+	Content  string      `json:"content"`
+	Source   *PoemSource `json:"source"`
+}
+
+// ExportAuthor 是导出的一位作者。
+type ExportAuthor struct {
+	AuthorID    int    `json:"author_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ImgURL      string `json:"imgUrl"`
+	Dynasty     string `json:"dynasty"`
+	PoemCount   int    `json:"poem_count"`
+}
+
+// ImportRecord 是导入的一条记录，与 poet.tang.*.json 中的一条相同。
+type ImportRecord struct {
+	Author     string   `json:"author"`
+	Title      string   `json:"title"`
+	Paragraphs []string `json:"paragraphs"`
+	ID         string   `json:"id,omitempty"` // 原始数据中的 UUID 或导出时的 id，用于匹配已有诗作
+	Notes      []string `json:"notes,omitempty"`
+}
+
+// 导入记录的处理方式
+const (
+	ImportInsert    = "insert"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+	ImportConflict  = "conflict"
+	ImportInvalid   = "invalid"
+)
+
+// ImportReport 是导入的处理报告，Items 不含没有变化的记录。
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Applied bool           `json:"applied"`
+	Summary map[string]int `json:"summary"` // 各处理方式的记录数
+	Items   []ImportItem   `json:"items"`
+}
+
+// ImportItem 是导入报告中的一条记录。
+type ImportItem struct {
+	File       string       `json:"file"`
+	Index      int          `json:"index"` // 在上传内容中的序号，从 0 开始
+	ID         string       `json:"id"`
+	Author     string       `json:"author"`
+	Title      string       `json:"title"`
+	Action     string       `json:"action"`     // ImportInsert 等
+	PoemID     int          `json:"poem_id"`    // 对应的已有诗作，新建时为新诗作的 ID（仅实际导入后）
+	MatchedBy  string       `json:"matched_by"` // id 或 author_title
+	Changed    []string     `json:"changed"`    // 更新的字段，记录出处时含 source
+	Reason     string       `json:"reason"`     // 冲突原因：ambiguous、duplicate 或 deleted
+	Candidates []int        `json:"candidates"` // 作者和标题对应的全部诗作
+	Fields     []FieldError `json:"fields"`     // 未通过校验的字段
+}
+
+// FieldError 是一个未通过校验的字段。
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Max   int    `json:"max,omitempty"`
+	Line  int    `json:"line,omitempty"`
+}
+
+// Token 是用 API Key 换取的 JWT。
+type Token struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresAt string `json:"expires_at"`
+	Role      string `json:"role"`
+}
+
+// Stats 是作者数、诗作数和字数。
+type Stats struct {
+	Poets int `json:"poets"`
+	Poems int `json:"poems"`
+	Words int `json:"words"`
+}
+
+// AuthorCount 是图表中一位作者的诗作数和字数。
+type AuthorCount struct {
+	AuthorID   int    `json:"author_id"`
+	AuthorName string `json:"author_name"`
+	Dynasty    string `json:"dynasty,omitempty"` // 只在表格数据中出现
+	PoemCount  int    `json:"poem_count"`
+	WordCount  int    `json:"word_count"`
+}
+
+// Chart 是 /data/echart/two 的图表数据。
+type Chart struct {
+	Authors    []AuthorCount `json:"authors"`
+	TotalPoems int           `json:"total_poems"`
+	TotalWords int           `json:"total_words"`
+}
+
+// Table 是 /data/table 的表格数据。
+type Table struct {
+	List       []AuthorCount `json:"list"`
+	TotalPoems int           `json:"total_poems"`
+	TotalWords int           `json:"total_words"`
+}
+
+// QualityReport 是 /reports/quality 的数据质量报告。
+type QualityReport struct {
+	Total  int            `json:"total"`
+	Checks []QualityCheck `json:"checks"`
+}
+
+// QualityCheck 是一项检查的问题数和有问题的记录，Items 最多为请求的 limit 条。
+type QualityCheck struct {
+	Check       string        `json:"check"`
+	Description string        `json:"description"`
+	Count       int           `json:"count"`
+	Skipped     string        `json:"skipped,omitempty"`
+	Items       []QualityItem `json:"items"`
+}
+
+// QualityItem 是一条有问题的诗作或作者，Link 为其接口地址。
+type QualityItem struct {
+	Entity string `json:"entity"`
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+	Link   string `json:"link"`
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commands 是命令行子命令，用法：go run . <command> [flags]
+var commands = map[string]func(args []string) error{
+	"integrity": runIntegrity,
+	"purge":     runPurge,
+	"user":      runUser,
+	"key":       runKey,
+	"token":     runToken,
+	"openapi":   runOpenAPI,
+	"export":    runExport,
+	"sync":      runSync,
+	"lint":      runLint,
+}
+
+func runCommand(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown command %q, available: %s", name, strings.Join(names, ", "))
+	}
+	return cmd(args)
+}
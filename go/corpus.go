@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// corpusDynasty 是全部作者的朝代，数据只包含《全唐诗》，与 data_table 视图一致
+const corpusDynasty = "唐"
+
+// 诗作的体裁，按句数和每句字数推断
+const (
+	formWujue   = "五言绝句"
+	formQijue   = "七言绝句"
+	formWulv    = "五言律诗"
+	formQilv    = "七言律诗"
+	formWupai   = "五言排律"
+	formQipai   = "七言排律"
+	formWugu    = "五言古诗"
+	formQigu    = "七言古诗"
+	formZayan   = "杂言"
+	formUnknown = "未知"
+)
+
+// poemForms 是全部体裁，用于校验 ?form= 参数
+var poemForms = []string{formWujue, formQijue, formWulv, formQilv, formWupai, formQipai, formWugu, formQigu, formZayan, formUnknown}
+
+// verses 把诗作内容按句读标点拆分为句，忽略空句。
+func verses(content string) []string {
+	return strings.FieldsFunc(content, func(r rune) bool {
+		return r == '\n' || strings.ContainsRune("，。？！；：、,.?!;:", r) || unicode.IsSpace(r)
+	})
+}
+
+// poemForm 推断诗作的体裁：每句字数相同且为五言或七言时，四句为绝句，八句为律诗，十句及以上的偶数句为排律，
+// 其他为古诗；每句字数不同时为杂言。只看句数和字数，不检查平仄和对仗，古体的四句诗也会归为绝句。
+func poemForm(content string) string {
+	vs := verses(content)
+	if len(vs) == 0 {
+		return formUnknown
+	}
+	n := utf8.RuneCountInString(vs[0])
+	for _, v := range vs[1:] {
+		if utf8.RuneCountInString(v) != n {
+			return formZayan
+		}
+	}
+	if n != 5 && n != 7 {
+		return formZayan
+	}
+
+	five := n == 5
+	pick := func(wu, qi string) string {
+		if five {
+			return wu
+		}
+		return qi
+	}
+	switch count := len(vs); {
+	case count == 4:
+		return pick(formWujue, formQijue)
+	case count == 8:
+		return pick(formWulv, formQilv)
+	case count >= 10 && count%2 == 0:
+		return pick(formWupai, formQipai)
+	default:
+		return pick(formWugu, formQigu)
+	}
+}
+
+// corpusDir 是 chinese-poetry 原始数据的目录，与 app.py 相同
+const corpusDir = "./全唐诗"
+
+// supplementFile 是《全唐诗》以外补录的诗作，带有 notes，部分没有 id
+const supplementFile = "唐诗补录.json"
+
+// sourcePoem 是 poet.tang.*.json 中的一首诗，其他字段忽略。
+type sourcePoem struct {
+	Author     string      `json:"author"`
+	Title      string      `json:"title"`
+	Paragraphs []string    `json:"paragraphs"`
+	ID         string      `json:"id"`
+	Notes      sourceNotes `json:"notes"`
+
+	file  string
+	index int
+}
+
+// sourceNotes 是诗作的说明，poet.tang.*.json 中为字符串数组，唐诗补录.json 中为字符串，统一保存为以换行分隔的字符串。
+type sourceNotes string
+
+func (n *sourceNotes) UnmarshalJSON(b []byte) error {
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err == nil {
+		*n = sourceNotes(strings.Join(lines, "\n"))
+		return nil
+	}
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s != nil {
+		*n = sourceNotes(*s)
+	}
+	return nil
+}
+
+func (s sourcePoem) content() string {
+	return strings.Join(s.Paragraphs, "\n")
+}
+
+// decodeSourcePoems 解析一个文件，以 [ 开头时为 JSON 数组，否则为每行一条的 NDJSON。
+func decodeSourcePoems(file string, body []byte) ([]sourcePoem, error) {
+	body = bytes.TrimPrefix(body, []byte("\ufeff"))
+	var poems []sourcePoem
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &poems); err != nil {
+			return nil, apierror.Validation(apierror.CodeInvalidJSON, gin.H{"file": file}).WithCause(err)
+		}
+	} else {
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		sc.Buffer(nil, len(body)+1)
+		for line := 1; sc.Scan(); line++ {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+			var p sourcePoem
+			if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+				return nil, apierror.Validation(apierror.CodeInvalidJSON, gin.H{"file": file, "line": line}).WithCause(err)
+			}
+			poems = append(poems, p)
+		}
+	}
+	for i := range poems {
+		poems[i].file, poems[i].index = file, i
+	}
+	return poems, nil
+}
+
+// corpusFiles 返回 dir 中按编号排列的 poet.tang.*.json，以及补录文件（存在时）。
+func corpusFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "poet.tang."), ".json"))
+		return n
+	}
+	var files []string
+	supplement := false
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, "poet.tang.") && strings.HasSuffix(name, ".json") {
+			files = append(files, name)
+		}
+		supplement = supplement || name == supplementFile
+	}
+	sort.Slice(files, func(i, j int) bool { return number(files[i]) < number(files[j]) })
+	if supplement {
+		files = append(files, supplementFile)
+	}
+	return files, nil
+}
+
+// eachCorpusFile 依次读取 dir 中的每个文件并调用 fn，同一时间只在内存中保留一个文件。
+func eachCorpusFile(dir string, fn func(file string, poems []sourcePoem) error) error {
+	files, err := corpusFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		body, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		poems, err := decodeSourcePoems(file, body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := fn(file, poems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 导出格式
+const (
+	exportCSV           = "csv"
+	exportNDJSON        = "ndjson" // 默认，每行一条
+	exportJSON          = "json"
+	exportChinesePoetry = "chinese-poetry" // 与 全唐诗/poet.tang.*.json、authors.tang.json 结构相同
+)
+
+var exportFormats = []string{exportCSV, exportNDJSON, exportJSON, exportChinesePoetry}
+
+// exportFilter 是导出的过滤条件，均为精确匹配，未设置的条件不过滤。
+type exportFilter struct {
+	AuthorID int
+	Author   string // 作者名
+	Dynasty  string
+	Form     string // 体裁，见 poemForm
+	Tag      string // 《唐诗三百首》的标签
+}
+
+// validate 检查格式和取值范围。
+func (f exportFilter) validate(format string) error {
+	if !slices.Contains(exportFormats, format) {
+		return apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "format", "allowed": exportFormats})
+	}
+	if f.Form != "" && !slices.Contains(poemForms, f.Form) {
+		return apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "form", "allowed": poemForms})
+	}
+	if f.AuthorID < 0 {
+		return apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "author_id"})
+	}
+	return nil
+}
+
+// ExportPoem 是导出的一首诗作。
+type ExportPoem struct {
+	PoemID   int         `json:"poem_id"`
+	Title    string      `json:"title"`
+	AuthorID int         `json:"author_id"`
+	Author   string      `json:"author"`
+	Dynasty  string      `json:"dynasty"`
+	Form     string      `json:"form"`
+	Tags     []string    `json:"tags"`
+	Content  string      `json:"content"`
+	Source   *PoemSource `json:"source,omitempty"`
+}
+
+// ExportAuthor 是导出的一位作者。
+type ExportAuthor struct {
+	AuthorID    int    `json:"author_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ImgUrl      string `json:"imgUrl"`
+	Dynasty     string `json:"dynasty"`
+	PoemCount   int    `json:"poem_count"`
+}
+
+// chinesePoetryPoem 与 poet.tang.*.json 中的一条相同，字段顺序也相同。
+type chinesePoetryPoem struct {
+	Author     string   `json:"author"`
+	Paragraphs []string `json:"paragraphs"`
+	Title      string   `json:"title"`
+	ID         string   `json:"id"`
+	Notes      []string `json:"notes,omitempty"` // 说明，同 poet.tang.*.json 为字符串数组
+}
+
+// chinesePoetryAuthor 与 authors.tang.json 中的一条相同。
+type chinesePoetryAuthor struct {
+	Desc string `json:"desc"`
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+var (
+	exportPoemColumns   = []string{"poem_id", "title", "author_id", "author", "dynasty", "form", "tags", "content", "source_id"}
+	exportAuthorColumns = []string{"author_id", "name", "description", "imgUrl", "dynasty", "poem_count"}
+)
+
+func (p ExportPoem) csvRow() []string {
+	var source string
+	if p.Source != nil {
+		source = p.Source.ID
+	}
+	return []string{strconv.Itoa(p.PoemID), p.Title, strconv.Itoa(p.AuthorID), p.Author, p.Dynasty, p.Form,
+		strings.Join(p.Tags, ","), p.Content, source}
+}
+
+// chinesePoetry 的 id 为原始数据中的 UUID，没有时（如通过接口新建的诗作）由本地 ID 生成。
+func (p ExportPoem) chinesePoetry() any {
+	cp := chinesePoetryPoem{Author: p.Author, Paragraphs: strings.Split(p.Content, "\n"), Title: p.Title, ID: localSourceID(entityPoem, p.PoemID)}
+	if p.Source != nil {
+		if p.Source.Notes != "" {
+			cp.Notes = strings.Split(p.Source.Notes, "\n")
+		}
+		if p.Source.ID != "" {
+			cp.ID = p.Source.ID
+		}
+	}
+	return cp
+}
+
+func (a ExportAuthor) csvRow() []string {
+	return []string{strconv.Itoa(a.AuthorID), a.Name, a.Description, a.ImgUrl, a.Dynasty, strconv.Itoa(a.PoemCount)}
+}
+
+func (a ExportAuthor) chinesePoetry() any {
+	return chinesePoetryAuthor{Desc: a.Description, Name: a.Name, ID: localSourceID(entityAuthor, a.AuthorID)}
+}
+
+// localSourceID 返回没有原始 id 的记录在 chinese-poetry 格式中的 id：由实体类型和本地 ID 生成的 UUID（第 5 版），
+// 同一条记录每次导出都相同。
+func localSourceID(entity string, id int) string {
+	sum := sha1.Sum([]byte("poetry:" + entity + ":" + strconv.Itoa(id)))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// eachExportPoem 按 poem_id 顺序逐条读取符合 f 的诗作并调用 fn，分批读取，不在内存中保留全部诗作。
+// 体裁在读取后判断，标签来自 Tang300，按作者名和标题对应。
+func eachExportPoem(ctx context.Context, f exportFilter, fn func(ExportPoem) error) error {
+	if f.Dynasty != "" && f.Dynasty != corpusDynasty {
+		return nil
+	}
+	tags, err := loadTagIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := `SELECT COALESCE(a.name, ''), p.poem_id, p.title, p.author_id, p.content,
+		p.source_id, p.source_file, p.source_index, p.source_notes FROM Poems p
+		LEFT JOIN Authors a ON a.author_id = p.author_id WHERE p.deleted_at IS NULL`
+	var args []any
+	if f.AuthorID != 0 {
+		q += " AND p.author_id = ?"
+		args = append(args, f.AuthorID)
+	}
+	if f.Author != "" {
+		q += " AND a.name = ?"
+		args = append(args, f.Author)
+	}
+	if f.Tag != "" {
+		ids := tags.poems[f.Tag]
+		if len(ids) == 0 {
+			return nil
+		}
+		q += " AND p.poem_id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	scan := func(rows rowScanner) (ExportPoem, int, error) {
+		var author string
+		poem, err := scanPoem(rows, &author)
+		return ExportPoem{PoemID: poem.PoemID, Title: poem.Title, AuthorID: poem.AuthorID, Author: author,
+			Dynasty: corpusDynasty, Content: poem.Content, Source: poem.Source}, poem.PoemID, err
+	}
+	return eachBatch(ctx, "export_poems", "poems", q, "p.poem_id", args, scan, func(p ExportPoem) error {
+		p.Form = poemForm(p.Content)
+		if f.Form != "" && p.Form != f.Form {
+			return nil
+		}
+		p.Tags = tags.byPoem[p.PoemID]
+		if p.Tags == nil {
+			p.Tags = []string{}
+		}
+		return fn(p)
+	})
+}
+
+// eachExportAuthor 按 author_id 顺序逐条读取符合 f 的作者并调用 fn。指定体裁或标签时只导出有这类诗作的作者。
+func eachExportAuthor(ctx context.Context, f exportFilter, fn func(ExportAuthor) error) error {
+	if f.Dynasty != "" && f.Dynasty != corpusDynasty {
+		return nil
+	}
+	var withPoems map[int]bool
+	if f.Form != "" || f.Tag != "" {
+		withPoems = map[int]bool{}
+		err := eachExportPoem(ctx, f, func(p ExportPoem) error {
+			withPoems[p.AuthorID] = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	q := `SELECT a.author_id, a.name, a.description, COALESCE(a.imgUrl, ''),
+		(SELECT COUNT(*) FROM Poems p WHERE p.author_id = a.author_id AND p.deleted_at IS NULL)
+		FROM Authors a WHERE a.deleted_at IS NULL`
+	var args []any
+	if f.AuthorID != 0 {
+		q += " AND a.author_id = ?"
+		args = append(args, f.AuthorID)
+	}
+	if f.Author != "" {
+		q += " AND a.name = ?"
+		args = append(args, f.Author)
+	}
+	scan := func(rows rowScanner) (ExportAuthor, int, error) {
+		a := ExportAuthor{Dynasty: corpusDynasty}
+		err := rows.Scan(&a.AuthorID, &a.Name, &a.Description, &a.ImgUrl, &a.PoemCount)
+		return a, a.AuthorID, err
+	}
+	return eachBatch(ctx, "export_authors", "authors", q, "a.author_id", args, scan, func(a ExportAuthor) error {
+		if withPoems != nil && !withPoems[a.AuthorID] {
+			return nil
+		}
+		return fn(a)
+	})
+}
+
+// exportBatchSize 是导出时每次查询读取的条数
+const exportBatchSize = 500
+
+// eachBatch 按主键 key 的顺序分批执行 q（以 WHERE 条件结尾），每批读完并关闭游标后再对每条记录调用 fn。
+// 导出在慢速连接上会持续很久，一直打开的游标让 SQLite 保持读锁，期间所有写操作等待 _busy_timeout 后失败；
+// 分批读取时各批之间不持有锁，导出的不是同一时刻的快照，导出期间修改的记录按读到时的内容导出。
+func eachBatch[T any](ctx context.Context, name, what, q, key string, args []any,
+	scan func(rowScanner) (T, int, error), fn func(T) error) error {
+	q += " AND " + key + " > ? ORDER BY " + key + " LIMIT ?"
+	after := 0
+	for {
+		batch, err := readBatch(ctx, name, q, append(slices.Clip(args), after, exportBatchSize), scan)
+		if err != nil {
+			return apierror.Storage(fmt.Errorf("read %s: %w", what, err))
+		}
+		for _, r := range batch {
+			if err := fn(r.item); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		after = batch[len(batch)-1].key
+	}
+}
+
+type batchItem[T any] struct {
+	item T
+	key  int
+}
+
+func readBatch[T any](ctx context.Context, name, q string, args []any, scan func(rowScanner) (T, int, error)) ([]batchItem[T], error) {
+	rows, err := query(ctx, name, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	batch := make([]batchItem[T], 0, exportBatchSize)
+	for rows.Next() {
+		item, key, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, batchItem[T]{item, key})
+	}
+	return batch, rows.Err()
+}
+
+// exportRecord 是可以按各种格式写出的一条记录。
+type exportRecord interface {
+	csvRow() []string
+	chinesePoetry() any
+}
+
+// exportWriter 按格式逐条写出记录，close 写出结尾（如 JSON 数组的右括号）并刷新缓冲。
+// indent 是 JSON 数组元素的缩进：与原始文件一致，poet.tang.*.json 缩进四格，authors.tang.json 缩进两格。
+type exportWriter struct {
+	format string
+	indent string
+	w      *bufio.Writer
+	csv    *csv.Writer
+	n      int
+}
+
+func newExportWriter(w io.Writer, format, indent string, columns []string) (*exportWriter, error) {
+	ew := &exportWriter{format: format, indent: indent, w: bufio.NewWriter(w)}
+	if format == exportCSV {
+		ew.csv = csv.NewWriter(ew.w)
+		if err := ew.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	return ew, nil
+}
+
+func (ew *exportWriter) write(r exportRecord) error {
+	defer func() { ew.n++ }()
+	switch ew.format {
+	case exportCSV:
+		return ew.csv.Write(r.csvRow())
+	case exportNDJSON:
+		b, err := marshalExport(r, "")
+		if err != nil {
+			return err
+		}
+		_, err = ew.w.Write(append(b, '\n'))
+		return err
+	}
+
+	var v any = r
+	if ew.format == exportChinesePoetry {
+		v = r.chinesePoetry()
+	}
+	b, err := marshalExport(v, ew.indent)
+	if err != nil {
+		return err
+	}
+	sep := ",\n" + ew.indent
+	if ew.n == 0 {
+		sep = "[\n" + ew.indent
+	}
+	if _, err := ew.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = ew.w.Write(b)
+	return err
+}
+
+func (ew *exportWriter) close() error {
+	switch ew.format {
+	case exportCSV:
+		ew.csv.Flush()
+		if err := ew.csv.Error(); err != nil {
+			return err
+		}
+	case exportJSON, exportChinesePoetry:
+		end := "\n]\n"
+		if ew.n == 0 {
+			end = "[]\n"
+		}
+		if _, err := ew.w.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return ew.w.Flush()
+}
+
+// marshalExport 编码一条记录，不转义 HTML 字符；indent 不为空时按数组元素缩进。
+func marshalExport(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent(indent, indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// exportPoems 把符合 f 的诗作按 format 写到 w，返回条数。
+func exportPoems(ctx context.Context, w io.Writer, format string, f exportFilter) (int, error) {
+	ew, err := newExportWriter(w, format, "    ", exportPoemColumns)
+	if err != nil {
+		return 0, err
+	}
+	err = eachExportPoem(ctx, f, func(p ExportPoem) error { return ew.write(p) })
+	if err != nil {
+		return ew.n, err
+	}
+	return ew.n, ew.close()
+}
+
+// exportAuthors 把符合 f 的作者按 format 写到 w，返回条数。
+func exportAuthors(ctx context.Context, w io.Writer, format string, f exportFilter) (int, error) {
+	ew, err := newExportWriter(w, format, "  ", exportAuthorColumns)
+	if err != nil {
+		return 0, err
+	}
+	err = eachExportAuthor(ctx, f, func(a ExportAuthor) error { return ew.write(a) })
+	if err != nil {
+		return ew.n, err
+	}
+	return ew.n, ew.close()
+}
+
+// exportContentTypes 是各格式的 Content-Type 和文件扩展名
+var exportContentTypes = map[string][2]string{
+	exportCSV:           {"text/csv; charset=utf-8", "csv"},
+	exportNDJSON:        {"application/x-ndjson", "ndjson"},
+	exportJSON:          {"application/json", "json"},
+	exportChinesePoetry: {"application/json", "json"},
+}
+
+// exportFilename 返回下载的文件名，chinese-poetry 格式与原始文件同名。
+func exportFilename(entity, format string) string {
+	if format == exportChinesePoetry {
+		if entity == entityAuthor {
+			return "authors.tang.json"
+		}
+		return "poet.tang.json"
+	}
+	return entity + "s." + exportContentTypes[format][1]
+}
+
+func exportPoemsHandler(c *gin.Context)   { exportHandler(c, entityPoem, exportPoems) }
+func exportAuthorsHandler(c *gin.Context) { exportHandler(c, entityAuthor, exportAuthors) }
+
+// 导出诗作或作者，格式由 ?format= 指定，过滤条件为 ?author_id=&author=&dynasty=&form=&tag=。
+// 逐条写出，内存占用与数据量无关；响应已开始后出错时记录日志并中断连接。
+func exportHandler(c *gin.Context, entity string, export func(context.Context, io.Writer, string, exportFilter) (int, error)) {
+	format := c.DefaultQuery("format", exportNDJSON)
+	f := exportFilter{
+		Author:  c.Query("author"),
+		Dynasty: c.Query("dynasty"),
+		Form:    c.Query("form"),
+		Tag:     c.Query("tag"),
+	}
+	if v := c.Query("author_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "author_id"}))
+			return
+		}
+		f.AuthorID = id
+	}
+	if err := f.validate(format); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[format][0])
+	c.Header("Content-Disposition", `attachment; filename="`+exportFilename(entity, format)+`"`)
+	c.Status(http.StatusOK)
+	extendWriteDeadline(c)
+
+	n, err := export(c.Request.Context(), c.Writer, format, f)
+	setResultCount(c, n)
+	if err != nil {
+		abortResponse(c, err)
+	}
+}
+
+// runExport 实现 export 命令：go run . export poems|authors [-format ndjson] [-author 李白] [-form 七言绝句] [-tag 送别] [-o file]
+func runExport(args []string) error {
+	if len(args) == 0 || (args[0] != "poems" && args[0] != "authors") {
+		return fmt.Errorf("export: expected poems or authors")
+	}
+	entity := strings.TrimSuffix(args[0], "s")
+	fs := flag.NewFlagSet("export "+args[0], flag.ExitOnError)
+	format := fs.String("format", exportNDJSON, "格式："+strings.Join(exportFormats, "、"))
+	out := fs.String("o", "", "输出文件，默认为标准输出")
+	var f exportFilter
+	fs.IntVar(&f.AuthorID, "author-id", 0, "作者 ID")
+	fs.StringVar(&f.Author, "author", "", "作者名")
+	fs.StringVar(&f.Dynasty, "dynasty", "", "朝代")
+	fs.StringVar(&f.Form, "form", "", "体裁："+strings.Join(poemForms, "、"))
+	fs.StringVar(&f.Tag, "tag", "", "《唐诗三百首》的标签")
+	fs.Parse(args[1:])
+	if !slices.Contains(exportFormats, *format) {
+		return fmt.Errorf("export: unknown format %q, available: %s", *format, strings.Join(exportFormats, ", "))
+	}
+	if f.Form != "" && !slices.Contains(poemForms, f.Form) {
+		return fmt.Errorf("export: unknown form %q, available: %s", f.Form, strings.Join(poemForms, ", "))
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	export := exportPoems
+	if entity == entityAuthor {
+		export = exportAuthors
+	}
+	n, err := export(context.Background(), w, *format, f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d %ss\n", n, entity)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"poetry/client"
+)
+
+// seedPoems 为作者 1 追加 n 首诗，使导出的内容超过响应缓冲区，响应头和前面的记录先写出
+func seedPoems(t *testing.T, n int) {
+	t.Helper()
+	_, err := db.Exec(`WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM seq WHERE i < ?)
+		INSERT INTO Poems (title, author_id, content) SELECT '无题' || i, 1, replace(hex(zeroblob(100)), '0', '月') FROM seq`, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 导出中途出错时中断连接，读取方得到 unexpected EOF，而不是看起来完整的文件
+func TestExportAbortsOnError(t *testing.T) {
+	openTestDB(t)
+	seedPoems(t, 600)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+	ctx := context.Background()
+	c := client.New(srv.URL + apiPrefix)
+
+	n := 0
+	for _, err := range c.ExportedPoems(ctx, client.ExportFilter{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 605 {
+		t.Fatalf("exported %d poems, want 605", n)
+	}
+
+	// 标题为 NULL 的诗作在读取时出错
+	if _, err := db.Exec("INSERT INTO Poems (title, author_id, content) VALUES (NULL, 1, '无题')"); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{exportNDJSON, exportCSV} {
+		resp, err := http.Get(srv.URL + "/api/export/poems?format=" + format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d", format, resp.StatusCode)
+		}
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: read error %v, want unexpected EOF", format, err)
+		}
+	}
+
+	n = 0
+	var last error
+	for _, err := range c.ExportedPoems(ctx, client.ExportFilter{}) {
+		if err != nil {
+			last = err
+			break
+		}
+		n++
+	}
+	if !errors.Is(last, io.ErrUnexpectedEOF) || n == 0 || n >= 605 {
+		t.Errorf("client: %d poems then %v, want a partial export and unexpected EOF", n, last)
+	}
+}
+
+// 客户端读取导出很慢时，服务端停在导出中途，不持有读锁，编辑照常完成
+func TestExportDoesNotBlockWrites(t *testing.T) {
+	openTestDB(t)
+	seedPoems(t, 20000)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/export/poems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	// 等服务端写满连接的缓冲区
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	doJSON(t, srv, http.MethodPatch, "/api/authors/3", strings.NewReader(`{"description":"字摩诘，号摩诘居士"}`), http.StatusOK, nil)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("PATCH took %v while an export was open", d)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlotel "github.com/graph-gophers/graphql-go/trace/otel"
+
+	"poetry/apierror"
+	"poetry/batch"
+)
+
+//go:embed docs/schema.graphql
+var graphSchema string
+
+// GraphQL 查询的限制
+const (
+	maxGraphPageSize    = 50    // pageSize、first 的上限
+	maxGraphComplexity  = 5000  // 一次请求估算的字段数上限，也是实际返回的对象数上限
+	maxGraphDepth       = 10    // 最大嵌套层数
+	maxGraphQueryLength = 10000 // 查询文本的最大字节数
+	graphTagsPerPoem    = 5     // 估算代价时每首诗的标签数
+)
+
+// graphBatchWait 是 dataloader 收集同一层字段的时间窗口
+const graphBatchWait = time.Millisecond
+
+// graphListFields 是返回列表的字段及其条数参数和默认值，用于估算查询代价。这些字段名在 schema 中
+// 只用于列表；items 的条数由根字段的 pageSize 决定。
+var graphListFields = map[string]struct {
+	arg string
+	def int
+}{
+	"poems":      {"first", 6},
+	"similar":    {"first", 5},
+	"topAuthors": {"first", 10},
+	"tags":       {"", graphTagsPerPoem},
+}
+
+// newGraphSchema 解析 docs/schema.graphql 并绑定解析器。
+func newGraphSchema() (*graphql.Schema, error) {
+	return graphql.ParseSchema(graphSchema, &graphRoot{},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxGraphDepth),
+		graphql.MaxQueryLength(maxGraphQueryLength),
+		// 同一层的列表元素并发解析，dataloader 才能把它们合并为一次查询
+		graphql.MaxParallelism(maxGraphPageSize),
+		graphql.Tracer(&gqlotel.Tracer{Tracer: tracer}),
+	)
+}
+
+// graphRequest 是一次 GraphQL 请求的状态：dataloader 和查询代价计数，不跨请求共享。
+type graphRequest struct {
+	log       *slog.Logger
+	requestID string
+	lang      string
+
+	estimated atomic.Int64 // 各根字段估算的代价之和
+	objects   atomic.Int64 // 已返回的对象数
+
+	authors *batch.Loader[int, Author]
+	totals  *batch.Loader[int, poemTotals]
+	poems   *batch.Loader[int, Poem]
+
+	mu          sync.Mutex
+	authorPoems map[[2]int]*batch.Loader[int, []Poem] // 按 (first, offset) 区分
+	similar     map[int]*batch.Loader[int, []Poem]    // 按 first 区分
+
+	tagsOnce sync.Once
+	tags     *tagIndex
+	tagsErr  error
+}
+
+type graphRequestKey struct{}
+
+func newGraphRequest(c *gin.Context) *graphRequest {
+	return &graphRequest{
+		log:         logger(c),
+		requestID:   apierror.RequestID(c),
+		lang:        apierror.Lang(c.GetHeader("Accept-Language")),
+		authors:     batch.New(authorsByID, graphBatchWait, loaderBatchSize),
+		totals:      batch.New(poemTotalsByAuthor, graphBatchWait, loaderBatchSize),
+		poems:       batch.New(poemsByID, graphBatchWait, loaderBatchSize),
+		authorPoems: map[[2]int]*batch.Loader[int, []Poem]{},
+		similar:     map[int]*batch.Loader[int, []Poem]{},
+	}
+}
+
+func graphReq(ctx context.Context) *graphRequest {
+	return ctx.Value(graphRequestKey{}).(*graphRequest)
+}
+
+// authorPoemsLoader 返回按 (first, offset) 批量读取作者诗作的 dataloader
+func (r *graphRequest) authorPoemsLoader(first, offset int) *batch.Loader[int, []Poem] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := [2]int{first, offset}
+	l, ok := r.authorPoems[key]
+	if !ok {
+		l = batch.New(func(ctx context.Context, ids []int) (map[int][]Poem, error) {
+			return poemsByAuthor(ctx, ids, offset, first)
+		}, graphBatchWait, loaderBatchSize)
+		r.authorPoems[key] = l
+	}
+	return l
+}
+
+// similarLoader 返回按 first 批量读取相似诗作的 dataloader
+func (r *graphRequest) similarLoader(first int) *batch.Loader[int, []Poem] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.similar[first]
+	if !ok {
+		l = batch.New(func(ctx context.Context, ids []int) (map[int][]Poem, error) {
+			return similarPoems(ctx, ids, first)
+		}, graphBatchWait, loaderBatchSize)
+		r.similar[first] = l
+	}
+	return l
+}
+
+// graphError 是返回给客户端的 GraphQL 错误，extensions 中带有与 REST 接口相同的错误码和请求 ID。
+type graphError struct {
+	code      string
+	message   string
+	details   any
+	requestID string
+}
+
+func (e *graphError) Error() string { return e.message }
+
+func (e *graphError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.code, "request_id": e.requestID}
+	if e.details != nil {
+		ext["details"] = e.details
+	}
+	return ext
+}
+
+// fail 转换解析器中的错误：领域错误保留错误码，其他错误只写日志，返回 internal_error。
+func (r *graphRequest) fail(err error) error {
+	var e *apierror.Error
+	if !errors.As(err, &e) {
+		e = &apierror.Error{Code: apierror.CodeInternal, Err: err}
+	}
+	if e.Err != nil {
+		r.log.Error("GraphQL resolver failed", "code", e.Code, "error", e.Err)
+	}
+	return &graphError{code: e.Code, message: apierror.Message(e.Code, r.lang), details: e.Details, requestID: r.requestID}
+}
+
+// count 检查列表参数不超过 maxGraphPageSize
+func (r *graphRequest) count(name string, n int32) (int, error) {
+	if n < 1 || n > maxGraphPageSize {
+		return 0, r.fail(apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": name, "min": 1, "max": maxGraphPageSize}))
+	}
+	return int(n), nil
+}
+
+// estimate 在根字段开始读取数据前估算代价并计入本次请求：每个选中的字段按其所在列表的条数计数，
+// 嵌套的列表相乘。root 为根字段直接返回的条数，items 为分页结果中 items 的条数。
+// 超过 maxGraphComplexity 时拒绝执行。
+func (r *graphRequest) estimate(ctx context.Context, root, items int) error {
+	cost := root
+	for _, path := range graphql.SelectedFieldNames(ctx) {
+		n := root
+		segments := strings.Split(path, ".")
+		for i, name := range segments {
+			if name == "items" {
+				n *= items
+				continue
+			}
+			f, ok := graphListFields[name]
+			if !ok {
+				continue
+			}
+			size := f.def
+			if f.arg != "" {
+				var args struct{ First *int32 }
+				if ok, _ := graphql.DecodeSelectedFieldArgs(ctx, strings.Join(segments[:i+1], "."), &args); ok && args.First != nil {
+					size = int(*args.First)
+				}
+			}
+			n *= max(size, 1)
+		}
+		cost += n
+	}
+	if total := r.estimated.Add(int64(cost)); total > maxGraphComplexity {
+		return r.fail(apierror.Validation(apierror.CodeQueryTooComplex, gin.H{"estimated": total, "max": maxGraphComplexity}))
+	}
+	return nil
+}
+
+// charge 记录实际返回的对象数。别名可以让同一字段在估算中只计一次，实际返回的对象仍受 maxGraphComplexity 限制。
+func (r *graphRequest) charge(n int) error {
+	if total := r.objects.Add(int64(n)); total > maxGraphComplexity {
+		return r.fail(apierror.Validation(apierror.CodeQueryTooComplex, gin.H{"objects": total, "max": maxGraphComplexity}))
+	}
+	return nil
+}
+
+type graphQuery struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphqlHandler 执行 GraphQL 查询。查询错误按 GraphQL 规范放在响应的 errors 中，状态码为 200；
+// 请求体不是合法的 JSON 或缺少 query 时按 REST 接口的格式返回 400。
+func graphqlHandler(schema *graphql.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var q graphQuery
+		if err := json.NewDecoder(c.Request.Body).Decode(&q); err != nil {
+			apierror.Write(c, bodyError(err))
+			return
+		}
+		if strings.TrimSpace(q.Query) == "" {
+			apierror.Write(c, apierror.Validation(apierror.CodeMissingParam, gin.H{"param": "query"}))
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), graphRequestKey{}, newGraphRequest(c))
+		resp := schema.Exec(ctx, q.Query, q.OperationName, q.Variables)
+		if len(resp.Errors) > 0 {
+			logger(c).Info("GraphQL query returned errors", "errors", len(resp.Errors), "first", resp.Errors[0].Message)
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// graphiQLPage 是开发用的 GraphiQL 页面，从 CDN 加载脚本
+const graphiQLPage = `<!DOCTYPE html>
+<html lang="zh">
+<head>
+  <meta charset="utf-8">
+  <title>GraphiQL - 唐诗数据库</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+  <div id="graphiql"></div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: "%s" });
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(
+      React.createElement(GraphiQL, { fetcher, defaultQuery: "{ stats { poetCount poemCount wordCount } }" })
+    );
+  </script>
+</body>
+</html>
+`
+
+// graphRoutes 注册 /api/graphql。POETRY_GRAPHIQL=1 时 GET /api/graphql 返回 GraphiQL 页面，只用于开发。
+func graphRoutes(r *gin.RouterGroup) {
+	schema, err := newGraphSchema()
+	if err != nil {
+		fatal("Invalid GraphQL schema", "error", err)
+	}
+	r.POST("/graphql", graphqlHandler(schema))
+
+	if os.Getenv("POETRY_GRAPHIQL") == "1" {
+		page := fmt.Sprintf(graphiQLPage, apiPrefix+"/graphql")
+		r.GET("/graphql", func(c *gin.Context) {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+		})
+	}
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"poetry/apierror"
+)
+
+// graphRoot 解析 GraphQL 的根字段，嵌套字段通过 dataloader 批量读取，避免 N+1 查询。
+type graphRoot struct{}
+
+type pageArgs struct {
+	Page     int32
+	PageSize int32
+}
+
+// paging 检查分页参数并估算查询代价，返回页码和每页条数
+func (r *graphRequest) paging(ctx context.Context, args pageArgs) (page, size int, err error) {
+	if size, err = r.count("pageSize", args.PageSize); err != nil {
+		return 0, 0, err
+	}
+	return max(int(args.Page), 1), size, r.estimate(ctx, 1, size)
+}
+
+func (graphRoot) Author(ctx context.Context, args struct{ ID int32 }) (*authorResolver, error) {
+	r := graphReq(ctx)
+	if err := r.estimate(ctx, 1, 0); err != nil {
+		return nil, err
+	}
+	return loadAuthorResolver(ctx, int(args.ID))
+}
+
+func (graphRoot) Authors(ctx context.Context, args pageArgs) (*authorPage, error) {
+	r := graphReq(ctx)
+	page, size, err := r.paging(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	authors, total, err := listAuthors(ctx, page, size)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return newAuthorPage(r, page, size, total, authors)
+}
+
+func (graphRoot) SearchAuthors(ctx context.Context, args struct {
+	Name string
+	pageArgs
+}) (*authorPage, error) {
+	r := graphReq(ctx)
+	page, size, err := r.paging(ctx, args.pageArgs)
+	if err != nil {
+		return nil, err
+	}
+	authors, total, err := findAuthors(ctx, args.Name, page, size)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return newAuthorPage(r, page, size, total, authors)
+}
+
+func (graphRoot) Poem(ctx context.Context, args struct{ ID int32 }) (*poemResolver, error) {
+	r := graphReq(ctx)
+	if err := r.estimate(ctx, 1, 0); err != nil {
+		return nil, err
+	}
+	p, err := r.poems.Load(ctx, int(args.ID))
+	if err != nil {
+		return nil, r.fail(apierror.Storage(err))
+	}
+	if p.PoemID == 0 {
+		return nil, nil
+	}
+	return &poemResolver{p}, nil
+}
+
+func (graphRoot) Poems(ctx context.Context, args struct {
+	pageArgs
+	Tag *string
+}) (*poemPage, error) {
+	r := graphReq(ctx)
+	page, size, err := r.paging(ctx, args.pageArgs)
+	if err != nil {
+		return nil, err
+	}
+	offset := (page - 1) * size
+
+	if args.Tag != nil {
+		tags, err := r.tagIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids := tags.poems[*args.Tag]
+		poems, err := r.loadPoems(ctx, ids[min(offset, len(ids)):min(offset+size, len(ids))])
+		if err != nil {
+			return nil, err
+		}
+		return newPoemPage(r, page, size, len(ids), poems)
+	}
+
+	poems, total, err := listPoems(ctx, page, size)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return newPoemPage(r, page, size, total, poems)
+}
+
+func (graphRoot) SearchPoems(ctx context.Context, args struct {
+	Text string
+	pageArgs
+}) (*poemPage, error) {
+	r := graphReq(ctx)
+	page, size, err := r.paging(ctx, args.pageArgs)
+	if err != nil {
+		return nil, err
+	}
+	poems, total, err := findPoems(ctx, args.Text, page, size)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return newPoemPage(r, page, size, total, poems)
+}
+
+func (graphRoot) Tag(ctx context.Context, args struct{ Name string }) (*tagResolver, error) {
+	r := graphReq(ctx)
+	if err := r.estimate(ctx, 1, 0); err != nil {
+		return nil, err
+	}
+	tags, err := r.tagIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tags.poems[args.Name]; !ok {
+		return nil, nil
+	}
+	return &tagResolver{args.Name, tags}, nil
+}
+
+func (graphRoot) Tags(ctx context.Context, args struct{ First int32 }) ([]*tagResolver, error) {
+	r := graphReq(ctx)
+	first, err := r.count("first", args.First)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.estimate(ctx, first, 0); err != nil {
+		return nil, err
+	}
+	tags, err := r.tagIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := tags.names[:min(first, len(tags.names))]
+	if err := r.charge(len(names)); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*tagResolver, len(names))
+	for i, name := range names {
+		resolvers[i] = &tagResolver{name, tags}
+	}
+	return resolvers, nil
+}
+
+func (graphRoot) Stats(ctx context.Context) (*statsResolver, error) {
+	r := graphReq(ctx)
+	if err := r.estimate(ctx, 1, 0); err != nil {
+		return nil, err
+	}
+	rows, err := query(ctx, "stats_view", "SELECT name, value FROM stats_view")
+	if err != nil {
+		return nil, r.fail(apierror.Storage(fmt.Errorf("query stats_view: %w", err)))
+	}
+	defer rows.Close()
+
+	var s statsResolver
+	for rows.Next() {
+		var name string
+		var value int32
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, r.fail(apierror.Storage(fmt.Errorf("scan stats_view: %w", err)))
+		}
+		switch name {
+		case "poets":
+			s.poets = value
+		case "poems":
+			s.poems = value
+		case "words":
+			s.words = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, r.fail(apierror.Storage(fmt.Errorf("read stats_view: %w", err)))
+	}
+	return &s, nil
+}
+
+type authorPage struct {
+	page, size, total int
+	items             []*authorResolver
+}
+
+func newAuthorPage(r *graphRequest, page, size, total int, authors []Author) (*authorPage, error) {
+	if err := r.charge(len(authors)); err != nil {
+		return nil, err
+	}
+	p := &authorPage{page: page, size: size, total: total, items: make([]*authorResolver, len(authors))}
+	for i, a := range authors {
+		p.items[i] = &authorResolver{a}
+	}
+	return p, nil
+}
+
+func (p *authorPage) Page() int32              { return int32(p.page) }
+func (p *authorPage) PageSize() int32          { return int32(p.size) }
+func (p *authorPage) Total() int32             { return int32(p.total) }
+func (p *authorPage) Items() []*authorResolver { return p.items }
+
+type poemPage struct {
+	page, size, total int
+	items             []*poemResolver
+}
+
+func newPoemPage(r *graphRequest, page, size, total int, poems []Poem) (*poemPage, error) {
+	if err := r.charge(len(poems)); err != nil {
+		return nil, err
+	}
+	return &poemPage{page: page, size: size, total: total, items: poemResolvers(poems)}, nil
+}
+
+func (p *poemPage) Page() int32            { return int32(p.page) }
+func (p *poemPage) PageSize() int32        { return int32(p.size) }
+func (p *poemPage) Total() int32           { return int32(p.total) }
+func (p *poemPage) Items() []*poemResolver { return p.items }
+
+type authorResolver struct {
+	a Author
+}
+
+func loadAuthorResolver(ctx context.Context, id int) (*authorResolver, error) {
+	r := graphReq(ctx)
+	a, err := r.authors.Load(ctx, id)
+	if err != nil {
+		return nil, r.fail(apierror.Storage(err))
+	}
+	if a.AuthorID == 0 {
+		return nil, nil
+	}
+	return &authorResolver{a}, nil
+}
+
+func (a *authorResolver) ID() int32           { return int32(a.a.AuthorID) }
+func (a *authorResolver) Name() string        { return a.a.Name }
+func (a *authorResolver) Description() string { return a.a.Description }
+func (a *authorResolver) ImgUrl() string      { return a.a.ImgUrl }
+
+// Dynasty 与 data_table 视图一致，全部作者都是唐代
+func (a *authorResolver) Dynasty() string { return corpusDynasty }
+
+func (a *authorResolver) PoemCount(ctx context.Context) (int32, error) {
+	t, err := a.totals(ctx)
+	return int32(t.Poems), err
+}
+
+func (a *authorResolver) WordCount(ctx context.Context) (int32, error) {
+	t, err := a.totals(ctx)
+	return int32(t.Words), err
+}
+
+func (a *authorResolver) totals(ctx context.Context) (poemTotals, error) {
+	r := graphReq(ctx)
+	t, err := r.totals.Load(ctx, a.a.AuthorID)
+	if err != nil {
+		return t, r.fail(apierror.Storage(err))
+	}
+	return t, nil
+}
+
+func (a *authorResolver) Poems(ctx context.Context, args struct{ First, Offset int32 }) ([]*poemResolver, error) {
+	r := graphReq(ctx)
+	first, err := r.count("first", args.First)
+	if err != nil {
+		return nil, err
+	}
+	poems, err := r.authorPoemsLoader(first, max(int(args.Offset), 0)).Load(ctx, a.a.AuthorID)
+	if err != nil {
+		return nil, r.fail(apierror.Storage(err))
+	}
+	if err := r.charge(len(poems)); err != nil {
+		return nil, err
+	}
+	return poemResolvers(poems), nil
+}
+
+type poemResolver struct {
+	p Poem
+}
+
+func poemResolvers(poems []Poem) []*poemResolver {
+	resolvers := make([]*poemResolver, len(poems))
+	for i, p := range poems {
+		resolvers[i] = &poemResolver{p}
+	}
+	return resolvers
+}
+
+func (p *poemResolver) ID() int32       { return int32(p.p.PoemID) }
+func (p *poemResolver) Title() string   { return p.p.Title }
+func (p *poemResolver) Content() string { return p.p.Content }
+
+func (p *poemResolver) Paragraphs() []string {
+	return strings.Split(p.p.Content, "\n")
+}
+
+func (p *poemResolver) Author(ctx context.Context) (*authorResolver, error) {
+	return loadAuthorResolver(ctx, p.p.AuthorID)
+}
+
+func (p *poemResolver) Tags(ctx context.Context) ([]*tagResolver, error) {
+	r := graphReq(ctx)
+	tags, err := r.tagIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := tags.byPoem[p.p.PoemID]
+	resolvers := make([]*tagResolver, len(names))
+	for i, name := range names {
+		resolvers[i] = &tagResolver{name, tags}
+	}
+	return resolvers, r.charge(len(names))
+}
+
+func (p *poemResolver) Similar(ctx context.Context, args struct{ First int32 }) ([]*poemResolver, error) {
+	r := graphReq(ctx)
+	first, err := r.count("first", args.First)
+	if err != nil {
+		return nil, err
+	}
+	poems, err := r.similarLoader(first).Load(ctx, p.p.PoemID)
+	if err != nil {
+		return nil, r.fail(apierror.Storage(err))
+	}
+	if err := r.charge(len(poems)); err != nil {
+		return nil, err
+	}
+	return poemResolvers(poems), nil
+}
+
+func (p *poemResolver) Source() *sourceResolver {
+	if p.p.Source == nil {
+		return nil
+	}
+	return &sourceResolver{*p.p.Source}
+}
+
+type sourceResolver struct {
+	s PoemSource
+}
+
+func (s *sourceResolver) ID() *string    { return optional(s.s.ID) }
+func (s *sourceResolver) File() string   { return s.s.File }
+func (s *sourceResolver) Index() int32   { return int32(s.s.Index) }
+func (s *sourceResolver) Notes() *string { return optional(s.s.Notes) }
+
+// optional 把空字符串转换为 GraphQL 的 null
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type tagResolver struct {
+	name string
+	tags *tagIndex
+}
+
+func (t *tagResolver) Name() string     { return t.name }
+func (t *tagResolver) PoemCount() int32 { return int32(len(t.tags.poems[t.name])) }
+
+func (t *tagResolver) Poems(ctx context.Context, args struct{ First, Offset int32 }) ([]*poemResolver, error) {
+	r := graphReq(ctx)
+	first, err := r.count("first", args.First)
+	if err != nil {
+		return nil, err
+	}
+	ids := t.tags.poems[t.name]
+	offset := min(max(int(args.Offset), 0), len(ids))
+	poems, err := r.loadPoems(ctx, ids[offset:min(offset+first, len(ids))])
+	if err != nil {
+		return nil, err
+	}
+	if err := r.charge(len(poems)); err != nil {
+		return nil, err
+	}
+	return poemResolvers(poems), nil
+}
+
+type statsResolver struct {
+	poets, poems, words int32
+}
+
+func (s *statsResolver) PoetCount() int32 { return s.poets }
+func (s *statsResolver) PoemCount() int32 { return s.poems }
+func (s *statsResolver) WordCount() int32 { return s.words }
+
+func (s *statsResolver) TopAuthors(ctx context.Context, args struct{ First int32 }) ([]*authorResolver, error) {
+	r := graphReq(ctx)
+	first, err := r.count("first", args.First)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := queryAuthors(ctx, "graph_top_authors", `SELECT a.author_id, a.name, a.description, COALESCE(a.imgUrl, '')
+		FROM echart_two e JOIN Authors a ON a.author_id = e.author_id
+		ORDER BY e.poem_count DESC, a.author_id LIMIT ?`, first)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	if err := r.charge(len(authors)); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*authorResolver, len(authors))
+	for i, a := range authors {
+		resolvers[i] = &authorResolver{a}
+	}
+	return resolvers, nil
+}
+
+// loadPoems 通过 dataloader 按顺序读取诗作，跳过已删除的诗作
+func (r *graphRequest) loadPoems(ctx context.Context, ids []int) ([]Poem, error) {
+	poems := make([]Poem, 0, len(ids))
+	for _, id := range ids {
+		p, err := r.poems.Load(ctx, id)
+		if err != nil {
+			return nil, r.fail(apierror.Storage(err))
+		}
+		if p.PoemID != 0 {
+			poems = append(poems, p)
+		}
+	}
+	return poems, nil
+}
+
+// similarPoems 批量读取相似的诗作：先列出其他作者的同题之作，再列出同一作者行数和字数都相同的诗作（通常体裁相同）。
+func similarPoems(ctx context.Context, ids []int, first int) (map[int][]Poem, error) {
+	similar := make(map[int][]Poem, len(ids))
+	err := inBatches(ids, func(placeholders string, args []any) error {
+		rows, err := query(ctx, "similar_poems_batch", `SELECT src, `+poemColumns+` FROM (
+				SELECT p.poem_id AS src, q.*,
+					ROW_NUMBER() OVER (PARTITION BY p.poem_id ORDER BY q.title = p.title AND q.author_id IS NOT p.author_id DESC, q.poem_id) AS n
+				FROM Poems p JOIN Poems q ON q.poem_id <> p.poem_id AND q.deleted_at IS NULL AND (
+					q.title = p.title AND q.author_id IS NOT p.author_id
+					OR q.author_id = p.author_id AND LENGTH(q.content) = LENGTH(p.content)
+						AND LENGTH(q.content) - LENGTH(REPLACE(q.content, char(10), '')) = LENGTH(p.content) - LENGTH(REPLACE(p.content, char(10), '')))
+				WHERE p.poem_id IN (`+placeholders+`)
+			) WHERE n <= ? ORDER BY src, n`, append(args, first)...)
+		if err != nil {
+			return fmt.Errorf("query similar poems: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var src int
+			p, err := scanPoem(rows, &src)
+			if err != nil {
+				return fmt.Errorf("scan similar poem: %w", err)
+			}
+			similar[src] = append(similar[src], p)
+		}
+		return rows.Err()
+	})
+	return similar, err
+}
+
+// tagIndex 是《唐诗三百首》（Tang300 表）的标签与诗作的对应关系。Tang300 没有诗作 ID，按作者名和诗题匹配。
+type tagIndex struct {
+	byPoem map[int][]string // 诗作 ID 到标签
+	poems  map[string][]int // 标签到诗作 ID，按 ID 排列
+	names  []string         // 全部标签，按诗作数从多到少排列
+}
+
+// tagIndex 在第一次使用时读取标签，一次请求只读取一次。
+func (r *graphRequest) tagIndex(ctx context.Context) (*tagIndex, error) {
+	r.tagsOnce.Do(func() {
+		r.tags, r.tagsErr = loadTagIndex(ctx)
+	})
+	if r.tagsErr != nil {
+		return nil, r.fail(r.tagsErr)
+	}
+	return r.tags, nil
+}
+
+func loadTagIndex(ctx context.Context) (*tagIndex, error) {
+	rows, err := query(ctx, "tang300_tags", `SELECT p.poem_id, t.tags FROM Tang300 t
+		JOIN Authors a ON a.name = t.author AND a.deleted_at IS NULL
+		JOIN Poems p ON p.author_id = a.author_id AND p.title = t.title AND p.deleted_at IS NULL
+		ORDER BY p.poem_id`)
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query tags: %w", err))
+	}
+	defer rows.Close()
+
+	idx := &tagIndex{byPoem: map[int][]string{}, poems: map[string][]int{}}
+	for rows.Next() {
+		var id int
+		var tags string
+		if err := rows.Scan(&id, &tags); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan tags: %w", err))
+		}
+		// app.py 以 ", " 连接标签
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				idx.byPoem[id] = append(idx.byPoem[id], tag)
+				idx.poems[tag] = append(idx.poems[tag], id)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read tags: %w", err))
+	}
+
+	for name := range idx.poems {
+		idx.names = append(idx.names, name)
+	}
+	sort.Slice(idx.names, func(i, j int) bool {
+		a, b := idx.names[i], idx.names[j]
+		if len(idx.poems[a]) != len(idx.poems[b]) {
+			return len(idx.poems[a]) > len(idx.poems[b])
+		}
+		return a < b
+	})
+	return idx, nil
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"poetry/apierror"
+	"poetry/poetrypb"
+	"poetry/ratelimit"
+)
+
+// defaultGRPCAddr 是 gRPC 服务的默认地址，与 HTTP 服务分开监听
+const defaultGRPCAddr = ":9090"
+
+// rpcRoles 是各 RPC 要求的最低角色，未列出的允许匿名调用，与 REST 接口一致。
+var rpcRoles = map[string]string{
+	poetrypb.PoetryService_CreateAuthor_FullMethodName: roleEditor,
+	poetrypb.PoetryService_UpdateAuthor_FullMethodName: roleEditor,
+	poetrypb.PoetryService_DeleteAuthor_FullMethodName: roleEditor,
+	poetrypb.PoetryService_CreatePoem_FullMethodName:   roleEditor,
+	poetrypb.PoetryService_UpdatePoem_FullMethodName:   roleEditor,
+	poetrypb.PoetryService_DeletePoem_FullMethodName:   roleEditor,
+}
+
+// rpcCosts 是各 RPC 每次调用扣除的令牌数，与 routeCosts 中对应的路由相同；流式导出一次读取全部数据，代价最高。
+var rpcCosts = map[string]float64{
+	poetrypb.PoetryService_SearchAuthors_FullMethodName:   5,
+	poetrypb.PoetryService_SearchPoems_FullMethodName:     5,
+	poetrypb.PoetryService_ListAuthorPoems_FullMethodName: 2,
+	poetrypb.PoetryService_GetStats_FullMethodName:        2,
+	poetrypb.PoetryService_StreamAuthors_FullMethodName:   10,
+	poetrypb.PoetryService_StreamPoems_FullMethodName:     10,
+}
+
+// rpcServer 是与 HTTP 服务一同启动和关闭的 gRPC 服务。
+type rpcServer struct {
+	addr   string
+	srv    *grpc.Server
+	health *health.Server
+}
+
+// newRPCServer 按 POETRY_GRPC_ADDR（默认 :9090）创建 gRPC 服务，设置为 off 时返回 nil。
+// 除 PoetryService 外还注册标准的健康检查和反射服务，便于 grpcurl 等工具调用。
+func newRPCServer() *rpcServer {
+	addr := os.Getenv("POETRY_GRPC_ADDR")
+	switch addr {
+	case "off":
+		return nil
+	case "":
+		addr = defaultGRPCAddr
+	}
+
+	calls := &rpcInterceptor{limiter: rateLimiter}
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxBodyBytes),
+		grpc.ChainUnaryInterceptor(calls.unary),
+		grpc.ChainStreamInterceptor(calls.stream),
+	)
+	poetrypb.RegisterPoetryServiceServer(srv, poetryService{})
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+	return &rpcServer{addr: addr, srv: srv, health: hs}
+}
+
+// start 开始监听，Serve 返回的错误写入 errc。
+func (s *rpcServer) start(errc chan<- error) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.addr, err)
+	}
+	go func() {
+		slog.Info("gRPC server listening", "addr", s.addr)
+		errc <- s.srv.Serve(lis)
+	}()
+	return nil
+}
+
+// shutdown 把健康检查置为 NOT_SERVING 并等待进行中的调用完成，ctx 到期后断开剩余的连接（如未完成的流式导出）。
+func (s *rpcServer) shutdown(ctx context.Context) {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.srv.Stop()
+		<-done
+	}
+}
+
+// rpcCall 是一次 RPC 调用的状态，相当于 HTTP 请求中保存在 gin.Context 里的值。
+type rpcCall struct {
+	method    string
+	requestID string
+	traceID   string
+	lang      string
+	clientIP  string
+	identity  *Identity
+	log       *slog.Logger
+}
+
+type rpcCallKey struct{}
+
+// rpcCallFrom 返回 ctx 中的调用状态。
+func rpcCallFrom(ctx context.Context) *rpcCall {
+	return ctx.Value(rpcCallKey{}).(*rpcCall)
+}
+
+// audit 返回本次调用的审计来源，route 记为完整的方法名。
+func (c *rpcCall) audit() requestAudit {
+	a := requestAudit{Actor: "anonymous", Route: c.method, ClientIP: c.clientIP, RequestID: c.requestID}
+	if c.identity != nil {
+		a.Actor = c.identity.Name
+	}
+	return a
+}
+
+// rpcInterceptor 为每次调用依次完成追踪、请求 ID、认证、授权和限流，结束后记录访问日志和指标，
+// 并把领域错误转换为 gRPC 状态。
+type rpcInterceptor struct {
+	limiter *ratelimit.Limiter
+}
+
+func (i *rpcInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var resp any
+	err := i.intercept(ctx, info.FullMethod, func(ctx context.Context) error {
+		var err error
+		resp, err = handler(ctx, req)
+		return err
+	}, nil)
+	return resp, err
+}
+
+func (i *rpcInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s := &countingStream{ServerStream: ss}
+	return i.intercept(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		s.ctx = ctx
+		return handler(srv, s)
+	}, &s.sent)
+}
+
+// countingStream 替换流的上下文，并记录发送的消息数写入访问日志。
+type countingStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent int
+}
+
+func (s *countingStream) Context() context.Context { return s.ctx }
+
+func (s *countingStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.sent++
+	return nil
+}
+
+func (i *rpcInterceptor) intercept(ctx context.Context, method string, call func(context.Context) error, sent *int) error {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", method)),
+	)
+	defer span.End()
+
+	c := &rpcCall{method: method, requestID: mdValue(md, "x-request-id"), lang: apierror.Lang(mdValue(md, "accept-language"))}
+	if !validRequestID.MatchString(c.requestID) {
+		c.requestID = apierror.NewRequestID()
+	}
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		c.traceID = sc.TraceID().String()
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		c.clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(c.clientIP); err == nil {
+			c.clientIP = host
+		}
+	}
+	c.log = slog.Default().With("request_id", c.requestID)
+	if c.traceID != "" {
+		c.log = c.log.With("trace_id", c.traceID)
+	}
+	span.SetAttributes(attribute.String("request_id", c.requestID))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", c.requestID))
+	ctx = context.WithValue(ctx, rpcCallKey{}, c)
+
+	err := i.admit(ctx, c, md)
+	if err == nil {
+		err = call(ctx)
+	}
+	err = c.status(err)
+
+	code := status.Code(err)
+	rpcRequests.WithLabelValues(method, code.String()).Inc()
+	rpcDuration.WithLabelValues(method, code.String()).Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if serverError(code) {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case serverError(code):
+		level = slog.LevelError
+	case code != codes.OK:
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("client_ip", c.clientIP),
+	}
+	if c.identity != nil {
+		attrs = append(attrs, slog.String("actor", c.identity.Name))
+	}
+	if sent != nil {
+		attrs = append(attrs, slog.Int("messages", *sent))
+	}
+	c.log.LogAttrs(ctx, level, "rpc", attrs...)
+	return err
+}
+
+// admit 解析 metadata 中的凭据（x-api-key 或 authorization: Bearer <jwt>），检查角色并扣除令牌。
+// 与 HTTP 相同，认证之前先按客户端 IP 扣除令牌，认证通过后再按用户扣除。
+func (i *rpcInterceptor) admit(ctx context.Context, c *rpcCall, md metadata.MD) error {
+	cost, ok := rpcCosts[c.method]
+	if !ok {
+		cost = defaultRouteCost
+	}
+	key, authorization := mdValue(md, "x-api-key"), mdValue(md, "authorization")
+	clientCost := cost
+	if key != "" || strings.HasPrefix(authorization, "Bearer ") {
+		clientCost = authCost
+	}
+	if err := i.take("ip:"+c.clientIP, clientCost); err != nil {
+		return err
+	}
+
+	var err error
+	if key != "" {
+		c.identity, err = identityFromAPIKey(ctx, key)
+	} else if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		c.identity, err = identityFromJWT(ctx, token)
+	}
+	if err != nil {
+		return err
+	}
+
+	if role, ok := rpcRoles[c.method]; ok {
+		if c.identity == nil {
+			return apierror.Unauthorized(apierror.CodeUnauthenticated, nil)
+		}
+		if roleLevels[c.identity.Role] < roleLevels[role] {
+			return apierror.Forbidden(apierror.CodeForbidden, gin.H{"required_role": role, "role": c.identity.Role})
+		}
+	}
+
+	if c.identity == nil {
+		return nil
+	}
+	return i.take(fmt.Sprintf("user:%d", c.identity.UserID), cost)
+}
+
+// take 从 key 的桶中扣除令牌，未配置限流时直接放行。
+func (i *rpcInterceptor) take(key string, cost float64) error {
+	if i.limiter == nil {
+		return nil
+	}
+	if allowed, wait := i.limiter.Take(key, cost); !allowed {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		return apierror.RateLimited(apierror.CodeRateLimited, gin.H{"retry_after": retryAfter})
+	}
+	return nil
+}
+
+// rpcCodes 把领域错误的分类映射为 gRPC 状态码。
+var rpcCodes = map[apierror.Kind]codes.Code{
+	apierror.KindNotFound:     codes.NotFound,
+	apierror.KindValidation:   codes.InvalidArgument,
+	apierror.KindConflict:     codes.FailedPrecondition,
+	apierror.KindUnauthorized: codes.Unauthenticated,
+	apierror.KindForbidden:    codes.PermissionDenied,
+	apierror.KindTooLarge:     codes.ResourceExhausted,
+	apierror.KindRateLimited:  codes.ResourceExhausted,
+}
+
+// status 把处理函数返回的错误转换为 gRPC 状态。领域错误的错误码、details 和请求 ID 放在 ErrorInfo 中，
+// message 与 REST 接口相同；其他错误只写日志，返回 Internal。
+func (c *rpcCall) status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var e *apierror.Error
+	if !errors.As(err, &e) {
+		e = &apierror.Error{Kind: apierror.KindInternal, Code: apierror.CodeInternal, Err: err}
+	}
+	if e.Err != nil {
+		c.log.Error("RPC failed", "method", c.method, "code", e.Code, "error", e.Err)
+	}
+
+	code, ok := rpcCodes[e.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+	if e.Code == apierror.CodeAuthorExists || e.Code == apierror.CodeAuthorInTrash {
+		code = codes.AlreadyExists
+	}
+	info := &errdetails.ErrorInfo{Reason: e.Code, Domain: "poetry", Metadata: map[string]string{"request_id": c.requestID}}
+	if c.traceID != "" {
+		info.Metadata["trace_id"] = c.traceID
+	}
+	if e.Details != nil {
+		if b, err := json.Marshal(e.Details); err == nil {
+			info.Metadata["details"] = string(b)
+		}
+	}
+	st := status.New(code, apierror.Message(e.Code, c.lang))
+	if withInfo, err := st.WithDetails(info); err == nil {
+		st = withInfo
+	}
+	// 限流时同 HTTP 的 Retry-After，给出需要等待的时间
+	if d, ok := e.Details.(gin.H); ok && e.Kind == apierror.KindRateLimited {
+		if n, ok := d["retry_after"].(int); ok {
+			if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(n) * time.Second)}); err == nil {
+				st = withRetry
+			}
+		}
+	}
+	return st.Err()
+}
+
+// serverError 报告状态码是否表示服务端错误，对应 HTTP 的 5xx。
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// mdValue 返回 metadata 中 key 的第一个值。
+func mdValue(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// metadataCarrier 让 traceparent 可以从 gRPC metadata 中读取。
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier{}
+
+func (m metadataCarrier) Get(key string) string { return mdValue(metadata.MD(m), key) }
+func (m metadataCarrier) Set(key, value string) { metadata.MD(m).Set(key, value) }
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
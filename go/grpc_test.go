@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"poetry/poetrypb"
+)
+
+// TestSharedRateLimit 检查 HTTP 和 gRPC 共用同一客户端 IP 的令牌桶：一种协议用完令牌后，另一种协议同样被限流
+func TestSharedRateLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		http  int // 先发送的 HTTP 请求数，每次 1 个令牌
+		rpc   int // 先发送的 GetStats 调用数，每次 2 个令牌
+		check string
+	}{
+		{"http then grpc", 4, 0, "grpc"},
+		{"grpc then http", 0, 2, "http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			// 桶容量 4，几乎不补充
+			t.Setenv("POETRY_RATE_LIMIT", "0.001")
+			t.Setenv("POETRY_RATE_BURST", "4")
+			setupRateLimiter()
+
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+			rpc := newRPCServer()
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go rpc.srv.Serve(lis)
+			defer rpc.srv.Stop()
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			client := poetrypb.NewPoetryServiceClient(conn)
+			getStats := func() error {
+				_, err := client.GetStats(context.Background(), &poetrypb.GetStatsRequest{})
+				return err
+			}
+			getAuthor := func() int {
+				resp, err := http.Get(srv.URL + "/api/authors/1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+
+			for range tt.http {
+				if code := getAuthor(); code != http.StatusOK {
+					t.Fatalf("HTTP request within burst: status %d", code)
+				}
+			}
+			for range tt.rpc {
+				if err := getStats(); err != nil {
+					t.Fatalf("GetStats within burst: %v", err)
+				}
+			}
+			switch tt.check {
+			case "grpc":
+				if err := getStats(); status.Code(err) != codes.ResourceExhausted {
+					t.Errorf("GetStats after HTTP used the bucket: %v, want ResourceExhausted", err)
+				}
+			case "http":
+				if code := getAuthor(); code != http.StatusTooManyRequests {
+					t.Errorf("HTTP after gRPC used the bucket: status %d, want 429", code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"poetry/apierror"
+	"poetry/poetrypb"
+)
+
+// gRPC 列表接口的分页：默认与 REST 相同每页 6 条，可通过 page_size 调整
+const (
+	defaultRPCPageSize = 6
+	maxRPCPageSize     = 100
+)
+
+// streamBatchSize 是流式导出每次从数据库读取的条数。按 ID 分批读取，不会在整个导出期间占用一个查询。
+const streamBatchSize = 500
+
+// poetryService 实现 poetrypb.PoetryServiceServer，读写与 REST 接口共用同一套查询、校验和事务。
+type poetryService struct {
+	poetrypb.UnimplementedPoetryServiceServer
+}
+
+// rpcID 检查请求中的 ID，同 paramID。
+func rpcID(name string, id int64) (int, error) {
+	if id < 1 {
+		return 0, apierror.Validation(apierror.CodeInvalidID, gin.H{"param": name})
+	}
+	return int(id), nil
+}
+
+// rpcPage 返回页码和每页条数。页码小于 1 时按第一页处理，同 REST。
+func rpcPage(page, size int32) (int, int, error) {
+	if size == 0 {
+		size = defaultRPCPageSize
+	}
+	if size < 1 || size > maxRPCPageSize {
+		return 0, 0, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "page_size", "min": 1, "max": maxRPCPageSize})
+	}
+	return max(int(page), 1), int(size), nil
+}
+
+// rpcText 检查搜索文本，与 REST 查询参数的长度限制相同。
+func rpcText(name, text string, required bool) error {
+	if required && text == "" {
+		return apierror.Validation(apierror.CodeMissingParam, gin.H{"param": name})
+	}
+	if len(text) > maxQueryParamLen {
+		return apierror.Validation(apierror.CodeQueryTooLong, gin.H{"param": name, "max_bytes": maxQueryParamLen})
+	}
+	return nil
+}
+
+func authorInclusion(inc *poetrypb.AuthorInclude, def authorInclude) authorInclude {
+	if inc == nil {
+		return def
+	}
+	return authorInclude{Poems: inc.Poems, Total: inc.Total}
+}
+
+func authorPB(a Author) *poetrypb.Author {
+	pb := &poetrypb.Author{
+		AuthorId:    int64(a.AuthorID),
+		Name:        a.Name,
+		Description: a.Description,
+		ImgUrl:      a.ImgUrl,
+		TotalPoems:  int32(a.TotalPoems),
+	}
+	for _, p := range a.Poems {
+		pb.Poems = append(pb.Poems, poemPB(p))
+	}
+	return pb
+}
+
+func poemPB(p Poem) *poetrypb.Poem {
+	pb := &poetrypb.Poem{PoemId: int64(p.PoemID), Title: p.Title, AuthorId: int64(p.AuthorID), Content: p.Content}
+	if s := p.Source; s != nil {
+		pb.Source = &poetrypb.PoemSource{Id: s.ID, File: s.File, Index: int32(s.Index), Notes: s.Notes}
+	}
+	return pb
+}
+
+func authorsPB(authors []Author) []*poetrypb.Author {
+	pb := make([]*poetrypb.Author, len(authors))
+	for i, a := range authors {
+		pb[i] = authorPB(a)
+	}
+	return pb
+}
+
+func poemsPB(poems []Poem) []*poetrypb.Poem {
+	pb := make([]*poetrypb.Poem, len(poems))
+	for i, p := range poems {
+		pb[i] = poemPB(p)
+	}
+	return pb
+}
+
+func (poetryService) GetAuthor(ctx context.Context, req *poetrypb.GetAuthorRequest) (*poetrypb.Author, error) {
+	id, err := rpcID("author_id", req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	a, err := loadAuthor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return authorPB(a), nil
+}
+
+func (poetryService) ListAuthors(ctx context.Context, req *poetrypb.ListAuthorsRequest) (*poetrypb.ListAuthorsResponse, error) {
+	page, size, err := rpcPage(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	authors, total, err := listAuthors(ctx, page, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadAuthorPoems(ctx, authors, authorInclusion(req.Include, authorInclude{})); err != nil {
+		return nil, apierror.Storage(err)
+	}
+	return &poetrypb.ListAuthorsResponse{Page: int32(page), PageSize: int32(size), Total: int32(total), Authors: authorsPB(authors)}, nil
+}
+
+func (poetryService) SearchAuthors(ctx context.Context, req *poetrypb.SearchAuthorsRequest) (*poetrypb.ListAuthorsResponse, error) {
+	if err := rpcText("name", req.Name, true); err != nil {
+		return nil, err
+	}
+	page, size, err := rpcPage(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	authors, total, err := findAuthors(ctx, req.Name, page, size)
+	if err != nil {
+		return nil, err
+	}
+	// 同 REST，搜索结果默认附带诗作预览和总数
+	if err := loadAuthorPoems(ctx, authors, authorInclusion(req.Include, authorInclude{Poems: true, Total: true})); err != nil {
+		return nil, apierror.Storage(err)
+	}
+	return &poetrypb.ListAuthorsResponse{Page: int32(page), PageSize: int32(size), Total: int32(total), Authors: authorsPB(authors)}, nil
+}
+
+func (poetryService) ListAuthorPoems(ctx context.Context, req *poetrypb.ListAuthorPoemsRequest) (*poetrypb.ListPoemsResponse, error) {
+	id, err := rpcID("author_id", req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	page, size, err := rpcPage(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	poems, total, err := listAuthorPoems(ctx, id, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &poetrypb.ListPoemsResponse{Page: int32(page), PageSize: int32(size), Total: int32(total), Poems: poemsPB(poems)}, nil
+}
+
+func (poetryService) CreateAuthor(ctx context.Context, req *poetrypb.CreateAuthorRequest) (*poetrypb.Author, error) {
+	author := Author{Name: req.Name, Description: req.Description, ImgUrl: req.ImgUrl}
+	if err := validateAuthor(author); err != nil {
+		return nil, err
+	}
+	c := rpcCallFrom(ctx)
+	id, err := createAuthorRow(ctx, author, c.audit())
+	if err != nil {
+		return nil, err
+	}
+	c.log.Info("Author created", "author_id", id, "name", author.Name)
+	author.AuthorID = int(id)
+	return authorPB(author), nil
+}
+
+func (poetryService) UpdateAuthor(ctx context.Context, req *poetrypb.UpdateAuthorRequest) (*poetrypb.UpdateAuthorResponse, error) {
+	id, err := rpcID("author_id", req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	c := rpcCallFrom(ctx)
+	author, rev, err := updateAuthorRow(ctx, id, c.audit(), req.Comment, func(author *Author) error {
+		if req.Name != nil {
+			author.Name = *req.Name
+		}
+		if req.Description != nil {
+			author.Description = *req.Description
+		}
+		if req.ImgUrl != nil {
+			author.ImgUrl = *req.ImgUrl
+		}
+		return validateAuthor(*author)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.log.Info("Author updated", "author_id", id, "revision_id", rev)
+	return &poetrypb.UpdateAuthorResponse{Author: authorPB(author), RevisionId: rev}, nil
+}
+
+func (poetryService) DeleteAuthor(ctx context.Context, req *poetrypb.DeleteAuthorRequest) (*poetrypb.DeleteAuthorResponse, error) {
+	id, err := rpcID("author_id", req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	var mode string
+	to := 0
+	switch req.Poems {
+	case poetrypb.DeleteAuthorRequest_POEMS_MODE_RESTRICT:
+		mode = poemsRestrict
+	case poetrypb.DeleteAuthorRequest_POEMS_MODE_CASCADE:
+		mode = poemsCascade
+	case poetrypb.DeleteAuthorRequest_POEMS_MODE_REASSIGN:
+		mode = poemsReassign
+		if to, err = rpcID("reassign_to", req.ReassignTo); err != nil {
+			return nil, err
+		}
+		if to == id {
+			return nil, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "reassign_to"})
+		}
+	default:
+		return nil, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "poems"})
+	}
+
+	c := rpcCallFrom(ctx)
+	affected, err := removeAuthor(ctx, id, mode, to, c.audit())
+	if err != nil {
+		return nil, err
+	}
+	c.log.Info("Author deleted", "author_id", id, "poems", mode, "affected", affected)
+	resp := &poetrypb.DeleteAuthorResponse{}
+	switch mode {
+	case poemsCascade:
+		resp.PoemsDeleted = affected
+	case poemsReassign:
+		resp.PoemsReassigned = affected
+	}
+	return resp, nil
+}
+
+func (poetryService) GetPoem(ctx context.Context, req *poetrypb.GetPoemRequest) (*poetrypb.Poem, error) {
+	id, err := rpcID("poem_id", req.PoemId)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadPoem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return poemPB(p), nil
+}
+
+func (poetryService) ListPoems(ctx context.Context, req *poetrypb.ListPoemsRequest) (*poetrypb.ListPoemsResponse, error) {
+	page, size, err := rpcPage(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	poems, total, err := listPoems(ctx, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &poetrypb.ListPoemsResponse{Page: int32(page), PageSize: int32(size), Total: int32(total), Poems: poemsPB(poems)}, nil
+}
+
+func (poetryService) SearchPoems(ctx context.Context, req *poetrypb.SearchPoemsRequest) (*poetrypb.ListPoemsResponse, error) {
+	if err := rpcText("text", req.Text, true); err != nil {
+		return nil, err
+	}
+	page, size, err := rpcPage(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	poems, total, err := findPoems(ctx, req.Text, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &poetrypb.ListPoemsResponse{Page: int32(page), PageSize: int32(size), Total: int32(total), Poems: poemsPB(poems)}, nil
+}
+
+func (poetryService) CreatePoem(ctx context.Context, req *poetrypb.CreatePoemRequest) (*poetrypb.Poem, error) {
+	poem := Poem{Title: req.Title, AuthorID: int(req.AuthorId), Content: req.Content}
+	c := rpcCallFrom(ctx)
+	id, err := createPoemRow(ctx, poem, c.audit())
+	if err != nil {
+		return nil, err
+	}
+	c.log.Info("Poem created", "poem_id", id)
+	poem.PoemID = int(id)
+	return poemPB(poem), nil
+}
+
+func (poetryService) UpdatePoem(ctx context.Context, req *poetrypb.UpdatePoemRequest) (*poetrypb.UpdatePoemResponse, error) {
+	id, err := rpcID("poem_id", req.PoemId)
+	if err != nil {
+		return nil, err
+	}
+	c := rpcCallFrom(ctx)
+	poem, rev, err := updatePoemRow(ctx, id, c.audit(), req.Comment, func(poem *Poem) error {
+		if req.Title != nil {
+			poem.Title = *req.Title
+		}
+		if req.AuthorId != nil {
+			poem.AuthorID = int(*req.AuthorId)
+		}
+		if req.Content != nil {
+			poem.Content = *req.Content
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.log.Info("Poem updated", "poem_id", id, "revision_id", rev)
+	return &poetrypb.UpdatePoemResponse{Poem: poemPB(poem), RevisionId: rev}, nil
+}
+
+func (poetryService) DeletePoem(ctx context.Context, req *poetrypb.DeletePoemRequest) (*poetrypb.DeletePoemResponse, error) {
+	id, err := rpcID("poem_id", req.PoemId)
+	if err != nil {
+		return nil, err
+	}
+	c := rpcCallFrom(ctx)
+	if err := removePoem(ctx, id, c.audit()); err != nil {
+		return nil, err
+	}
+	c.log.Info("Poem deleted", "poem_id", id)
+	return &poetrypb.DeletePoemResponse{}, nil
+}
+
+func (poetryService) GetStats(ctx context.Context, _ *poetrypb.GetStatsRequest) (*poetrypb.Stats, error) {
+	stats, err := loadStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := stats["data"].(gin.H)
+	return &poetrypb.Stats{Poets: int32(data["poets"].(int)), Poems: int32(data["poems"].(int)), Words: int64(data["words"].(int))}, nil
+}
+
+func (poetryService) StreamAuthors(req *poetrypb.StreamAuthorsRequest, stream grpc.ServerStreamingServer[poetrypb.Author]) error {
+	if err := rpcText("name", req.Name, false); err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	after := req.AfterId
+	for {
+		authors, err := queryAuthors(ctx, "rpc_stream_authors",
+			"SELECT "+authorColumns+" FROM Authors WHERE deleted_at IS NULL AND author_id > ? AND name LIKE ? ORDER BY author_id LIMIT ?",
+			after, "%"+req.Name+"%", streamBatchSize)
+		if err != nil {
+			return err
+		}
+		if err := loadAuthorPoems(ctx, authors, authorInclude{Total: req.IncludeTotal}); err != nil {
+			return apierror.Storage(err)
+		}
+		for _, a := range authors {
+			if err := stream.Send(authorPB(a)); err != nil {
+				return err
+			}
+		}
+		if len(authors) < streamBatchSize {
+			return nil
+		}
+		after = int64(authors[len(authors)-1].AuthorID)
+	}
+}
+
+func (poetryService) StreamPoems(req *poetrypb.StreamPoemsRequest, stream grpc.ServerStreamingServer[poetrypb.Poem]) error {
+	if err := rpcText("text", req.Text, false); err != nil {
+		return err
+	}
+	if req.AuthorId < 0 {
+		return apierror.Validation(apierror.CodeInvalidID, gin.H{"param": "author_id"})
+	}
+	q := "SELECT " + poemColumns + " FROM Poems WHERE deleted_at IS NULL AND poem_id > ?"
+	var filters []any
+	if req.AuthorId != 0 {
+		q += " AND author_id = ?"
+		filters = append(filters, req.AuthorId)
+	}
+	if req.Text != "" {
+		q += " AND (title LIKE ? OR content LIKE ?)"
+		filters = append(filters, "%"+req.Text+"%", "%"+req.Text+"%")
+	}
+	q += " ORDER BY poem_id LIMIT ?"
+
+	ctx := stream.Context()
+	after := req.AfterId
+	for {
+		args := append(append([]any{after}, filters...), streamBatchSize)
+		poems, err := queryPoems(ctx, "rpc_stream_poems", q, args...)
+		if err != nil {
+			return err
+		}
+		for _, p := range poems {
+			if err := stream.Send(poemPB(p)); err != nil {
+				return err
+			}
+		}
+		if len(poems) < streamBatchSize {
+			return nil
+		}
+		after = int64(poems[len(poems)-1].PoemID)
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 导入记录的处理方式
+const (
+	importInsert    = "insert"
+	importUpdate    = "update"
+	importUnchanged = "unchanged"
+	importConflict  = "conflict"
+	importInvalid   = "invalid"
+)
+
+// 冲突原因
+const (
+	conflictAmbiguous = "ambiguous" // 作者和标题对应多首诗作，无法确定更新哪一首
+	conflictDuplicate = "duplicate" // 本次上传中有多条记录对应同一首诗作
+	conflictDeleted   = "deleted"   // id 对应的诗作在回收站中，需要先恢复
+)
+
+// ImportItem 是导入报告中的一条记录。
+type ImportItem struct {
+	File       string       `json:"file,omitempty"`
+	Index      int          `json:"index"` // 在文件中的序号，从 0 开始
+	ID         string       `json:"id,omitempty"`
+	Author     string       `json:"author"`
+	Title      string       `json:"title"`
+	Action     string       `json:"action"`
+	PoemID     int          `json:"poem_id,omitempty"`    // 对应的已有诗作，新建时为新诗作的 ID（仅实际导入后）
+	MatchedBy  string       `json:"matched_by,omitempty"` // id 或 author_title
+	Changed    []string     `json:"changed,omitempty"`    // 更新的字段，记录出处时含 source
+	Reason     string       `json:"reason,omitempty"`     // 冲突原因
+	Candidates []int        `json:"candidates,omitempty"` // 作者和标题对应的全部诗作
+	Fields     []FieldError `json:"fields,omitempty"`     // 未通过校验的字段
+
+	poem       Poem       // 导入后的诗作
+	previous   Poem       // 更新前的诗作
+	source     PoemSource // 新建时记录的出处
+	linkSource bool       // 更新时同时记录出处
+}
+
+// ImportReport 是导入的结果，items 不含没有变化的记录。
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Applied bool           `json:"applied"`
+	Summary map[string]int `json:"summary"`
+	Items   []ImportItem   `json:"items"`
+}
+
+func (r *ImportReport) rejected() bool {
+	return r.Summary[importInvalid] > 0 || r.Summary[importConflict] > 0
+}
+
+// readImport 读取请求体中的全部记录：multipart/form-data 时读取每个上传的文件，否则把请求体作为一个文件。
+// 每个文件可以是 JSON 数组（poet.tang.*.json）或 NDJSON。
+func readImport(c *gin.Context) ([]sourcePoem, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, bodyError(err)
+		}
+		return decodeSourcePoems("", body)
+	}
+
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "body"}).WithCause(err)
+	}
+	var records []sourcePoem
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, bodyError(err)
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return nil, bodyError(err)
+		}
+		recs, err := decodeSourcePoems(part.FileName(), body)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, recs...)
+	}
+	return records, nil
+}
+
+// importIndex 是匹配上传记录所需的现有数据，在导入事务中读取。
+type importIndex struct {
+	authors  map[string]int   // 作者名 -> author_id
+	bySource map[string]int   // 原始数据或导出的 id -> poem_id
+	deleted  map[string]int   // 回收站中的诗作的原始 id -> poem_id
+	byTitle  map[string][]int // author_id + 标题 -> poem_id
+}
+
+func titleKey(authorID int, title string) string {
+	return strconv.Itoa(authorID) + "\x00" + title
+}
+
+func loadImportIndex(tx *tracedTx) (*importIndex, error) {
+	idx := &importIndex{authors: map[string]int{}, bySource: map[string]int{}, deleted: map[string]int{}, byTitle: map[string][]int{}}
+
+	rows, err := tx.query("import_authors", "SELECT author_id, name FROM Authors WHERE deleted_at IS NULL")
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query authors: %w", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan author: %w", err))
+		}
+		idx.authors[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read authors: %w", err))
+	}
+
+	rows, err = tx.query("import_poems", "SELECT poem_id, author_id, title, COALESCE(source_id, ''), deleted_at IS NOT NULL FROM Poems ORDER BY poem_id")
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query poems: %w", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, authorID int
+		var title, source string
+		var deleted bool
+		if err := rows.Scan(&id, &authorID, &title, &source, &deleted); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan poem: %w", err))
+		}
+		if deleted {
+			if source != "" {
+				idx.deleted[source] = id
+			}
+			continue
+		}
+		// 没有原始 id 的诗作按导出时生成的 id 匹配
+		if source == "" {
+			source = localSourceID(entityPoem, id)
+		}
+		idx.bySource[source] = id
+		key := titleKey(authorID, title)
+		idx.byTitle[key] = append(idx.byTitle[key], id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read poems: %w", err))
+	}
+	return idx, nil
+}
+
+// planImport 校验并匹配每条记录，决定新建、更新还是跳过，不修改数据。
+// 有 id 且与原始数据或导出的 id 相同时按 id 匹配，否则按作者和标题匹配。
+func planImport(tx *tracedTx, records []sourcePoem) (*ImportReport, error) {
+	idx, err := loadImportIndex(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Items: []ImportItem{}, Summary: map[string]int{
+		importInsert: 0, importUpdate: 0, importUnchanged: 0, importConflict: 0, importInvalid: 0,
+	}}
+	claimed := map[int]bool{}     // 已有记录对应的诗作
+	inserted := map[string]bool{} // 将要新建的作者和标题，以及 id
+	for _, r := range records {
+		item := ImportItem{File: r.file, Index: r.index, ID: strings.ToLower(r.ID), Author: r.Author, Title: r.Title}
+		item.source = PoemSource{ID: item.ID, File: r.file, Index: r.index, Notes: string(r.Notes)}
+		item.poem = Poem{Title: r.Title, AuthorID: idx.authors[r.Author], Content: r.content()}
+		if err := planRecord(tx, idx, &item, claimed, inserted); err != nil {
+			return nil, err
+		}
+		report.Summary[item.Action]++
+		if item.Action != importUnchanged {
+			report.Items = append(report.Items, item)
+		}
+	}
+	return report, nil
+}
+
+// planRecord 填写 item 的处理方式。
+func planRecord(tx *tracedTx, idx *importIndex, item *ImportItem, claimed map[int]bool, inserted map[string]bool) error {
+	fe := poemFieldErrors(item.poem)
+	if strings.TrimSpace(item.Author) == "" {
+		fe = append(fe, FieldError{Field: "author", Rule: "required"})
+	} else if item.poem.AuthorID == 0 {
+		fe = append(fe, FieldError{Field: "author", Rule: apierror.CodeUnknownAuthor})
+	}
+	if item.ID != "" && !isUUID(item.ID) {
+		fe = append(fe, FieldError{Field: "id", Rule: "uuid"})
+	}
+	if len(fe) > 0 {
+		item.Action, item.Fields = importInvalid, fe
+		return nil
+	}
+
+	key := titleKey(item.poem.AuthorID, item.poem.Title)
+	if id, ok := idx.deleted[item.ID]; ok && item.ID != "" {
+		item.Action, item.Reason, item.PoemID = importConflict, conflictDeleted, id
+		return nil
+	}
+	if id, ok := idx.bySource[item.ID]; ok && item.ID != "" {
+		item.PoemID, item.MatchedBy = id, "id"
+	} else if ids := idx.byTitle[key]; len(ids) == 1 {
+		item.PoemID, item.MatchedBy = ids[0], "author_title"
+	} else if len(ids) > 1 {
+		item.Action, item.Reason, item.Candidates = importConflict, conflictAmbiguous, ids
+		return nil
+	}
+
+	if item.PoemID == 0 {
+		if inserted[key] || item.ID != "" && inserted[item.ID] {
+			item.Action, item.Reason = importConflict, conflictDuplicate
+			return nil
+		}
+		inserted[key] = true
+		if item.ID != "" {
+			inserted[item.ID] = true
+		}
+		item.Action = importInsert
+		return nil
+	}
+	if claimed[item.PoemID] {
+		item.Action, item.Reason = importConflict, conflictDuplicate
+		return nil
+	}
+	claimed[item.PoemID] = true
+
+	prev := Poem{PoemID: item.PoemID}
+	var hasSourceID bool
+	err := tx.queryRow("get_poem", "SELECT title, author_id, content, source_id IS NOT NULL FROM Poems WHERE poem_id = ?", item.PoemID).
+		Scan(&prev.Title, &prev.AuthorID, &prev.Content, &hasSourceID)
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("query poem %d: %w", item.PoemID, err))
+	}
+	item.previous = prev
+	before, after := poemFields(prev), poemFields(item.poem)
+	for _, f := range []string{"title", "author_id", "content"} {
+		if before[f] != after[f] {
+			item.Changed = append(item.Changed, f)
+		}
+	}
+	// 按作者和标题匹配到的诗作还没有原始 id 时记录上传的出处，以后可以按 id 匹配
+	if item.MatchedBy == "author_title" && item.ID != "" && !hasSourceID {
+		if inserted[item.ID] {
+			item.Action, item.Reason = importConflict, conflictDuplicate
+			return nil
+		}
+		inserted[item.ID] = true
+		item.linkSource = true
+		item.Changed = append(item.Changed, "source")
+	}
+	item.Action = importUpdate
+	if len(item.Changed) == 0 {
+		item.Action = importUnchanged
+	}
+	return nil
+}
+
+// isUUID 检查 s 是否为 8-4-4-4-12 格式的 UUID。
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applyImport 在 tx 中执行报告中的新建和更新，每首诗作都记录审计，更新同时记录修订。
+func applyImport(tx *tracedTx, report *ImportReport, a requestAudit, comment string) error {
+	for i := range report.Items {
+		item := &report.Items[i]
+		switch item.Action {
+		case importInsert:
+			id, err := insertPoem(tx, item.poem, a)
+			if err != nil {
+				return err
+			}
+			item.PoemID = int(id)
+			// 带有 id 或来自上传的文件时记录出处，以后可以按 id 匹配和查找
+			if item.source.ID != "" || item.source.File != "" {
+				if err := setPoemSource(tx, item.PoemID, item.source); err != nil {
+					return apierror.Storage(err)
+				}
+			}
+		case importUpdate:
+			if _, err := updatePoemTx(tx, item.PoemID, item.previous, item.poem, a, comment); err != nil {
+				return err
+			}
+			if item.linkSource {
+				if err := setPoemSource(tx, item.PoemID, item.source); err != nil {
+					return apierror.Storage(err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// 批量导入诗作：请求体为 poet.tang.*.json 格式的 JSON 数组或 NDJSON，也可以用 multipart/form-data 上传多个文件。
+// 默认只返回处理报告（?dry_run=false 时才写入）；有未通过校验或冲突的记录时不写入任何数据，
+// 全部记录在同一事务中写入。
+func importPoems(c *gin.Context) {
+	dryRun := true
+	if v := c.Query("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "dry_run"}))
+			return
+		}
+		dryRun = b
+	}
+	comment := c.Query("comment")
+	if comment == "" {
+		comment = "import"
+	}
+
+	extendReadDeadline(c)
+	records, err := readImport(c)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	if len(records) == 0 {
+		apierror.Write(c, apierror.Validation(apierror.CodeValidationFailed,
+			gin.H{"fields": []FieldError{{Field: "records", Rule: "required"}}}))
+		return
+	}
+
+	tx, err := begin(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("begin transaction: %w", err)))
+		return
+	}
+	defer tx.Rollback()
+
+	report, err := planImport(tx, records)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	report.DryRun = dryRun
+	if dryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	if report.rejected() {
+		e := apierror.Conflict(apierror.CodeImportRejected, report)
+		if report.Summary[importInvalid] > 0 {
+			e = apierror.Validation(apierror.CodeImportRejected, report)
+		}
+		apierror.Write(c, e)
+		return
+	}
+
+	if err := applyImport(tx, report, auditOf(c), comment); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("commit: %w", err)))
+		return
+	}
+	report.Applied = true
+
+	ids := make([]int, 0, len(report.Items))
+	for _, item := range report.Items {
+		ids = append(ids, item.PoemID)
+	}
+	invalidate(entityPoem, ids...)
+	logger(c).Info("Poems imported", "inserted", report.Summary[importInsert], "updated", report.Summary[importUpdate])
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"poetry/apierror"
+)
+
+// 读超时包含读取请求体的时间，导入接口延长读超时后，慢速上传仍能完成
+func TestImportSlowUpload(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewUnstartedServer(setupRouter())
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	body := `[{"author":"李白","title":"赠汪伦","paragraphs":["李白乘舟将欲行，忽闻岸上踏歌声。","桃花潭水深千尺，不及汪伦送我情。"]}]`
+	pr, pw := io.Pipe()
+	go func() {
+		const chunks = 10
+		for i := range chunks {
+			time.Sleep(30 * time.Millisecond)
+			pw.Write([]byte(body[i*len(body)/chunks : (i+1)*len(body)/chunks]))
+		}
+		pw.Close()
+	}()
+
+	var report ImportReport
+	doJSON(t, srv, http.MethodPost, "/api/import?dry_run=true", pr, http.StatusOK, &report)
+	if report.Summary[importInsert] != 1 {
+		t.Errorf("summary %v, want 1 insert", report.Summary)
+	}
+}
+
+// TestImport 检查导入的匹配方式、dry_run 和出错时整体回滚。
+// 记录中的 {source3} 替换为诗作 3 的原始 id，{local6} 替换为没有原始 id 的诗作 6 导出时的 id。
+func TestImport(t *testing.T) {
+	const (
+		update3 = `{"author":"杜甫","title":"春望","paragraphs":["国破山河在，城春草木深。","感时花溅泪，恨别鸟惊心。","烽火连三月，家书抵万金。"]}`
+		insert  = `{"author":"杜甫","title":"登高","paragraphs":["风急天高猿啸哀，渚清沙白鸟飞回。"]}`
+	)
+	type want struct {
+		action, matchedBy, reason string
+		poemID                    int
+	}
+	tests := []struct {
+		name    string
+		body    string
+		dryRun  bool
+		status  int
+		items   []want
+		changed bool // 数据库是否被修改
+	}{
+		{"dry run", `[` + insert + `,` + update3 + `]`, true, http.StatusOK,
+			[]want{{importInsert, "", "", 0}, {importUpdate, "author_title", "", 3}}, false},
+		{"apply", `[` + insert + `,` + update3 + `]`, false, http.StatusOK,
+			[]want{{importInsert, "", "", 7}, {importUpdate, "author_title", "", 3}}, true},
+		{"match by source id", `[{"id":"{source3}","author":"杜甫","title":"春望（节选）","paragraphs":["国破山河在，城春草木深。"]}]`, false, http.StatusOK,
+			[]want{{importUpdate, "id", "", 3}}, true},
+		{"match by exported id", `[{"id":"{local6}","author":"李白","title":"赠汪伦","paragraphs":["李白乘舟将欲行，忽闻岸上踏歌声。"]}]`, false, http.StatusOK,
+			[]want{{importUpdate, "id", "", 6}}, true},
+		{"unchanged", `[{"author":"王维","title":"鹿柴","paragraphs":["空山不见人，但闻人语响。","返景入深林，复照青苔上。"]}]`, false, http.StatusOK,
+			nil, false},
+		{"duplicate rejects all", `[` + insert + `,` + update3 + `,` + update3 + `]`, false, http.StatusConflict,
+			[]want{{importInsert, "", "", 0}, {importUpdate, "author_title", "", 3}, {importConflict, "author_title", conflictDuplicate, 3}}, false},
+		{"invalid rejects all", `[` + update3 + `,{"author":"无名氏","title":"无题","paragraphs":["一"]}]`, false, http.StatusBadRequest,
+			[]want{{importUpdate, "author_title", "", 3}, {importInvalid, "", "", 0}}, false},
+		// 写入第二条记录时出错，已写入的第一条随事务回滚
+		{"storage error rolls back", `[` + update3 + `,{"author":"杜甫","title":"爆","paragraphs":["一"]}]`, false, http.StatusInternalServerError,
+			nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			stmts := []string{
+				"INSERT INTO Poems (poem_id, title, author_id, content) VALUES (6, '赠汪伦', 1, '李白乘舟将欲行，忽闻岸上踏歌声。\n桃花潭水深千尺，不及汪伦送我情。')",
+				"CREATE TRIGGER boom BEFORE INSERT ON Poems WHEN NEW.title = '爆' BEGIN SELECT RAISE(ABORT, 'boom'); END",
+			}
+			for _, stmt := range stmts {
+				if _, err := db.Exec(stmt); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var source3 string
+			if err := db.QueryRow("SELECT source_id FROM Poems WHERE poem_id = 3").Scan(&source3); err != nil {
+				t.Fatal(err)
+			}
+			body := strings.NewReplacer("{source3}", source3, "{local6}", localSourceID(entityPoem, 6)).Replace(tt.body)
+			before := dumpPoems(t)
+
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+			var resp struct {
+				ImportReport
+				Code    string       `json:"code"`
+				Details ImportReport `json:"details"`
+			}
+			doJSON(t, srv, http.MethodPost, fmt.Sprintf("/api/import?dry_run=%v", tt.dryRun), strings.NewReader(body), tt.status, &resp)
+			report := resp.ImportReport
+			if resp.Code == apierror.CodeImportRejected {
+				report = resp.Details
+			}
+			if tt.items != nil {
+				if len(report.Items) != len(tt.items) {
+					t.Fatalf("items %+v, want %d", report.Items, len(tt.items))
+				}
+				for i, w := range tt.items {
+					it := report.Items[i]
+					if got := (want{it.Action, it.MatchedBy, it.Reason, it.PoemID}); got != w {
+						t.Errorf("item %d: %+v, want %+v", i, got, w)
+					}
+				}
+			}
+			if report.Applied != (tt.status == http.StatusOK && !tt.dryRun) {
+				t.Errorf("applied %v", report.Applied)
+			}
+
+			after := dumpPoems(t)
+			if changed := after != before; changed != tt.changed {
+				t.Errorf("database changed %v, want %v:\n%s", changed, tt.changed, after)
+			}
+			var revisions int
+			if err := db.QueryRow("SELECT COUNT(*) FROM revisions").Scan(&revisions); err != nil {
+				t.Fatal(err)
+			}
+			if tt.changed != (revisions > 0 || len(after) > len(before)) {
+				t.Errorf("%d revisions after import", revisions)
+			}
+		})
+	}
+}
+
+// dumpPoems 返回全部诗作的字段，用于比较导入前后的数据
+func dumpPoems(t *testing.T) string {
+	t.Helper()
+	rows, err := db.Query("SELECT poem_id, title, author_id, content, COALESCE(source_id, '') FROM Poems ORDER BY poem_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var sb strings.Builder
+	for rows.Next() {
+		var id, author int
+		var title, content, source string
+		if err := rows.Scan(&id, &title, &author, &content, &source); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&sb, "%d %s %d %q %s\n", id, title, author, content, source)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// findOrphanPoems 查找 author_id 为空或指向不存在作者的诗作。
+func findOrphanPoems(limit int) ([]Poem, int, error) {
+	const orphans = `FROM Poems p LEFT JOIN Authors a ON a.author_id = p.author_id WHERE a.author_id IS NULL`
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) " + orphans).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count orphan poems: %w", err)
+	}
+
+	rows, err := db.Query("SELECT p.poem_id, COALESCE(p.title, ''), COALESCE(p.author_id, 0) "+orphans+" ORDER BY p.poem_id LIMIT ?", limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query orphan poems: %w", err)
+	}
+	defer rows.Close()
+
+	poems := []Poem{}
+	for rows.Next() {
+		var poem Poem
+		if err := rows.Scan(&poem.PoemID, &poem.Title, &poem.AuthorID); err != nil {
+			return nil, 0, fmt.Errorf("scan orphan poem: %w", err)
+		}
+		poems = append(poems, poem)
+	}
+	return poems, total, rows.Err()
+}
+
+// runIntegrity 检查数据完整性：列出孤立的诗作，存在问题时以非零状态退出。
+func runIntegrity(args []string) error {
+	fs := flag.NewFlagSet("integrity", flag.ExitOnError)
+	limit := fs.Int("limit", 50, "最多列出的孤立诗作数量")
+	fs.Parse(args)
+
+	poems, total, err := findOrphanPoems(*limit)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		fmt.Println("OK: no orphan poems")
+		return nil
+	}
+
+	fmt.Printf("%d orphan poems (author_id not in Authors):\n", total)
+	for _, p := range poems {
+		fmt.Printf("  poem_id=%d author_id=%d title=%s\n", p.PoemID, p.AuthorID, p.Title)
+	}
+	if total > len(poems) {
+		fmt.Printf("  ... and %d more\n", total-len(poems))
+	}
+	return fmt.Errorf("integrity check failed: %d orphan poems", total)
+}
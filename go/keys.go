@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runUser 创建用户或修改已有用户的角色：go run . user -name alice -role editor
+func runUser(args []string) error {
+	fs := flag.NewFlagSet("user", flag.ExitOnError)
+	name := fs.String("name", "", "用户名")
+	role := fs.String("role", roleReader, "角色：reader、editor 或 admin")
+	fs.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("user: -name is required")
+	}
+	if _, ok := roleLevels[*role]; !ok {
+		return fmt.Errorf("user: unknown role %q", *role)
+	}
+
+	_, err := exec(context.Background(), "save_user", `INSERT INTO users (name, role, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET role = excluded.role`, *name, *role, now())
+	if err != nil {
+		return fmt.Errorf("save user: %w", err)
+	}
+	fmt.Printf("User %s saved with role %s\n", *name, *role)
+	return nil
+}
+
+// runKey 管理 API Key：
+//
+//	go run . key create -user alice [-name ci]
+//	go run . key revoke -id 3
+//	go run . key list
+func runKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("key: expected create, revoke or list")
+	}
+	switch args[0] {
+	case "create":
+		return createKey(args[1:])
+	case "revoke":
+		return revokeKey(args[1:])
+	case "list":
+		return listKeys()
+	default:
+		return fmt.Errorf("key: unknown action %q", args[0])
+	}
+}
+
+func createKey(args []string) error {
+	fs := flag.NewFlagSet("key create", flag.ExitOnError)
+	user := fs.String("user", "", "所属用户名")
+	name := fs.String("name", "", "备注，如用途")
+	fs.Parse(args)
+
+	var userID int
+	err := queryRow(context.Background(), "get_user_id", "SELECT user_id FROM users WHERE name = ?", *user).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("key create: user %q not found, create it with: go run . user -name %s -role editor", *user, *user)
+	}
+	if err != nil {
+		return err
+	}
+
+	key := apiKeyPrefix + randomHex(24)
+	_, err = exec(context.Background(), "insert_api_key", "INSERT INTO api_keys (user_id, name, prefix, key_hash, created_at) VALUES (?, ?, ?, ?, ?)",
+		userID, *name, key[:len(apiKeyPrefix)+6], hashAPIKey(key), now())
+	if err != nil {
+		return fmt.Errorf("save api key: %w", err)
+	}
+	// 数据库只保存哈希，密钥只在这里显示一次
+	fmt.Println(key)
+	return nil
+}
+
+func revokeKey(args []string) error {
+	fs := flag.NewFlagSet("key revoke", flag.ExitOnError)
+	id := fs.Int("id", 0, "要吊销的 key_id，见 key list")
+	fs.Parse(args)
+
+	result, err := exec(context.Background(), "revoke_api_key", "UPDATE api_keys SET revoked_at = ? WHERE key_id = ? AND revoked_at IS NULL", now(), *id)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("key revoke: no active key with id %d", *id)
+	}
+	fmt.Printf("API key %d revoked\n", *id)
+	return nil
+}
+
+func listKeys() error {
+	rows, err := query(context.Background(), "list_api_keys", `SELECT k.key_id, k.prefix, u.name, u.role, k.name, k.created_at,
+			COALESCE(k.last_used_at, '-'), COALESCE(k.revoked_at, '-')
+		FROM api_keys k JOIN users u ON u.user_id = k.user_id ORDER BY k.key_id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPREFIX\tUSER\tROLE\tNAME\tCREATED\tLAST USED\tREVOKED")
+	for rows.Next() {
+		var id int
+		var prefix, user, role, name, created, used, revoked string
+		if err := rows.Scan(&id, &prefix, &user, &role, &name, &created, &used, &revoked); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%d\t%s…\t%s\t%s\t%s\t%s\t%s\t%s\n", id, prefix, user, role, name, created, used, revoked)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// runToken 为用户签发 JWT：go run . token -user alice -ttl 24h
+func runToken(args []string) error {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	user := fs.String("user", "", "用户名")
+	ttl := fs.Duration("ttl", tokenTTL, "有效期")
+	fs.Parse(args)
+
+	var role string
+	err := queryRow(context.Background(), "get_user_role", "SELECT role FROM users WHERE name = ?", *user).Scan(&role)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("token: user %q not found", *user)
+	}
+	if err != nil {
+		return err
+	}
+	if err := loadJWTSecret(); err != nil {
+		return err
+	}
+	token, expires, err := issueToken(*user, role, 0, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	fmt.Fprintf(os.Stderr, "expires at %s\n", expires.UTC().Format(time.RFC3339))
+	return nil
+}
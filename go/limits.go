@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+	"poetry/ratelimit"
+)
+
+// 请求大小限制
+const (
+	maxBodyBytes     = 1 << 20  // 请求体最大 1 MiB，足以容纳最长的诗作
+	maxQueryLen      = 2048     // 整个查询字符串的最大字节数
+	maxQueryParamLen = 256      // 单个查询参数值的最大字节数
+	maxImportBytes   = 64 << 20 // 导入的请求体最大 64 MiB，可以一次上传全部 poet.tang.*.json
+)
+
+// routeBodyLimits 是请求体上限不同于 maxBodyBytes 的路由。
+var routeBodyLimits = map[string]int64{
+	"/import": maxImportBytes,
+}
+
+// 限流默认值，可通过环境变量调整
+const (
+	defaultRateLimit = 10 // 每秒补充的令牌数
+	defaultRateBurst = 40 // 桶容量
+	defaultRouteCost = 1
+	// authCost 是携带凭据的请求在认证之前从客户端 IP 的桶扣除的令牌数，
+	// 限制猜测凭据的速度，API Key 的查询和写库也不会绕过限流
+	authCost = 1
+)
+
+// routeCosts 是各路由每次请求扣除的令牌数，未列出的路由为 defaultRouteCost。
+// 搜索会全表扫描，统计接口需要聚合全部诗作，代价较高；GraphQL 一次请求可以读取多种数据；导出和导入会读写大量数据；
+// 质量报告需要检查全部诗作。
+var routeCosts = map[string]float64{
+	"/search/poems":        5,
+	"/search/authors":      5,
+	"/authors/:id/poems":   2,
+	"/data/stats":          2,
+	"/data/echart/:params": 3,
+	"/data/table":          3,
+	"/admin/audit/export":  10,
+	"/graphql":             5,
+	"/export/poems":        10,
+	"/export/authors":      10,
+	"/import":              10,
+	"/reports/quality":     10,
+}
+
+// rateLimiter 是 HTTP 和 gRPC 共用的限流器，同一客户端 IP 或用户在两种协议上使用同一个令牌桶。
+// 由 setupRateLimiter 创建，为 nil 时不限流。
+var rateLimiter *ratelimit.Limiter
+
+// setupRateLimiter 创建共用的限流器，见 newRateLimiter。
+func setupRateLimiter() {
+	rateLimiter = newRateLimiter()
+}
+
+// newRateLimiter 按环境变量 POETRY_RATE_LIMIT（每秒令牌数）和 POETRY_RATE_BURST（桶容量）
+// 创建限流器，POETRY_RATE_LIMIT=0 时关闭限流返回 nil。
+func newRateLimiter() *ratelimit.Limiter {
+	rate := envFloat("POETRY_RATE_LIMIT", defaultRateLimit)
+	if rate <= 0 {
+		return nil
+	}
+	return ratelimit.New(rate, envFloat("POETRY_RATE_BURST", defaultRateBurst))
+}
+
+func envFloat(name string, def float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		fatal("Invalid "+name, "error", err)
+	}
+	return f
+}
+
+// rateLimitClient 在认证之前按客户端 IP 扣除令牌：匿名请求扣除路由的令牌数，
+// 携带凭据的请求扣除 authCost，认证通过后再由 rateLimitUser 按用户扣除路由的令牌数。
+func rateLimitClient(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		cost := routeCost(c)
+		if hasCredentials(c) {
+			cost = authCost
+		}
+		if takeTokens(c, limiter, "ip:"+c.ClientIP(), cost) {
+			c.Next()
+		}
+	}
+}
+
+// rateLimitUser 在认证之后按用户扣除令牌，匿名请求已由 rateLimitClient 计数。
+func rateLimitUser(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := identity(c)
+		if limiter == nil || id == nil {
+			c.Next()
+			return
+		}
+		if takeTokens(c, limiter, fmt.Sprintf("user:%d", id.UserID), routeCost(c)) {
+			c.Next()
+		}
+	}
+}
+
+func routeCost(c *gin.Context) float64 {
+	if cost, ok := routeCosts[apiRoute(c)]; ok {
+		return cost
+	}
+	return defaultRouteCost
+}
+
+// takeTokens 从 key 的桶中扣除令牌并设置 X-RateLimit-* 响应头。
+// 令牌不足时返回 429 并通过 Retry-After 告知需要等待的秒数。
+func takeTokens(c *gin.Context, limiter *ratelimit.Limiter, key string, cost float64) bool {
+	allowed, wait := limiter.Take(key, cost)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(int(limiter.Burst)))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(limiter.Remaining(key)))
+	if !allowed {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		apierror.Write(c, apierror.RateLimited(apierror.CodeRateLimited, gin.H{"retry_after": retryAfter}))
+		return false
+	}
+	return true
+}
+
+// limitRequestSize 拒绝过长的查询参数，并限制请求体大小。已声明长度的超大请求体直接返回 413，
+// 未声明长度的在读取超过上限时由 bodyError 转换为 413。
+func limitRequestSize(c *gin.Context) {
+	if len(c.Request.URL.RawQuery) > maxQueryLen {
+		apierror.Write(c, apierror.Validation(apierror.CodeQueryTooLong, gin.H{"max_bytes": maxQueryLen}))
+		return
+	}
+	for name, values := range c.Request.URL.Query() {
+		for _, v := range values {
+			if len(v) > maxQueryParamLen {
+				apierror.Write(c, apierror.Validation(apierror.CodeQueryTooLong, gin.H{"param": name, "max_bytes": maxQueryParamLen}))
+				return
+			}
+		}
+	}
+
+	limit, ok := routeBodyLimits[apiRoute(c)]
+	if !ok {
+		limit = maxBodyBytes
+	}
+	if c.Request.ContentLength > limit {
+		apierror.Write(c, apierror.TooLarge(apierror.CodeBodyTooLarge, gin.H{"max_bytes": limit}))
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	c.Next()
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// authorPoemPreview 是作者列表中每位作者附带的诗作条数
+const authorPoemPreview = 6
+
+// loaderBatchSize 限制一条 IN 查询中的作者数，避免超过 SQLite 的参数个数上限
+const loaderBatchSize = 500
+
+// authorInclude 是作者列表中附带的关联数据，由 ?include=poems,total 指定。
+type authorInclude struct {
+	Poems bool // 每位作者的前 authorPoemPreview 首诗作
+	Total bool // 每位作者的诗作总数
+}
+
+// parseAuthorInclude 解析 ?include=，未提供时使用 def。
+func parseAuthorInclude(c *gin.Context, def authorInclude) (authorInclude, error) {
+	v, ok := c.GetQuery("include")
+	if !ok {
+		return def, nil
+	}
+	var inc authorInclude
+	for _, name := range strings.Split(v, ",") {
+		switch strings.TrimSpace(name) {
+		case "poems":
+			inc.Poems = true
+		case "total":
+			inc.Total = true
+		case "":
+		default:
+			return inc, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "include", "allowed": []string{"poems", "total"}})
+		}
+	}
+	return inc, nil
+}
+
+// loadAuthorPoems 为一页作者批量读取诗作预览和诗作总数，每 loaderBatchSize 位作者各只需一条查询，
+// 不随作者数增加查询次数。
+func loadAuthorPoems(ctx context.Context, authors []Author, inc authorInclude) error {
+	if !inc.Poems && !inc.Total {
+		return nil
+	}
+	ids := make([]int, len(authors))
+	for i, a := range authors {
+		ids[i] = a.AuthorID
+	}
+
+	if inc.Total {
+		totals, err := poemTotalsByAuthor(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for i := range authors {
+			authors[i].TotalPoems = totals[authors[i].AuthorID].Poems
+		}
+	}
+	if inc.Poems {
+		poems, err := poemsByAuthor(ctx, ids, 0, authorPoemPreview)
+		if err != nil {
+			return err
+		}
+		for i := range authors {
+			authors[i].Poems = poems[authors[i].AuthorID]
+			if authors[i].Poems == nil {
+				authors[i].Poems = []Poem{}
+			}
+		}
+	}
+	return nil
+}
+
+// inBatches 把 ids 按 loaderBatchSize 分批，为每批生成 IN 查询的占位符和参数。
+func inBatches(ids []int, f func(placeholders string, args []any) error) error {
+	for start := 0; start < len(ids); start += loaderBatchSize {
+		batch := ids[start:min(start+loaderBatchSize, len(ids))]
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		if err := f(strings.TrimSuffix(strings.Repeat("?,", len(batch)), ","), args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// poemTotals 是一位作者的诗作数和字数（不含换行）
+type poemTotals struct {
+	Poems, Words int
+}
+
+// poemTotalsByAuthor 批量统计作者的诗作数和字数，没有诗作的作者不在结果中。
+func poemTotalsByAuthor(ctx context.Context, ids []int) (map[int]poemTotals, error) {
+	totals := make(map[int]poemTotals, len(ids))
+	err := inBatches(ids, func(placeholders string, args []any) error {
+		rows, err := query(ctx, "author_poems_count_batch", `SELECT author_id, COUNT(*), COALESCE(SUM(LENGTH(REPLACE(content, char(10), ''))), 0)
+			FROM Poems WHERE deleted_at IS NULL AND author_id IN (`+placeholders+") GROUP BY author_id", args...)
+		if err != nil {
+			return fmt.Errorf("count poems for authors: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var authorID int
+			var t poemTotals
+			if err := rows.Scan(&authorID, &t.Poems, &t.Words); err != nil {
+				return fmt.Errorf("scan poem count: %w", err)
+			}
+			totals[authorID] = t
+		}
+		return rows.Err()
+	})
+	return totals, err
+}
+
+// poemsByAuthor 用窗口函数一次取出每位作者按 poem_id 排列的第 offset+1 到 offset+limit 首诗作。
+func poemsByAuthor(ctx context.Context, ids []int, offset, limit int) (map[int][]Poem, error) {
+	poems := make(map[int][]Poem, len(ids))
+	err := inBatches(ids, func(placeholders string, args []any) error {
+		rows, err := query(ctx, "author_poems_batch", `SELECT `+poemColumns+` FROM (
+				SELECT *,
+					ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY poem_id) AS n
+				FROM Poems WHERE deleted_at IS NULL AND author_id IN (`+placeholders+`)
+			) WHERE n > ? AND n <= ? ORDER BY author_id, poem_id`, append(args, offset, offset+limit)...)
+		if err != nil {
+			return fmt.Errorf("query poems for authors: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			p, err := scanPoem(rows)
+			if err != nil {
+				return fmt.Errorf("scan poem: %w", err)
+			}
+			poems[p.AuthorID] = append(poems[p.AuthorID], p)
+		}
+		return rows.Err()
+	})
+	return poems, err
+}
+
+// poemsByID 批量读取诗作，已删除或不存在的诗作不在结果中。
+func poemsByID(ctx context.Context, ids []int) (map[int]Poem, error) {
+	poems := make(map[int]Poem, len(ids))
+	err := inBatches(ids, func(placeholders string, args []any) error {
+		rows, err := query(ctx, "poems_batch",
+			"SELECT "+poemColumns+" FROM Poems WHERE deleted_at IS NULL AND poem_id IN ("+placeholders+")", args...)
+		if err != nil {
+			return fmt.Errorf("query poems: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			p, err := scanPoem(rows)
+			if err != nil {
+				return fmt.Errorf("scan poem: %w", err)
+			}
+			poems[p.PoemID] = p
+		}
+		return rows.Err()
+	})
+	return poems, err
+}
+
+// authorsByID 批量读取作者，已删除或不存在的作者不在结果中。
+func authorsByID(ctx context.Context, ids []int) (map[int]Author, error) {
+	authors := make(map[int]Author, len(ids))
+	err := inBatches(ids, func(placeholders string, args []any) error {
+		rows, err := query(ctx, "authors_batch",
+			"SELECT author_id, name, description, COALESCE(imgUrl, '') FROM Authors WHERE deleted_at IS NULL AND author_id IN ("+placeholders+")", args...)
+		if err != nil {
+			return fmt.Errorf("query authors: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var a Author
+			if err := rows.Scan(&a.AuthorID, &a.Name, &a.Description, &a.ImgUrl); err != nil {
+				return fmt.Errorf("scan author: %w", err)
+			}
+			authors[a.AuthorID] = a
+		}
+		return rows.Err()
+	})
+	return authors, err
+}
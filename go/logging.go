@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+
+	"poetry/apierror"
+)
+
+// logLevel 是当前的日志级别，启动时由 POETRY_LOG_LEVEL 设置
+var logLevel = new(slog.LevelVar)
+
+// setupLogging 把默认日志改为输出到标准错误的 JSON，级别取自 POETRY_LOG_LEVEL
+// （debug、info、warn、error，默认 info）。设置 POETRY_LOG_FORMAT=text 时输出便于阅读的文本格式。
+func setupLogging() error {
+	if v := os.Getenv("POETRY_LOG_LEVEL"); v != "" {
+		if err := logLevel.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid POETRY_LOG_LEVEL %q: %w", v, err)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stderr, opts)
+	if strings.EqualFold(os.Getenv("POETRY_LOG_FORMAT"), "text") {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// fatal 记录错误并退出。
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+const (
+	loggerKey      = "logger"
+	resultCountKey = "result_count"
+)
+
+// validRequestID 限制客户端传入的请求 ID，避免在日志中写入任意内容
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID 为每个请求确定请求 ID（沿用合法的 X-Request-ID，否则生成），
+// 写入响应头，并把带有 request_id 和 trace_id 的 logger 放入上下文。
+func requestID(c *gin.Context) {
+	id := c.GetHeader("X-Request-ID")
+	if !validRequestID.MatchString(id) {
+		id = apierror.NewRequestID()
+	}
+	c.Set(apierror.RequestIDKey, id)
+	c.Header("X-Request-ID", id)
+	l := slog.Default().With("request_id", id)
+	if traceID := c.GetString(apierror.TraceIDKey); traceID != "" {
+		l = l.With("trace_id", traceID)
+	}
+	c.Set(loggerKey, l)
+	traceAttrs(c.Request.Context(), attribute.String("request_id", id))
+	c.Next()
+}
+
+// logger 返回当前请求的 logger，日志自动带上 request_id 和 trace_id。
+func logger(c *gin.Context) *slog.Logger {
+	if l, ok := c.Get(loggerKey); ok {
+		return l.(*slog.Logger)
+	}
+	return slog.Default()
+}
+
+// setResultCount 记录列表接口返回的条数，写入访问日志。
+func setResultCount(c *gin.Context, n int) {
+	c.Set(resultCountKey, n)
+}
+
+// accessLog 在请求结束后记录一条访问日志，5xx 记为 error，4xx 记为 warn。
+func accessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("route", c.FullPath()),
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.Int("bytes", c.Writer.Size()),
+		slog.String("client_ip", c.ClientIP()),
+	}
+	if id := identity(c); id != nil {
+		attrs = append(attrs, slog.String("actor", id.Name))
+	}
+	if n, ok := c.Get(resultCountKey); ok {
+		attrs = append(attrs, slog.Any("result_count", n))
+	}
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, slog.String("errors", c.Errors.String()))
+	}
+	logger(c).LogAttrs(c.Request.Context(), level, "request", attrs...)
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"poetry/apierror"
+	"poetry/web"
 )
 
 var db *sql.DB
@@ -23,79 +33,327 @@ type Author struct {
 }
 
 type Poem struct {
-	PoemID   int    `json:"poem_id"`
-	Title    string `json:"title"`
-	AuthorID int    `json:"author_id"`
-	Content  string `json:"content"`
+	PoemID   int         `json:"poem_id"`
+	Title    string      `json:"title"`
+	AuthorID int         `json:"author_id"`
+	Content  string      `json:"content"`
+	Source   *PoemSource `json:"source,omitempty"` // 只读，来自原始数据的诗作才有
 }
 
+const dbPath = "./tang_poetry.db"
+
 func main() {
+	if err := setupLogging(); err != nil {
+		fatal("Failed to set up logging", "error", err)
+	}
+
 	var err error
-	db, err = sql.Open("sqlite3", "./tang_poetry.db")
+	// 开启外键约束，SQLite 默认不检查；写事务以 IMMEDIATE 开始，事务内先读后写的修改按顺序执行，
+	// 等待其他写事务的锁最多 5 秒
+	db, err = sql.Open("sqlite3", dbPath+"?_foreign_keys=on&_txlock=immediate&_busy_timeout=5000")
+	if err != nil {
+		fatal("Failed to connect to database", "error", err)
+	}
+	slog.Info("Connected to database", "path", dbPath)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		fatal("Failed to migrate database", "error", err)
+	}
+
+	// 带参数运行时执行子命令，如 go run . integrity
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			db.Close()
+			fatal("Command failed", "command", os.Args[1], "error", err)
+		}
+		return
+	}
+
+	if err := loadJWTSecret(); err != nil {
+		db.Close()
+		fatal("Failed to load JWT secret", "error", err)
+	}
+
+	shutdownTracing, err := setupTracing(context.Background())
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		db.Close()
+		fatal("Failed to set up tracing", "error", err)
 	}
-	log.Printf("Connected to database: %s", "./tang_poetry.db")
-	defer db.Close()
 
+	setupCaches()
+	setupRateLimiter()
+	registerMetrics()
 	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+	addr := os.Getenv("POETRY_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	err = serve(addr, setupRouter(), newRPCServer())
+	// 进行中的请求已处理完毕，可以安全关闭数据库
+	if cerr := db.Close(); cerr != nil {
+		slog.Error("Failed to close database", "error", cerr)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	if terr := shutdownTracing(ctx); terr != nil {
+		slog.Error("Failed to flush traces", "error", terr)
+	}
+	cancel()
+	if err != nil {
+		fatal("Server failed", "error", err)
+	}
+}
 
-	// 配置 CORS
-	router.Use(cors.Default())
+// apiPrefix 是接口的路径前缀，与前端的 BASE_URL 一致
+const apiPrefix = "/api"
+
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	// 只信任 POETRY_TRUSTED_PROXIES 中的反向代理转发的客户端 IP，审计记录中的 IP 不能被请求头伪造
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		fatal("Invalid POETRY_TRUSTED_PROXIES", "error", err)
+	}
+	router.Use(abortConnection, traceRequest, requestID, accessLog, observeRequest)
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		apierror.Write(c, fmt.Errorf("panic: %v", recovered))
+	}))
+
+	// 配置 CORS，只允许 POETRY_CORS_ORIGINS 中的来源跨域访问
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = corsOrigins()
+	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization", "X-API-Key")
+	router.Use(cors.New(corsConfig))
+
+	router.Use(limitRequestSize)
+	// 认证之前先按客户端 IP 限流，认证失败的请求同样计数；认证通过后再按用户限流
+	router.Use(rateLimitClient(rateLimiter))
+	// 解析 API Key / JWT；读接口允许匿名访问，写接口按角色校验
+	router.Use(authenticate)
+	router.Use(rateLimitUser(rateLimiter))
+	// 接口挂在 /api 下，根路径下的旧地址保留为已弃用的别名
+	apiRoutes(router.Group(apiPrefix))
+	apiRoutes(router.Group("", deprecatedAlias))
+	// 接口文档：/api/openapi.json 和 Swagger UI /api/docs/
+	openAPIRoutes(router.Group(apiPrefix))
+	// GraphQL 只挂在 /api 下
+	graphRoutes(router.Group(apiPrefix))
+
+	// Prometheus 指标和健康检查
+	router.GET("/metrics", metricsHandler())
+	router.GET("/healthz", healthz)
+	router.GET("/readyz", readyz)
+
+	// 其他 GET 请求返回前端页面，/api 下未匹配的路径仍返回 JSON 错误
+	ui, err := web.New()
+	if err != nil {
+		fatal("Failed to load web UI", "error", err)
+	}
+	if ui == nil {
+		slog.Warn("Web UI not embedded, run npm run build in poet-ui and rebuild to serve it")
+	}
+	router.NoRoute(func(c *gin.Context) {
+		path := c.Request.URL.Path
+		isAPI := path == apiPrefix || strings.HasPrefix(path, apiPrefix+"/")
+		if ui != nil && !isAPI && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) &&
+			ui.Serve(c.Writer, c.Request) {
+			return
+		}
+		apierror.Write(c, apierror.NotFound(apierror.CodeRouteNotFound, gin.H{"path": path}))
+	})
 
-	router.POST("/authors", createAuthor)
-	router.GET("/authors", getAuthors)
-	router.GET("/authors/:id", getAuthor)
-	router.PUT("/authors/:id", updateAuthor)
-	router.DELETE("/authors/:id", deleteAuthor)
-	router.GET("/authors/num/:number", getAuthorsByNumber)
+	return router
+}
 
-	router.POST("/poems", createPoem)
-	router.GET("/poems", getPoems)
-	router.GET("/poems/:id", getPoem)
-	router.PUT("/poems/:id", updatePoem)
-	router.DELETE("/poems/:id", deletePoem)
+// deprecatedAlias 标记根路径下的旧接口地址，响应头中给出 /api 下的新地址。
+func deprecatedAlias(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", "<"+apiPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+	c.Next()
+}
 
-	router.GET("/search/authors", searchAuthors)
-	router.GET("/search/poems", searchPoems)
-	router.GET("/authors/:id/poems", getAuthorAllPoems)
+// apiRoute 返回去掉 /api 前缀的路由模板，新旧地址对应同一个值，如 "/authors/:id"。
+func apiRoute(c *gin.Context) string {
+	return strings.TrimPrefix(c.FullPath(), apiPrefix)
+}
 
-	router.GET("/data/stats", dataStats)
-	router.GET("/data/echart/:params", dataEchart)
-	router.GET("/data/table", dataTable)
+// apiRoutes 在 r 下注册全部接口。
+func apiRoutes(r *gin.RouterGroup) {
+	reader := requireRole(roleReader)
+	editor := requireRole(roleEditor)
+
+	r.POST("/auth/token", reader, createToken)
+
+	r.POST("/authors", editor, createAuthor)
+	r.GET("/authors", getAuthors)
+	r.GET("/authors/:id", getAuthor)
+	r.PUT("/authors/:id", editor, updateAuthor)
+	r.PATCH("/authors/:id", editor, patchAuthor)
+	r.DELETE("/authors/:id", editor, deleteAuthor)
+	r.GET("/authors/num/:number", getAuthorsByNumber)
+	r.GET("/authors/:id/revisions", reader, getAuthorRevisions)
+	r.POST("/authors/:id/revisions/:rev/revert", editor, revertAuthor)
+
+	r.POST("/poems", editor, createPoem)
+	r.GET("/poems", getPoems)
+	r.GET("/poems/:id", getPoem)
+	r.GET("/poems/by-source/:uuid", getPoemBySource)
+	r.PUT("/poems/:id", editor, updatePoem)
+	r.PATCH("/poems/:id", editor, patchPoem)
+	r.DELETE("/poems/:id", editor, deletePoem)
+	r.GET("/poems/:id/revisions", reader, getPoemRevisions)
+	r.POST("/poems/:id/revisions/:rev/revert", editor, revertPoem)
+
+	r.GET("/search/authors", searchAuthors)
+	r.GET("/search/poems", searchPoems)
+	r.GET("/authors/:id/poems", getAuthorAllPoems)
+
+	r.GET("/data/stats", dataStats)
+	r.GET("/data/echart/:params", dataEchart)
+	r.GET("/data/table", dataTable)
+	r.GET("/reports/quality", qualityReport)
+
+	r.GET("/export/poems", exportPoemsHandler)
+	r.GET("/export/authors", exportAuthorsHandler)
+	r.POST("/import", editor, importPoems)
+
+	r.GET("/trash", editor, getTrash)
+	r.POST("/trash/:type/:id/restore", editor, restoreTrash)
+
+	// 投稿：登录用户提交新诗、勘误和作者小传，编辑审核通过后才写入正式数据
+	r.POST("/submissions/poems", reader, submitPoem)
+	r.POST("/submissions/poems/:id", reader, submitPoemCorrection)
+	r.POST("/submissions/authors/:id", reader, submitAuthorBio)
+	r.GET("/submissions", reader, getSubmissions)
+	r.GET("/submissions/:id", reader, getSubmission)
+	r.POST("/submissions/:id/approve", editor, approveSubmission)
+	r.POST("/submissions/:id/reject", editor, rejectSubmission)
+
+	admin := r.Group("/admin", requireRole(roleAdmin))
+	admin.GET("/audit", getAudit)
+	admin.GET("/audit/export", exportAudit)
+}
 
-	router.Run(":8080")
+// trustedProxies 返回逗号分隔的环境变量 POETRY_TRUSTED_PROXIES 中的代理地址，未设置时不信任任何代理。
+func trustedProxies() []string {
+	return splitEnv("POETRY_TRUSTED_PROXIES")
+}
+
+// corsOrigins 返回允许跨域访问的来源，取自逗号分隔的环境变量 POETRY_CORS_ORIGINS，
+// 未设置时只允许本地开发服务器。
+func corsOrigins() []string {
+	if origins := splitEnv("POETRY_CORS_ORIGINS"); len(origins) > 0 {
+		return origins
+	}
+	return []string{"http://localhost:5173", "http://localhost:3000"}
+}
+
+// splitEnv 把逗号分隔的环境变量拆分为列表，忽略空项。
+func splitEnv(name string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// paramID 解析路径参数中的整数 ID，格式错误时直接写入 400 响应并返回 false。
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidID, gin.H{"param": name}))
+		return 0, false
+	}
+	return id, true
+}
+
+// actor 返回执行写操作的用户名，写接口都要求认证，匿名只在异常情况下出现。
+func actor(c *gin.Context) string {
+	if id := identity(c); id != nil {
+		return id.Name
+	}
+	return "anonymous"
+}
+
+// now 返回写入数据库的时间戳（UTC，RFC 3339）。
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// authorWriteError 转换写入 Authors 表时的错误，作者名重复映射为 409。
+// 作者名的唯一约束也包括回收站中的作者，同名作者已删除时返回 author_in_trash，提示恢复或清除该作者。
+func authorWriteError(tx *tracedTx, err error, name string) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		var id int
+		lookupErr := tx.queryRow("deleted_author_by_name", "SELECT author_id FROM Authors WHERE name = ? AND deleted_at IS NOT NULL", name).Scan(&id)
+		if lookupErr == nil {
+			return apierror.Conflict(apierror.CodeAuthorInTrash, gin.H{
+				"name":      name,
+				"author_id": id,
+				"restore":   fmt.Sprintf("%s/trash/authors/%d/restore", apiPrefix, id),
+			}).WithCause(err)
+		}
+		if lookupErr != sql.ErrNoRows {
+			return apierror.Storage(fmt.Errorf("query deleted author %q: %w", name, lookupErr))
+		}
+		return apierror.Conflict(apierror.CodeAuthorExists, gin.H{"name": name}).WithCause(err)
+	}
+	return apierror.Storage(fmt.Errorf("write author: %w", err))
 }
 
 func createAuthor(c *gin.Context) {
 	var author Author
 	if err := c.ShouldBindJSON(&author); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Write(c, bodyError(err))
 		return
 	}
-
-	stmt, err := db.Prepare("INSERT INTO Authors (name, description) VALUES (?, ?)")
-	if err != nil {
-		log.Printf("Error preparing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := validateAuthor(author); err != nil {
+		apierror.Write(c, err)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(author.Name, author.Description)
+	id, err := createAuthorRow(c.Request.Context(), author, auditOf(c))
 	if err != nil {
-		log.Printf("Error executing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	log.Printf("Author created: %v", author)
+	logger(c).Info("Author created", "author_id", id, "name", author.Name)
 	c.JSON(http.StatusCreated, gin.H{"message": "Author created"})
 }
 
+// createAuthorRow 新建作者并写入审计记录，返回新作者的 ID。
+func createAuthorRow(ctx context.Context, author Author, a requestAudit) (int64, error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.exec("insert_author", "INSERT INTO Authors (name, description, imgUrl) VALUES (?, ?, ?)", author.Name, author.Description, author.ImgUrl)
+	if err != nil {
+		return 0, authorWriteError(tx, err, author.Name)
+	}
+	id, _ := result.LastInsertId()
+	if err := a.record(tx, auditCreate, entityAuthor, id, nil, authorFields(author)); err != nil {
+		return 0, apierror.Storage(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityAuthor, int(id))
+	return id, nil
+}
+
 func getAuthors(c *gin.Context) {
+	include, err := parseAuthorInclude(c, authorInclude{})
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
 	// 获取请求参数中的页码，默认为第一页
 	pageStr := c.Query("page")
 	page := 1
@@ -108,37 +366,19 @@ func getAuthors(c *gin.Context) {
 
 	// 设置每页显示的条数
 	const pageSize = 6
-	offset := (page - 1) * pageSize
 
-	// 计算总数
-	var totalAuthors int
-	err := db.QueryRow("SELECT COUNT(*) FROM Authors").Scan(&totalAuthors)
+	authors, totalAuthors, err := listAuthors(c.Request.Context(), page, pageSize)
 	if err != nil {
-		log.Printf("Error querying total authors: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-
-	rows, err := db.Query("SELECT * FROM Authors LIMIT ? OFFSET ?", pageSize, offset)
-	if err != nil {
-		log.Printf("Error querying database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := loadAuthorPoems(c.Request.Context(), authors, include); err != nil {
+		apierror.Write(c, apierror.Storage(err))
 		return
 	}
-	defer rows.Close()
-
-	authors := []Author{}
-	for rows.Next() {
-		var author Author
-		if err := rows.Scan(&author.AuthorID, &author.Name, &author.Description, &author.ImgUrl); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		authors = append(authors, author)
-	}
 
 	// 返回分页结果和总数
+	setResultCount(c, len(authors))
 	c.JSON(http.StatusOK, gin.H{
 		"page":      page,
 		"page_size": pageSize,
@@ -148,6 +388,12 @@ func getAuthors(c *gin.Context) {
 }
 
 func getAuthorsByNumber(c *gin.Context) {
+	include, err := parseAuthorInclude(c, authorInclude{})
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
 	// 获取请求参数中的 number，默认为 1
 	numberStr := c.Param("number")
 	number := 1
@@ -158,36 +404,19 @@ func getAuthorsByNumber(c *gin.Context) {
 		number = 1
 	}
 
-	// 计算总数
-	var totalAuthors int
-	err := db.QueryRow("SELECT COUNT(*) FROM Authors").Scan(&totalAuthors)
-	if err != nil {
-		log.Printf("Error querying total authors: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
 	// 查询指定数量的作者
-	rows, err := db.Query("SELECT * FROM Authors LIMIT ?", number)
+	authors, totalAuthors, err := firstAuthors(c.Request.Context(), number)
 	if err != nil {
-		log.Printf("Error querying database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-	defer rows.Close()
-
-	authors := []Author{}
-	for rows.Next() {
-		var author Author
-		if err := rows.Scan(&author.AuthorID, &author.Name, &author.Description, &author.ImgUrl); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		authors = append(authors, author)
+	if err := loadAuthorPoems(c.Request.Context(), authors, include); err != nil {
+		apierror.Write(c, apierror.Storage(err))
+		return
 	}
 
 	// 返回结果和总数
+	setResultCount(c, len(authors))
 	c.JSON(http.StatusOK, gin.H{
 		"requested_number": number,
 		"total_available":  totalAuthors,
@@ -196,94 +425,291 @@ func getAuthorsByNumber(c *gin.Context) {
 }
 
 func getAuthor(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	authorCache.serve(c, id, func() (any, int, error) {
+		author, err := loadAuthor(c.Request.Context(), id)
+		return author, -1, err
+	})
+}
+
+// loadAuthor 读取单个作者，不存在时返回 author_not_found。
+func loadAuthor(ctx context.Context, id int) (Author, error) {
 	var author Author
-	err := db.QueryRow("SELECT author_id, name, description, imgUrl FROM Authors WHERE author_id = ?", id).Scan(&author.AuthorID, &author.Name, &author.Description, &author.ImgUrl)
+	err := queryRow(ctx, "get_author", "SELECT author_id, name, description, COALESCE(imgUrl, '') FROM Authors WHERE author_id = ? AND deleted_at IS NULL", id).Scan(&author.AuthorID, &author.Name, &author.Description, &author.ImgUrl)
+	if err == sql.ErrNoRows {
+		return author, apierror.NotFound(apierror.CodeAuthorNotFound, gin.H{"author_id": id})
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("Author not found: %s", id)
-			c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
-		} else {
-			log.Printf("Error querying database: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
+		return author, apierror.Storage(fmt.Errorf("query author %d: %w", id, err))
 	}
+	return author, nil
+}
 
-	log.Printf("Author retrieved: %v", author)
-	c.JSON(http.StatusOK, author)
+// loadAuthorTx 在事务中读取单个作者，供先读后写的修改使用。
+func loadAuthorTx(tx *tracedTx, id int) (Author, error) {
+	var author Author
+	err := tx.queryRow("get_author", "SELECT author_id, name, description, COALESCE(imgUrl, '') FROM Authors WHERE author_id = ? AND deleted_at IS NULL", id).Scan(&author.AuthorID, &author.Name, &author.Description, &author.ImgUrl)
+	if err == sql.ErrNoRows {
+		return author, apierror.NotFound(apierror.CodeAuthorNotFound, gin.H{"author_id": id})
+	}
+	if err != nil {
+		return author, apierror.Storage(fmt.Errorf("query author %d: %w", id, err))
+	}
+	return author, nil
 }
 
+// 更新作者（PUT），name 必填，未提供的 description、imgUrl 保持原值
 func updateAuthor(c *gin.Context) {
-	id := c.Param("id")
-	var author Author
-	if err := c.ShouldBindJSON(&author); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	saveAuthor(c, "name")
+}
+
+// 部分更新作者（PATCH），请求体按 JSON Merge Patch 处理
+func patchAuthor(c *gin.Context) {
+	saveAuthor(c)
+}
+
+func saveAuthor(c *gin.Context, required ...string) {
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-
-	stmt, err := db.Prepare("UPDATE Authors SET name = ?, description = ?, imgUrl = ? WHERE author_id = ?")
+	patch, err := readPatch(c, required...)
 	if err != nil {
-		log.Printf("Error preparing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(author.Name, author.Description, author.ImgUrl, id)
+	// 修改说明通过 ?comment= 传入，随修订记录保存
+	_, rev, err := updateAuthorRow(c.Request.Context(), id, auditOf(c), c.Query("comment"), func(author *Author) error {
+		if err := applyPatch(author, patch); err != nil {
+			return err
+		}
+		return validateAuthor(*author)
+	})
 	if err != nil {
-		log.Printf("Error executing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	log.Printf("Author updated: %v", author)
-	c.JSON(http.StatusOK, gin.H{"message": "Author updated"})
+	logger(c).Info("Author updated", "author_id", id, "revision_id", rev)
+	c.JSON(http.StatusOK, gin.H{"message": "Author updated", "revision_id": rev})
 }
 
+// 删除作者时对其诗作的处理方式，通过 ?poems= 指定
+const (
+	poemsRestrict = "restrict" // 默认：作者仍有诗作时拒绝删除
+	poemsCascade  = "cascade"  // 连同诗作一起删除
+	poemsReassign = "reassign" // 把诗作转给 ?to= 指定的作者
+)
+
 func deleteAuthor(c *gin.Context) {
-	id := c.Param("id")
-	stmt, err := db.Prepare("DELETE FROM Authors WHERE author_id = ?")
-	if err != nil {
-		log.Printf("Error preparing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
+	mode := c.DefaultQuery("poems", poemsRestrict)
+	to := 0
+	switch mode {
+	case poemsRestrict, poemsCascade:
+	case poemsReassign:
+		var err error
+		to, err = strconv.Atoi(c.Query("to"))
+		if err != nil || to < 1 || to == id {
+			apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "to"}))
+			return
+		}
+	default:
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{
+			"param":   "poems",
+			"allowed": []string{poemsRestrict, poemsCascade, poemsReassign},
+		}))
+		return
+	}
+
+	affected, err := removeAuthor(c.Request.Context(), id, mode, to, auditOf(c))
 	if err != nil {
-		log.Printf("Error executing statement: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	log.Printf("Author deleted: %s", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Author deleted"})
+	logger(c).Info("Author deleted", "author_id", id, "poems", mode, "affected", affected)
+	resp := gin.H{"message": "Author deleted"}
+	switch mode {
+	case poemsCascade:
+		resp["poems_deleted"] = affected
+	case poemsReassign:
+		resp["poems_reassigned"] = affected
+		resp["to"] = to
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// removeAuthor 在一个事务中按 mode 处理作者的诗作并把作者移入回收站，返回受影响的诗作数。
+// cascade 时诗作与作者使用相同的删除时间，恢复作者时据此一并恢复。
+// 作者和每首受影响的诗作各写一条审计记录。
+func removeAuthor(ctx context.Context, id int, mode string, to int, a requestAudit) (int64, error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var author Author
+	var imgUrl sql.NullString
+	err = tx.queryRow("get_author", "SELECT name, description, imgUrl FROM Authors WHERE author_id = ? AND deleted_at IS NULL", id).
+		Scan(&author.Name, &author.Description, &imgUrl)
+	if err == sql.ErrNoRows {
+		return 0, apierror.NotFound(apierror.CodeAuthorNotFound, gin.H{"author_id": id})
+	}
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("query author %d: %w", id, err))
+	}
+	author.ImgUrl = imgUrl.String
+
+	deletedAt := now()
+	var moved []Poem // 被删除或改为其他作者的诗作
+	switch mode {
+	case poemsRestrict:
+		var count int64
+		if err := tx.queryRow("count_author_poems", "SELECT COUNT(*) FROM Poems WHERE author_id = ? AND deleted_at IS NULL", id).Scan(&count); err != nil {
+			return 0, apierror.Storage(fmt.Errorf("count poems for author %d: %w", id, err))
+		}
+		if count > 0 {
+			return 0, apierror.Conflict(apierror.CodeAuthorHasPoems, gin.H{"author_id": id, "poem_count": count})
+		}
+	case poemsCascade:
+		poems, err := authorPoemsTx(tx, id)
+		if err != nil {
+			return 0, apierror.Storage(err)
+		}
+		if _, err := tx.exec("delete_author_poems", "UPDATE Poems SET deleted_at = ?, deleted_by = ? WHERE author_id = ? AND deleted_at IS NULL", deletedAt, a.Actor, id); err != nil {
+			return 0, apierror.Storage(fmt.Errorf("delete poems for author %d: %w", id, err))
+		}
+		for _, p := range poems {
+			if err := a.record(tx, auditDelete, entityPoem, int64(p.PoemID), poemFields(p), nil); err != nil {
+				return 0, apierror.Storage(err)
+			}
+		}
+		moved = poems
+	case poemsReassign:
+		var exists int
+		err := tx.queryRow("author_exists", "SELECT 1 FROM Authors WHERE author_id = ? AND deleted_at IS NULL", to).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return 0, apierror.Validation(apierror.CodeUnknownAuthor, gin.H{"author_id": to})
+		}
+		if err != nil {
+			return 0, apierror.Storage(fmt.Errorf("query author %d: %w", to, err))
+		}
+		poems, err := authorPoemsTx(tx, id)
+		if err != nil {
+			return 0, apierror.Storage(err)
+		}
+		if _, err := tx.exec("reassign_author_poems", "UPDATE Poems SET author_id = ? WHERE author_id = ? AND deleted_at IS NULL", to, id); err != nil {
+			return 0, apierror.Storage(fmt.Errorf("reassign poems from author %d to %d: %w", id, to, err))
+		}
+		for _, p := range poems {
+			after := p
+			after.AuthorID = to
+			if err := a.record(tx, auditUpdate, entityPoem, int64(p.PoemID), poemFields(p), poemFields(after)); err != nil {
+				return 0, apierror.Storage(err)
+			}
+		}
+		moved = poems
+	}
+
+	if _, err := tx.exec("delete_author", "UPDATE Authors SET deleted_at = ?, deleted_by = ? WHERE author_id = ?", deletedAt, a.Actor, id); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("delete author %d: %w", id, err))
+	}
+	if err := a.record(tx, auditDelete, entityAuthor, int64(id), authorFields(author), nil); err != nil {
+		return 0, apierror.Storage(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityAuthor, id)
+	invalidate(entityPoem, poemIDs(moved)...)
+	return int64(len(moved)), nil
+}
+
+func poemIDs(poems []Poem) []int {
+	ids := make([]int, len(poems))
+	for i, p := range poems {
+		ids[i] = p.PoemID
+	}
+	return ids
+}
+
+// authorPoemsTx 在 tx 中读取作者未删除的全部诗作。
+func authorPoemsTx(tx *tracedTx, authorID int) ([]Poem, error) {
+	rows, err := tx.query("author_poems_tx", "SELECT poem_id, title, author_id, content FROM Poems WHERE author_id = ? AND deleted_at IS NULL", authorID)
+	if err != nil {
+		return nil, fmt.Errorf("query poems for author %d: %w", authorID, err)
+	}
+	defer rows.Close()
+
+	var poems []Poem
+	for rows.Next() {
+		var p Poem
+		if err := rows.Scan(&p.PoemID, &p.Title, &p.AuthorID, &p.Content); err != nil {
+			return nil, fmt.Errorf("scan poem: %w", err)
+		}
+		poems = append(poems, p)
+	}
+	return poems, rows.Err()
 }
 
 func createPoem(c *gin.Context) {
 	var poem Poem
 	if err := c.ShouldBindJSON(&poem); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Write(c, bodyError(err))
 		return
 	}
 
-	stmt, err := db.Prepare("INSERT INTO Poems (title, author_id, content) VALUES (?, ?, ?)")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if _, err := createPoemRow(c.Request.Context(), poem, auditOf(c)); err != nil {
+		apierror.Write(c, err)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(poem.Title, poem.AuthorID, poem.Content)
+	c.JSON(http.StatusCreated, gin.H{"message": "Poem created"})
+}
+
+// createPoemRow 在新事务中校验并新建诗作，返回新诗作的 ID。
+func createPoemRow(ctx context.Context, poem Poem, a requestAudit) (int64, error) {
+	tx, err := begin(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
 	}
+	defer tx.Rollback()
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Poem created"})
+	if err := validatePoemTx(tx, poem); err != nil {
+		return 0, err
+	}
+	id, err := insertPoem(tx, poem, a)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityPoem, int(id))
+	return id, nil
+}
+
+// insertPoem 在 tx 中新建诗作并写入审计记录，返回新诗作的 ID。
+func insertPoem(tx *tracedTx, poem Poem, a requestAudit) (int64, error) {
+	result, err := tx.exec("insert_poem", "INSERT INTO Poems (title, author_id, content) VALUES (?, ?, ?)", poem.Title, poem.AuthorID, poem.Content)
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("insert poem: %w", err))
+	}
+	id, _ := result.LastInsertId()
+	if err := a.record(tx, auditCreate, entityPoem, id, nil, poemFields(poem)); err != nil {
+		return 0, apierror.Storage(err)
+	}
+	return id, nil
 }
 
 func getPoems(c *gin.Context) {
@@ -299,35 +725,15 @@ func getPoems(c *gin.Context) {
 
 	// 设置每页显示的条数
 	const pageSize = 6
-	offset := (page - 1) * pageSize
 
-	// 计算总数
-	var totalPoems int
-	err := db.QueryRow("SELECT COUNT(*) FROM Poems").Scan(&totalPoems)
+	poems, totalPoems, err := listPoems(c.Request.Context(), page, pageSize)
 	if err != nil {
-		log.Printf("Error querying total poems: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	rows, err := db.Query("SELECT poem_id, title, author_id, content FROM Poems LIMIT ? OFFSET ?", pageSize, offset)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer rows.Close()
-
-	poems := []Poem{}
-	for rows.Next() {
-		var poem Poem
-		if err := rows.Scan(&poem.PoemID, &poem.Title, &poem.AuthorID, &poem.Content); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		poems = append(poems, poem)
-	}
-
 	// 返回分页结果和总数
+	setResultCount(c, len(poems))
 	c.JSON(http.StatusOK, gin.H{
 		"page":      page,
 		"page_size": pageSize,
@@ -337,82 +743,131 @@ func getPoems(c *gin.Context) {
 }
 
 func getPoem(c *gin.Context) {
-	id := c.Param("id")
-	var poem Poem
-	err := db.QueryRow("SELECT poem_id, title, author_id, content FROM Poems WHERE poem_id = ?", id).Scan(&poem.PoemID, &poem.Title, &poem.AuthorID, &poem.Content)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Poem not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
+	poemCache.serve(c, id, func() (any, int, error) {
+		poem, err := loadPoem(c.Request.Context(), id)
+		if err == nil {
+			traceAttrs(c.Request.Context(), attribute.Int("author_id", poem.AuthorID))
+		}
+		return poem, -1, err
+	})
+}
 
-	c.JSON(http.StatusOK, poem)
+// loadPoem 读取单首诗，不存在时返回 poem_not_found。
+func loadPoem(ctx context.Context, id int) (Poem, error) {
+	poem, err := scanPoem(queryRow(ctx, "get_poem", "SELECT "+poemColumns+" FROM Poems WHERE poem_id = ? AND deleted_at IS NULL", id))
+	if err == sql.ErrNoRows {
+		return poem, apierror.NotFound(apierror.CodePoemNotFound, gin.H{"poem_id": id})
+	}
+	if err != nil {
+		return poem, apierror.Storage(fmt.Errorf("query poem %d: %w", id, err))
+	}
+	return poem, nil
 }
 
+// loadPoemTx 在事务中读取单首诗，供先读后写的修改使用。
+func loadPoemTx(tx *tracedTx, id int) (Poem, error) {
+	poem, err := scanPoem(tx.queryRow("get_poem", "SELECT "+poemColumns+" FROM Poems WHERE poem_id = ? AND deleted_at IS NULL", id))
+	if err == sql.ErrNoRows {
+		return poem, apierror.NotFound(apierror.CodePoemNotFound, gin.H{"poem_id": id})
+	}
+	if err != nil {
+		return poem, apierror.Storage(fmt.Errorf("query poem %d: %w", id, err))
+	}
+	return poem, nil
+}
+
+// 更新诗作（PUT），title、author_id、content 均为必填
 func updatePoem(c *gin.Context) {
-	id := c.Param("id")
-	var poem Poem
-	if err := c.ShouldBindJSON(&poem); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	savePoem(c, "title", "author_id", "content")
+}
+
+// 部分更新诗作（PATCH），请求体按 JSON Merge Patch 处理
+func patchPoem(c *gin.Context) {
+	savePoem(c)
+}
+
+func savePoem(c *gin.Context, required ...string) {
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-
-	stmt, err := db.Prepare("UPDATE Poems SET title = ?, author_id = ?, content = ? WHERE poem_id = ?")
+	patch, err := readPatch(c, required...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(poem.Title, poem.AuthorID, poem.Content, id)
+	// 修改说明通过 ?comment= 传入，随修订记录保存
+	_, rev, err := updatePoemRow(c.Request.Context(), id, auditOf(c), c.Query("comment"), func(poem *Poem) error {
+		return applyPatch(poem, patch)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Poem updated"})
+	c.JSON(http.StatusOK, gin.H{"message": "Poem updated", "revision_id": rev})
 }
 
 func deletePoem(c *gin.Context) {
 	// 获取 URL 参数中的 poem_id
-	id := c.Param("id")
-
-	// 准备 SQL 语句
-	stmt, err := db.Prepare("DELETE FROM Poems WHERE poem_id = ?")
-	if err != nil {
-		// 如果准备语句失败，返回 500 Internal Server Error
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "无法准备 SQL 语句"})
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-	defer stmt.Close()
 
-	// 执行删除操作
-	result, err := stmt.Exec(id)
-	if err != nil {
-		// 如果执行失败，返回 500 Internal Server Error
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除诗时发生错误"})
+	if err := removePoem(c.Request.Context(), id, auditOf(c)); err != nil {
+		apierror.Write(c, err)
 		return
 	}
 
-	// 获取删除的行数
-	rowsAffected, err := result.RowsAffected()
+	// 如果删除成功，返回 200 OK
+	c.JSON(http.StatusOK, gin.H{"message": "Poem deleted"})
+}
+
+// removePoem 把诗作移入回收站并写入审计记录，诗作不存在或已删除时返回 poem_not_found。
+func removePoem(ctx context.Context, id int, a requestAudit) error {
+	tx, err := begin(ctx)
 	if err != nil {
-		// 如果获取受影响行数失败，返回 500 Internal Server Error
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "无法获取受影响的行数"})
-		return
+		return apierror.Storage(fmt.Errorf("begin transaction: %w", err))
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		// 如果没有行被删除，返回 404 Not Found
-		c.JSON(http.StatusNotFound, gin.H{"error": "未找到诗"})
-		return
+	if err := removePoemTx(tx, id, a); err != nil {
+		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityPoem, id)
+	return nil
+}
 
-	// 如果删除成功，返回 200 OK
-	c.JSON(http.StatusOK, gin.H{"message": "Poem deleted"})
+// removePoemTx 是在调用方事务中执行的 removePoem。
+func removePoemTx(tx *tracedTx, id int, a requestAudit) error {
+	// 读取删除前的内容写入审计记录
+	var poem Poem
+	err := tx.queryRow("get_poem", "SELECT poem_id, title, author_id, content FROM Poems WHERE poem_id = ? AND deleted_at IS NULL", id).
+		Scan(&poem.PoemID, &poem.Title, &poem.AuthorID, &poem.Content)
+	if err == sql.ErrNoRows {
+		return apierror.NotFound(apierror.CodePoemNotFound, gin.H{"poem_id": id})
+	}
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("query poem %d: %w", id, err))
+	}
+
+	// 删除只是移入回收站
+	if _, err := tx.exec("delete_poem", "UPDATE Poems SET deleted_at = ?, deleted_by = ? WHERE poem_id = ?", now(), a.Actor, id); err != nil {
+		return apierror.Storage(fmt.Errorf("delete poem %d: %w", id, err))
+	}
+	if err := a.record(tx, auditDelete, entityPoem, int64(id), poemFields(poem), nil); err != nil {
+		return apierror.Storage(err)
+	}
+	return nil
 }
 
 // 搜索作者（模糊匹配）
@@ -420,7 +875,13 @@ func searchAuthors(c *gin.Context) {
 	// 获取查询参数中的 name
 	name := c.Query("name")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name 参数不能为空"})
+		apierror.Write(c, apierror.Validation(apierror.CodeMissingParam, gin.H{"param": "name"}))
+		return
+	}
+	// 搜索结果默认附带诗作预览和总数
+	include, err := parseAuthorInclude(c, authorInclude{Poems: true, Total: true})
+	if err != nil {
+		apierror.Write(c, err)
 		return
 	}
 
@@ -436,49 +897,19 @@ func searchAuthors(c *gin.Context) {
 
 	// 设置每页显示的条数
 	const pageSize = 6
-	offset := (page - 1) * pageSize
 
-	// 查询作者总数
-	var totalAuthors int
-	err := db.QueryRow("SELECT COUNT(*) FROM Authors WHERE name LIKE ?", "%"+name+"%").Scan(&totalAuthors)
+	authors, totalAuthors, err := findAuthors(c.Request.Context(), name, page, pageSize)
 	if err != nil {
-		log.Printf("Error querying total authors: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-
-	// 查询作者
-	rows, err := db.Query("SELECT author_id, name, description FROM Authors WHERE name LIKE ? LIMIT ? OFFSET ?", "%"+name+"%", pageSize, offset)
-	if err != nil {
-		log.Printf("Error querying database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := loadAuthorPoems(c.Request.Context(), authors, include); err != nil {
+		apierror.Write(c, apierror.Storage(err))
 		return
 	}
-	defer rows.Close()
-
-	authors := []Author{}
-	for rows.Next() {
-		var author Author
-		if err := rows.Scan(&author.AuthorID, &author.Name, &author.Description); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		// 查询该作者的部分诗作（分页显示）
-		poems, totalPoems, err := getAuthorPoems(author.AuthorID, 1, 6) // 默认为第一页，每页6条
-		if err != nil {
-			log.Printf("Error querying poems for author %d: %v", author.AuthorID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		author.Poems = poems
-		author.TotalPoems = totalPoems
-		authors = append(authors, author)
-	}
 
 	// 返回结果
+	setResultCount(c, len(authors))
 	c.JSON(http.StatusOK, gin.H{
 		"page":      page,
 		"page_size": pageSize,
@@ -487,42 +918,12 @@ func searchAuthors(c *gin.Context) {
 	})
 }
 
-// 辅助函数：获取指定作者的部分诗作（分页显示）
-func getAuthorPoems(authorID int, page int, pageSize int) ([]Poem, int, error) {
-	offset := (page - 1) * pageSize
-
-	// 查询该作者的诗作总数
-	var totalPoems int
-	err := db.QueryRow("SELECT COUNT(*) FROM Poems WHERE author_id = ?", authorID).Scan(&totalPoems)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// 查询该作者的部分诗作
-	rows, err := db.Query("SELECT poem_id, title, content FROM Poems WHERE author_id = ? LIMIT ? OFFSET ?", authorID, pageSize, offset)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	poems := []Poem{}
-	for rows.Next() {
-		var poem Poem
-		if err := rows.Scan(&poem.PoemID, &poem.Title, &poem.Content); err != nil {
-			return nil, 0, err
-		}
-		poems = append(poems, poem)
-	}
-
-	return poems, totalPoems, nil
-}
-
 // 搜索诗作（模糊匹配）
 func searchPoems(c *gin.Context) {
 	// 获取查询参数中的 name
 	name := c.Query("name")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name 参数不能为空"})
+		apierror.Write(c, apierror.Validation(apierror.CodeMissingParam, gin.H{"param": "name"}))
 		return
 	}
 
@@ -538,38 +939,15 @@ func searchPoems(c *gin.Context) {
 
 	// 设置每页显示的条数
 	const pageSize = 6
-	offset := (page - 1) * pageSize
 
-	// 查询诗作总数
-	var totalPoems int
-	err := db.QueryRow("SELECT COUNT(*) FROM Poems WHERE title LIKE ? OR content LIKE ?", "%"+name+"%", "%"+name+"%").Scan(&totalPoems)
+	poems, totalPoems, err := findPoems(c.Request.Context(), name, page, pageSize)
 	if err != nil {
-		log.Printf("Error querying total poems: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
 
-	// 查询诗作
-	rows, err := db.Query("SELECT poem_id, title, author_id, content FROM Poems WHERE title LIKE ? OR content LIKE ? LIMIT ? OFFSET ?", "%"+name+"%", "%"+name+"%", pageSize, offset)
-	if err != nil {
-		log.Printf("Error querying database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer rows.Close()
-
-	poems := []Poem{}
-	for rows.Next() {
-		var poem Poem
-		if err := rows.Scan(&poem.PoemID, &poem.Title, &poem.AuthorID, &poem.Content); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		poems = append(poems, poem)
-	}
-
 	// 返回结果
+	setResultCount(c, len(poems))
 	c.JSON(http.StatusOK, gin.H{
 		"page":      page,
 		"page_size": pageSize,
@@ -583,8 +961,7 @@ func getAuthorAllPoems(c *gin.Context) {
 	authorIDStr := c.Param("id")
 	authorID, err := strconv.Atoi(authorIDStr)
 	if err != nil {
-		log.Printf("Error parsing author_id: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author_id"})
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidID, gin.H{"param": "id"}).WithCause(err))
 		return
 	}
 
@@ -600,38 +977,15 @@ func getAuthorAllPoems(c *gin.Context) {
 
 	// 设置每页显示的条数
 	const pageSize = 6
-	offset := (page - 1) * pageSize
-
-	// 查询该作者的诗作总数
-	var totalPoems int
-	err = db.QueryRow("SELECT COUNT(*) FROM Poems WHERE author_id = ?", authorID).Scan(&totalPoems)
-	if err != nil {
-		log.Printf("Error querying total poems for author %d: %v", authorID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
 
-	// 查询该作者的诗作
-	rows, err := db.Query("SELECT poem_id, title, content FROM Poems WHERE author_id = ? LIMIT ? OFFSET ?", authorID, pageSize, offset)
+	poems, totalPoems, err := listAuthorPoems(c.Request.Context(), authorID, page, pageSize)
 	if err != nil {
-		log.Printf("Error querying poems for author %d: %v", authorID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Write(c, err)
 		return
 	}
-	defer rows.Close()
-
-	poems := []Poem{}
-	for rows.Next() {
-		var poem Poem
-		if err := rows.Scan(&poem.PoemID, &poem.Title, &poem.Content); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		poems = append(poems, poem)
-	}
 
 	// 返回结果
+	setResultCount(c, len(poems))
 	c.JSON(http.StatusOK, gin.H{
 		"page":      page,
 		"page_size": pageSize,
@@ -641,13 +995,18 @@ func getAuthorAllPoems(c *gin.Context) {
 }
 
 func dataStats(c *gin.Context) {
+	aggregateCache.serve(c, "stats", func() (any, int, error) {
+		stats, err := loadStats(c.Request.Context())
+		return stats, -1, err
+	})
+}
+
+// loadStats 汇总作者数、诗作数和字数。
+func loadStats(ctx context.Context) (gin.H, error) {
 	// 查询 stats_view 视图
-	rows, err := db.Query("SELECT id, name, value FROM stats_view")
+	rows, err := query(ctx, "stats_view", "SELECT id, name, value FROM stats_view")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查询统计视图失败: " + err.Error(),
-		})
-		return
+		return nil, apierror.Storage(fmt.Errorf("query stats_view: %w", err))
 	}
 	defer rows.Close()
 
@@ -659,10 +1018,7 @@ func dataStats(c *gin.Context) {
 
 		err := rows.Scan(&id, &name, &value)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "解析统计数据失败: " + err.Error(),
-			})
-			return
+			return nil, apierror.Storage(fmt.Errorf("scan stats_view: %w", err))
 		}
 
 		stats = append(stats, gin.H{
@@ -690,14 +1046,15 @@ func dataStats(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": result,
-	})
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read stats_view: %w", err))
+	}
+	return gin.H{"data": result}, nil
 }
 func dataEchart(c *gin.Context) {
 	params := c.Param("params")
 	if params == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "params 参数不能为空"})
+		apierror.Write(c, apierror.Validation(apierror.CodeMissingParam, gin.H{"param": "params"}))
 		return
 	}
 
@@ -711,8 +1068,21 @@ func dataEchart(c *gin.Context) {
 		return
 
 	case "two":
-		// 查询 echart_two 视图数据
-		rows, err := db.Query(`
+		aggregateCache.serve(c, "echart_two", func() (any, int, error) {
+			return loadEchartTwo(c.Request.Context())
+		})
+		return
+
+	default:
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "params", "allowed": []string{"one", "two"}}))
+		return
+	}
+}
+
+// loadEchartTwo 读取各作者的诗作数和字数，按诗作数从多到少排列。
+func loadEchartTwo(ctx context.Context) (gin.H, int, error) {
+	// 查询 echart_two 视图数据
+	rows, err := query(ctx, "echart_two", `
             SELECT 
                 author_id, 
                 author_name, 
@@ -721,59 +1091,57 @@ func dataEchart(c *gin.Context) {
             FROM echart_two
             ORDER BY poem_count DESC
         `)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "查询诗作统计失败: " + err.Error(),
-			})
-			return
-		}
-		defer rows.Close()
-
-		var authors []gin.H
-		var totalPoems, totalWords int
-
-		for rows.Next() {
-			var authorID int
-			var authorName string
-			var poemCount, wordCount int
-
-			err := rows.Scan(&authorID, &authorName, &poemCount, &wordCount)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "解析诗作统计数据失败: " + err.Error(),
-				})
-				return
-			}
+	if err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query echart_two: %w", err))
+	}
+	defer rows.Close()
 
-			authors = append(authors, gin.H{
-				"author_id":   authorID,
-				"author_name": authorName,
-				"poem_count":  poemCount,
-				"word_count":  wordCount,
-			})
+	var authors []gin.H
+	var totalPoems, totalWords int
 
-			totalPoems += poemCount
-			totalWords += wordCount
+	for rows.Next() {
+		var authorID int
+		var authorName string
+		var poemCount, wordCount int
+
+		err := rows.Scan(&authorID, &authorName, &poemCount, &wordCount)
+		if err != nil {
+			return nil, 0, apierror.Storage(fmt.Errorf("scan echart_two: %w", err))
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"data": gin.H{
-				"authors":     authors,
-				"total_poems": totalPoems,
-				"total_words": totalWords,
-			},
+		authors = append(authors, gin.H{
+			"author_id":   authorID,
+			"author_name": authorName,
+			"poem_count":  poemCount,
+			"word_count":  wordCount,
 		})
-		return
 
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "params 参数错误"})
-		return
+		totalPoems += poemCount
+		totalWords += wordCount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("read echart_two: %w", err))
 	}
+
+	return gin.H{
+		"data": gin.H{
+			"authors":     authors,
+			"total_poems": totalPoems,
+			"total_words": totalWords,
+		},
+	}, len(authors), nil
 }
 
 func dataTable(c *gin.Context) {
+	aggregateCache.serve(c, "data_table", func() (any, int, error) {
+		return loadDataTable(c.Request.Context())
+	})
+}
+
+// loadDataTable 读取各作者的朝代、诗作数和字数，按诗作数从多到少排列。
+func loadDataTable(ctx context.Context) (gin.H, int, error) {
 	// 查询data_table视图数据
-	rows, err := db.Query(`
+	rows, err := query(ctx, "data_table", `
         SELECT 
             author_id,
             author_name,
@@ -784,10 +1152,7 @@ func dataTable(c *gin.Context) {
         ORDER BY poem_count DESC
     `)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查询表格数据失败: " + err.Error(),
-		})
-		return
+		return nil, 0, apierror.Storage(fmt.Errorf("query data_table: %w", err))
 	}
 	defer rows.Close()
 
@@ -805,10 +1170,7 @@ func dataTable(c *gin.Context) {
 
 		err := rows.Scan(&authorID, &authorName, &dynasty, &poemCount, &wordCount)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "解析表格数据失败: " + err.Error(),
-			})
-			return
+			return nil, 0, apierror.Storage(fmt.Errorf("scan data_table: %w", err))
 		}
 
 		tableData = append(tableData, gin.H{
@@ -823,13 +1185,17 @@ func dataTable(c *gin.Context) {
 		totalWords += wordCount
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	if err := rows.Err(); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("read data_table: %w", err))
+	}
+
+	return gin.H{
 		"data": gin.H{
 			"list":        tableData,
 			"total_poems": totalPoems,
 			"total_words": totalWords,
 		},
-	})
+	}, len(tableData), nil
 }
 
 // router.GET("/data/stats", dataStats)
@@ -0,0 +1,652 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"poetry/client"
+)
+
+// testAPIKey 是测试数据库中 editor 用户的 API Key
+const testAPIKey = apiKeyPrefix + "0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// openTestDB 在临时目录创建数据库：按 app.py 建表，执行迁移，写入三位作者、五首诗和一个 editor 用户，
+// 并设置全局的 db。关闭限流和服务端缓存，各测试之间互不影响。
+func openTestDB(t *testing.T) {
+	t.Helper()
+	t.Setenv("POETRY_RATE_LIMIT", "0")
+	t.Setenv("POETRY_CACHE_SIZE", "0")
+	gin.SetMode(gin.TestMode)
+	setupCaches()
+	setupRateLimiter()
+	jwtSecret = []byte("test")
+
+	var err error
+	db, err = sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_foreign_keys=on&_txlock=immediate&_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	stmts := []string{
+		`CREATE TABLE Authors (
+			author_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT UNIQUE,
+			description TEXT
+		)`,
+		`CREATE TABLE Poems (
+			poem_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT,
+			author_id INTEGER,
+			content TEXT,
+			FOREIGN KEY (author_id) REFERENCES Authors (author_id)
+		)`,
+		`CREATE TABLE Tang300 (
+			id TEXT PRIMARY KEY,
+			title TEXT,
+			author TEXT,
+			content TEXT,
+			tags TEXT
+		)`,
+		`INSERT INTO Authors (author_id, name, description) VALUES
+			(1, '李白', '字太白，号青莲居士'),
+			(2, '杜甫', '字子美'),
+			(3, '王维', '字摩诘')`,
+		`INSERT INTO Poems (poem_id, title, author_id, content) VALUES
+			(1, '静夜思', 1, '床前明月光，疑是地上霜。
+举头望明月，低头思故乡。'),
+			(2, '早发白帝城', 1, '朝辞白帝彩云间，千里江陵一日还。
+两岸猿声啼不住，轻舟已过万重山。'),
+			(3, '春望', 2, '国破山河在，城春草木深。
+感时花溅泪，恨别鸟惊心。'),
+			(4, '绝句', 2, '两个黄鹂鸣翠柳，一行白鹭上青天。
+窗含西岭千秋雪，门泊东吴万里船。'),
+			(5, '鹿柴', 3, '空山不见人，但闻人语响。
+返景入深林，复照青苔上。')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO users (user_id, name, role, created_at) VALUES (1, 'editor', ?, ?)", roleEditor, now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO api_keys (user_id, prefix, key_hash, created_at) VALUES (1, ?, ?, ?)",
+		testAPIKey[:len(apiKeyPrefix)+6], hashAPIKey(testAPIKey), now()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// getJSON 请求 path，检查状态码并把响应体解码到 out
+func getJSON(t *testing.T, srv *httptest.Server, path string, status int, out any) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		t.Fatalf("GET %s: status %d, want %d", path, resp.StatusCode, status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("GET %s: decode: %v", path, err)
+	}
+}
+
+// doJSON 以 editor 身份发送请求，检查状态码并把响应体解码到 out（为 nil 时不解码）
+func doJSON(t *testing.T, srv *httptest.Server, method, path string, body io.Reader, status int, out any) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", testAPIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, status, b)
+	}
+	if out != nil {
+		if err := json.Unmarshal(b, out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+}
+
+type testPage[T any] struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+	Data     []T `json:"data"`
+}
+
+type testError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+func TestReadEndpoints(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+
+	var authors testPage[Author]
+	getJSON(t, srv, "/api/authors?page=1", http.StatusOK, &authors)
+	if authors.Total != 3 || len(authors.Data) != 3 || authors.Data[0].Name != "李白" {
+		t.Errorf("authors: total %d, %d rows, first %+v", authors.Total, len(authors.Data), authors.Data)
+	}
+
+	var author Author
+	getJSON(t, srv, "/api/authors/2", http.StatusOK, &author)
+	if author.AuthorID != 2 || author.Name != "杜甫" || author.Description != "字子美" {
+		t.Errorf("author 2: %+v", author)
+	}
+
+	var poems testPage[Poem]
+	getJSON(t, srv, "/api/poems", http.StatusOK, &poems)
+	if poems.Total != 5 || len(poems.Data) != 5 {
+		t.Errorf("poems: total %d, %d rows", poems.Total, len(poems.Data))
+	}
+
+	var poem Poem
+	getJSON(t, srv, "/api/poems/5", http.StatusOK, &poem)
+	if poem.Title != "鹿柴" || poem.AuthorID != 3 {
+		t.Errorf("poem 5: %+v", poem)
+	}
+
+	var authorPoems testPage[Poem]
+	getJSON(t, srv, "/api/authors/1/poems", http.StatusOK, &authorPoems)
+	if authorPoems.Total != 2 || len(authorPoems.Data) != 2 {
+		t.Errorf("author 1 poems: total %d, %d rows", authorPoems.Total, len(authorPoems.Data))
+	}
+	for _, p := range authorPoems.Data {
+		if p.AuthorID != 1 {
+			t.Errorf("author 1 poems: got poem %d by author %d", p.PoemID, p.AuthorID)
+		}
+	}
+
+	var found testPage[Poem]
+	getJSON(t, srv, "/api/search/poems?name=明月", http.StatusOK, &found)
+	if found.Total != 1 || len(found.Data) != 1 || found.Data[0].PoemID != 1 {
+		t.Errorf("search poems: %+v", found)
+	}
+
+	var foundAuthors testPage[Author]
+	getJSON(t, srv, "/api/search/authors?name=王", http.StatusOK, &foundAuthors)
+	if foundAuthors.Total != 1 || len(foundAuthors.Data) != 1 || foundAuthors.Data[0].AuthorID != 3 {
+		t.Errorf("search authors: %+v", foundAuthors)
+	}
+
+	var stats struct {
+		Data struct {
+			Poets int `json:"poets"`
+			Poems int `json:"poems"`
+		} `json:"data"`
+	}
+	getJSON(t, srv, "/api/data/stats", http.StatusOK, &stats)
+	if stats.Data.Poets != 3 || stats.Data.Poems != 5 {
+		t.Errorf("stats: %+v", stats.Data)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/api/authors/999", http.StatusNotFound, "author_not_found"},
+		{"/api/poems/999", http.StatusNotFound, "poem_not_found"},
+		{"/api/authors/abc", http.StatusBadRequest, "invalid_id"},
+		{"/api/search/poems", http.StatusBadRequest, "missing_param"},
+		{"/api/nonexistent", http.StatusNotFound, "route_not_found"},
+	}
+	for _, tt := range tests {
+		var e testError
+		getJSON(t, srv, tt.path, tt.status, &e)
+		if e.Code != tt.code || e.Message == "" || e.RequestID == "" {
+			t.Errorf("GET %s: %+v, want code %s", tt.path, e, tt.code)
+		}
+	}
+}
+
+// TestClient 通过 client 包调用接口，检查客户端与服务端的请求和响应结构一致
+func TestClient(t *testing.T) {
+	openTestDB(t)
+	// 第一次请求 /data/stats 返回 503，检查客户端的重试
+	var injected atomic.Bool
+	router := setupRouter()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiPrefix+"/data/stats" && injected.CompareAndSwap(false, true) {
+			http.Error(w, "injected failure", http.StatusServiceUnavailable)
+			return
+		}
+		router.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL + apiPrefix)
+	c.RetryWait = time.Millisecond
+
+	stats, err := c.DataStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !injected.Load() || stats.Poets != 3 {
+		t.Errorf("DataStats: injected %v, %+v", injected.Load(), stats)
+	}
+
+	authors, err := c.ListAuthors(ctx, 1, &client.Include{Poems: true, Total: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authors.Total != 3 || authors.Data[0].TotalPoems != 2 || len(authors.Data[0].Poems) != 2 {
+		t.Errorf("ListAuthors: %+v", authors)
+	}
+
+	n := 0
+	for p, err := range c.AuthorPoems(ctx, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.AuthorID != 2 {
+			t.Errorf("AuthorPoems: poem %d by author %d", p.PoemID, p.AuthorID)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("AuthorPoems: iterated %d poems, want 2", n)
+	}
+
+	_, err = c.GetPoem(ctx, 999)
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "poem_not_found" {
+		t.Errorf("GetPoem(999): %v", err)
+	}
+
+	_, err = c.CreateAuthor(ctx, client.AuthorInput{Name: "孟浩然"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("anonymous CreateAuthor: %v, want 401", err)
+	}
+
+	c.APIKey = testAPIKey
+	res, err := c.PatchAuthor(ctx, 3, client.Patch{"description": "字摩诘，号摩诘居士"}, "补充号")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RevisionID == 0 {
+		t.Errorf("PatchAuthor: %+v, want a revision", res)
+	}
+	got, err := c.GetAuthor(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "字摩诘，号摩诘居士" {
+		t.Errorf("GetAuthor after patch: %+v", got)
+	}
+}
+
+// queryCounts 返回各命名语句的耗时记录次数
+func queryCounts(t *testing.T, reg *prometheus.Registry) map[string]uint64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]uint64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "query" {
+					counts[l.GetValue()] = m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// TestWriteMetrics 检查事务中的读写语句也记录耗时
+func TestWriteMetrics(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(dbQueryDuration)
+
+	before := queryCounts(t, reg)
+	doJSON(t, srv, http.MethodPatch, "/api/authors/3", strings.NewReader(`{"description": "字摩诘，号摩诘居士"}`), http.StatusOK, nil)
+	after := queryCounts(t, reg)
+	for _, name := range []string{"get_author", "update_author", "insert_revision", "insert_audit"} {
+		if got := after[name] - before[name]; got != 1 {
+			t.Errorf("%s: recorded %d times, want 1", name, got)
+		}
+	}
+}
+
+// testStep 是一次请求及预期的状态码和错误码
+type testStep struct {
+	method, path, body string
+	status             int
+	code               string
+}
+
+// runSteps 依次发送请求，检查状态码和错误码
+func runSteps(t *testing.T, srv *httptest.Server, steps []testStep) {
+	t.Helper()
+	for _, s := range steps {
+		var body io.Reader
+		if s.body != "" {
+			body = strings.NewReader(s.body)
+		}
+		var e testError
+		doJSON(t, srv, s.method, s.path, body, s.status, &e)
+		if e.Code != s.code {
+			t.Errorf("%s %s: code %q, want %q", s.method, s.path, e.Code, s.code)
+		}
+	}
+}
+
+// TestDeleteAndRestore 检查作者删除时对诗作的三种处理方式，以及从回收站恢复
+func TestDeleteAndRestore(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup string // 发送请求前执行的 SQL
+		steps []testStep
+	}{
+		{"restrict", "", []testStep{
+			{"DELETE", "/api/authors/1", "", http.StatusConflict, "author_has_poems"},
+			{"GET", "/api/authors/1", "", http.StatusOK, ""},
+			{"DELETE", "/api/poems/1", "", http.StatusOK, ""},
+			{"DELETE", "/api/poems/2", "", http.StatusOK, ""},
+			{"DELETE", "/api/authors/1", "", http.StatusOK, ""},
+			{"GET", "/api/authors/1", "", http.StatusNotFound, "author_not_found"},
+		}},
+		{"cascade", "", []testStep{
+			{"DELETE", "/api/authors/1?poems=cascade", "", http.StatusOK, ""},
+			{"GET", "/api/authors/1", "", http.StatusNotFound, "author_not_found"},
+			{"GET", "/api/poems/1", "", http.StatusNotFound, "poem_not_found"},
+			{"GET", "/api/poems/2", "", http.StatusNotFound, "poem_not_found"},
+			{"GET", "/api/poems/3", "", http.StatusOK, ""},
+		}},
+		{"reassign", "", []testStep{
+			{"DELETE", "/api/authors/1?poems=reassign&to=2", "", http.StatusOK, ""},
+			{"GET", "/api/authors/1", "", http.StatusNotFound, "author_not_found"},
+			{"GET", "/api/poems/1", "", http.StatusOK, ""},
+		}},
+		{"invalid mode", "", []testStep{
+			{"DELETE", "/api/authors/1?poems=all", "", http.StatusBadRequest, "invalid_param"},
+			{"DELETE", "/api/authors/1?poems=reassign", "", http.StatusBadRequest, "invalid_param"},
+			{"DELETE", "/api/authors/1?poems=reassign&to=1", "", http.StatusBadRequest, "invalid_param"},
+			{"DELETE", "/api/authors/1?poems=reassign&to=999", "", http.StatusBadRequest, "unknown_author"},
+			{"GET", "/api/authors/1", "", http.StatusOK, ""},
+		}},
+		{"restore poem", "", []testStep{
+			{"DELETE", "/api/poems/5", "", http.StatusOK, ""},
+			{"GET", "/api/poems/5", "", http.StatusNotFound, "poem_not_found"},
+			{"POST", "/api/trash/poems/5/restore", "", http.StatusOK, ""},
+			{"GET", "/api/poems/5", "", http.StatusOK, ""},
+			{"POST", "/api/trash/poems/5/restore", "", http.StatusNotFound, "not_in_trash"},
+		}},
+		// 先单独删除的诗作不随作者恢复
+		{"restore author with its poems", "UPDATE Poems SET deleted_at = '2026-01-01T00:00:00Z', deleted_by = 'editor' WHERE poem_id = 3", []testStep{
+			{"DELETE", "/api/authors/2?poems=cascade", "", http.StatusOK, ""},
+			{"POST", "/api/trash/poems/4/restore", "", http.StatusConflict, "author_deleted"},
+			{"POST", "/api/trash/authors/2/restore", "", http.StatusOK, ""},
+			{"GET", "/api/authors/2", "", http.StatusOK, ""},
+			{"GET", "/api/poems/4", "", http.StatusOK, ""},
+			{"GET", "/api/poems/3", "", http.StatusNotFound, "poem_not_found"},
+			{"POST", "/api/trash/poems/3/restore", "", http.StatusOK, ""},
+		}},
+		// 回收站中的作者仍占用作者名，恢复后可以正常使用
+		{"name of deleted author", "", []testStep{
+			{"DELETE", "/api/authors/2?poems=cascade", "", http.StatusOK, ""},
+			{"POST", "/api/authors", `{"name": "杜甫"}`, http.StatusConflict, "author_in_trash"},
+			{"PATCH", "/api/authors/3", `{"name": "杜甫"}`, http.StatusConflict, "author_in_trash"},
+			{"POST", "/api/authors", `{"name": "李白"}`, http.StatusConflict, "author_exists"},
+			{"POST", "/api/trash/authors/2/restore", "", http.StatusOK, ""},
+			{"POST", "/api/authors", `{"name": "杜甫"}`, http.StatusConflict, "author_exists"},
+		}},
+		{"invalid trash type", "", []testStep{
+			{"POST", "/api/trash/users/1/restore", "", http.StatusBadRequest, "invalid_param"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			if tt.setup != "" {
+				if _, err := db.Exec(tt.setup); err != nil {
+					t.Fatal(err)
+				}
+			}
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+			runSteps(t, srv, tt.steps)
+		})
+	}
+}
+
+// TestDeleteAuthorPoems 检查 cascade 和 reassign 时诗作的去向和回收站内容
+func TestDeleteAuthorPoems(t *testing.T) {
+	tests := []struct {
+		path   string
+		field  string
+		trash  int // 回收站中的条目数
+		author int // 诗作 1、2 删除后所属的作者，0 表示在回收站
+	}{
+		{"/api/authors/1?poems=cascade", "poems_deleted", 3, 0},
+		{"/api/authors/1?poems=reassign&to=2", "poems_reassigned", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			openTestDB(t)
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+
+			var resp map[string]any
+			doJSON(t, srv, http.MethodDelete, tt.path, nil, http.StatusOK, &resp)
+			if resp[tt.field] != float64(2) {
+				t.Errorf("response: %v, want %s 2", resp, tt.field)
+			}
+
+			var trash testPage[TrashItem]
+			doJSON(t, srv, http.MethodGet, "/api/trash", nil, http.StatusOK, &trash)
+			if trash.Total != tt.trash {
+				t.Errorf("trash: %+v, want %d items", trash.Data, tt.trash)
+			}
+
+			var poems testPage[Poem]
+			getJSON(t, srv, "/api/authors/2/poems", http.StatusOK, &poems)
+			want := 2
+			if tt.author == 2 {
+				want = 4
+			}
+			if poems.Total != want {
+				t.Errorf("author 2 poems: total %d, want %d", poems.Total, want)
+			}
+		})
+	}
+}
+
+// TestPurge 检查 purge 只永久删除在回收站中超过 -days 天的数据
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete string // 删除的接口
+		age    int    // 删除后经过的天数
+		args   []string
+		purged bool
+	}{
+		{"old poem", "/api/poems/5", 40, nil, true},
+		{"recent poem", "/api/poems/5", 10, nil, false},
+		{"custom days", "/api/poems/5", 10, []string{"-days", "7"}, true},
+		{"dry run", "/api/poems/5", 40, []string{"-dry-run"}, false},
+		{"old author with poems", "/api/authors/3?poems=cascade", 40, nil, true},
+		{"recent author with poems", "/api/authors/3?poems=cascade", 10, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+
+			doJSON(t, srv, http.MethodDelete, tt.delete, nil, http.StatusOK, nil)
+			deletedAt := time.Now().UTC().AddDate(0, 0, -tt.age).Format(time.RFC3339)
+			for _, table := range []string{"Poems", "Authors"} {
+				if _, err := db.Exec("UPDATE "+table+" SET deleted_at = ? WHERE deleted_at IS NOT NULL", deletedAt); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := runPurge(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			var poems, authors, live int
+			if err := db.QueryRow("SELECT COUNT(*) FROM Poems WHERE poem_id = 5").Scan(&poems); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.QueryRow("SELECT COUNT(*) FROM Authors WHERE author_id = 3").Scan(&authors); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.QueryRow("SELECT COUNT(*) FROM Poems WHERE deleted_at IS NULL").Scan(&live); err != nil {
+				t.Fatal(err)
+			}
+			if (poems == 0) != tt.purged {
+				t.Errorf("poem 5 purged %v, want %v", poems == 0, tt.purged)
+			}
+			if strings.Contains(tt.delete, "authors") && (authors == 0) != tt.purged {
+				t.Errorf("author 3 purged %v, want %v", authors == 0, tt.purged)
+			}
+			if authors == 0 && !strings.Contains(tt.delete, "authors") {
+				t.Error("author 3 purged without being deleted")
+			}
+			if live != 4 {
+				t.Errorf("%d poems left outside the trash, want 4", live)
+			}
+		})
+	}
+}
+
+// TestMergePatch 检查 PATCH 按 JSON Merge Patch 合并、PUT 要求必填字段，以及修改后读到的记录
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+		want   map[string]any // 修改后 GET path 返回的字段
+	}{
+		{"patch keeps other fields", "PATCH", "/api/poems/1", `{"title": "夜思"}`, http.StatusOK, "",
+			map[string]any{"title": "夜思", "author_id": float64(1), "content": "床前明月光，疑是地上霜。\n举头望明月，低头思故乡。"}},
+		{"null clears field", "PATCH", "/api/authors/3", `{"description": null}`, http.StatusOK, "",
+			map[string]any{"name": "王维", "description": ""}},
+		{"patch several fields", "PATCH", "/api/authors/3", `{"description": "诗佛", "imgUrl": "https://example.com/wangwei.jpg"}`, http.StatusOK, "",
+			map[string]any{"name": "王维", "description": "诗佛", "imgUrl": "https://example.com/wangwei.jpg"}},
+		{"null on required field", "PATCH", "/api/poems/1", `{"title": null}`, http.StatusBadRequest, "validation_failed",
+			map[string]any{"title": "静夜思"}},
+		{"patch to unknown author", "PATCH", "/api/poems/1", `{"author_id": 999}`, http.StatusBadRequest, "unknown_author",
+			map[string]any{"author_id": float64(1)}},
+		{"patch to duplicate name", "PATCH", "/api/authors/3", `{"name": "杜甫"}`, http.StatusConflict, "author_exists",
+			map[string]any{"name": "王维"}},
+		{"not an object", "PATCH", "/api/authors/3", `["王维"]`, http.StatusBadRequest, "invalid_json",
+			map[string]any{"name": "王维"}},
+		{"put requires fields", "PUT", "/api/authors/3", `{"description": "诗佛"}`, http.StatusBadRequest, "validation_failed",
+			map[string]any{"description": "字摩诘"}},
+		{"put keeps absent fields", "PUT", "/api/authors/3", `{"name": "王摩诘"}`, http.StatusOK, "",
+			map[string]any{"name": "王摩诘", "description": "字摩诘"}},
+		{"put poem", "PUT", "/api/poems/5", `{"title": "鹿砦", "author_id": 2, "content": "空山不见人，但闻人语响。"}`, http.StatusOK, "",
+			map[string]any{"title": "鹿砦", "author_id": float64(2), "content": "空山不见人，但闻人语响。"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			srv := httptest.NewServer(setupRouter())
+			defer srv.Close()
+
+			runSteps(t, srv, []testStep{{tt.method, tt.path, tt.body, tt.status, tt.code}})
+			var got map[string]any
+			getJSON(t, srv, tt.path, http.StatusOK, &got)
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s: %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+// 在事务中写入的请求只通过事务读取：连接池只有一个连接时，事务外的读取会一直等待连接
+func TestWritesReadInTx(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+	runSteps(t, srv, []testStep{
+		{"POST", "/api/submissions/poems", `{"title": "秋浦歌", "author_id": 1, "content": "白发三千丈，缘愁似个长。"}`, http.StatusCreated, ""},
+		{"POST", "/api/submissions/poems/2", `{"title": "春夜喜雨"}`, http.StatusCreated, ""},
+	})
+	db.SetMaxOpenConns(1)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	steps := []testStep{
+		{"POST", "/api/poems", `{"title": "赠汪伦", "author_id": 1, "content": "李白乘舟将欲行，忽闻岸上踏歌声。"}`, http.StatusCreated, ""},
+		{"POST", "/api/poems", `{"title": "赠汪伦", "author_id": 999, "content": "李白乘舟将欲行，忽闻岸上踏歌声。"}`, http.StatusBadRequest, "unknown_author"},
+		{"PUT", "/api/poems/5", `{"title": "鹿砦", "author_id": 3, "content": "空山不见人，但闻人语响。"}`, http.StatusOK, ""},
+		{"PATCH", "/api/poems/5", `{"author_id": 999}`, http.StatusBadRequest, "unknown_author"},
+		{"POST", "/api/poems/5/revisions/1/revert", "", http.StatusOK, ""},
+		{"POST", "/api/submissions/1/approve", "", http.StatusOK, ""},
+		{"POST", "/api/submissions/2/approve", "", http.StatusOK, ""},
+	}
+	for _, s := range steps {
+		req, err := http.NewRequest(s.method, srv.URL+s.path, strings.NewReader(s.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", testAPIKey)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", s.method, s.path, err)
+		}
+		var e testError
+		json.NewDecoder(resp.Body).Decode(&e)
+		resp.Body.Close()
+		if resp.StatusCode != s.status || e.Code != s.code {
+			t.Errorf("%s %s: %d %q, want %d %q", s.method, s.path, resp.StatusCode, e.Code, s.status, s.code)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsRegistry 只注册本服务的指标，不使用全局的 prometheus.DefaultRegisterer
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "poetry_http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "poetry_http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "poetry_db_query_duration_seconds",
+		Help:    "SQLite statement latency by statement name, including writes and reads inside transactions.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16), // 0.1ms 到约 3.3s
+	}, []string{"query"})
+
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "poetry_grpc_requests_total",
+		Help: "gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "poetry_grpc_request_duration_seconds",
+		Help:    "gRPC call latency by method and status code, streams until the last message.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "poetry_cache_requests_total",
+		Help: "Cache lookups by cache name and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+// registerMetrics 注册全部指标，db 必须已打开。
+func registerMetrics() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, "tang_poetry"),
+		httpRequests, httpDuration, rpcRequests, rpcDuration, dbQueryDuration, cacheRequests,
+		&corpusCollector{ttl: time.Minute},
+	)
+}
+
+// metricsHandler 以 Prometheus 文本格式输出指标。
+func metricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+}
+
+// observeRequest 记录请求数和耗时。未匹配路由的请求统一记为 unmatched，避免任意路径造成标签爆炸。
+func observeRequest(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(c.Writer.Status())
+	httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+	httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+}
+
+// cacheLookup 记录一次缓存查询的结果。
+func cacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+var (
+	corpusAuthorsDesc    = prometheus.NewDesc("poetry_corpus_authors", "Authors not in the trash.", nil, nil)
+	corpusPoemsDesc      = prometheus.NewDesc("poetry_corpus_poems", "Poems not in the trash.", nil, nil)
+	corpusCharactersDesc = prometheus.NewDesc("poetry_corpus_characters", "Characters in poems not in the trash, excluding line breaks.", nil, nil)
+)
+
+// corpusCollector 输出语料规模。统计需要扫描全部诗作，结果缓存 ttl，避免每次抓取都全表扫描。
+type corpusCollector struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	fetched time.Time
+	values  map[string]float64
+}
+
+func (cc *corpusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- corpusAuthorsDesc
+	ch <- corpusPoemsDesc
+	ch <- corpusCharactersDesc
+}
+
+func (cc *corpusCollector) Collect(ch chan<- prometheus.Metric) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if time.Since(cc.fetched) >= cc.ttl {
+		values, err := corpusStats(context.Background())
+		if err != nil {
+			// 抓取失败时沿用上次的值
+			slog.Error("Failed to collect corpus metrics", "error", err)
+		} else {
+			cc.values, cc.fetched = values, time.Now()
+		}
+	}
+	if cc.values == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(corpusAuthorsDesc, prometheus.GaugeValue, cc.values["poets"])
+	ch <- prometheus.MustNewConstMetric(corpusPoemsDesc, prometheus.GaugeValue, cc.values["poems"])
+	ch <- prometheus.MustNewConstMetric(corpusCharactersDesc, prometheus.GaugeValue, cc.values["words"])
+}
+
+// corpusStats 读取 stats_view 中的作者数、诗作数和字数。
+func corpusStats(ctx context.Context) (map[string]float64, error) {
+	rows, err := query(ctx, "stats_view", "SELECT name, value FROM stats_view")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[string]float64{}
+	for rows.Next() {
+		var name string
+		var value float64
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, rows.Err()
+}
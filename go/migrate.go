@@ -0,0 +1,219 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// migration 是一次数据库结构变更。已执行的版本记录在 schema_migrations 表中，
+// 启动时按版本号顺序执行尚未执行的变更；新增变更只能追加到 migrations 末尾。
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{1, "authors imgUrl", func(tx *sql.Tx) error {
+		// 旧数据库由 爬头像.py 添加该列，新导入的数据库可能没有
+		return addColumn(tx, "Authors", "imgUrl", "TEXT")
+	}},
+	{2, "soft delete", migrateSoftDelete},
+	{3, "revisions", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE revisions (
+			revision_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			actor TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			comment TEXT NOT NULL DEFAULT '',
+			previous TEXT NOT NULL,
+			data TEXT NOT NULL
+		);
+		CREATE INDEX idx_revisions_entity ON revisions (entity, entity_id)`)
+		return err
+	}},
+	{4, "users and api keys", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE users (
+			user_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			role TEXT NOT NULL CHECK (role IN ('reader', 'editor', 'admin')),
+			created_at TEXT NOT NULL
+		);
+		CREATE TABLE api_keys (
+			key_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL REFERENCES users (user_id),
+			name TEXT NOT NULL DEFAULT '',
+			prefix TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL,
+			last_used_at TEXT,
+			revoked_at TEXT
+		)`)
+		return err
+	}},
+	{5, "audit log", func(tx *sql.Tx) error {
+		// 审计记录只允许追加，触发器拒绝修改和删除
+		_, err := tx.Exec(`CREATE TABLE audit_log (
+			audit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			created_at TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			route TEXT NOT NULL,
+			action TEXT NOT NULL,
+			entity TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			before TEXT,
+			after TEXT,
+			client_ip TEXT,
+			request_id TEXT
+		);
+		CREATE INDEX idx_audit_log_entity ON audit_log (entity, entity_id);
+		CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+		CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+		CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+		BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;
+		CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+		BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`)
+		return err
+	}},
+	{6, "submissions", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE submissions (
+			submission_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL CHECK (kind IN ('new_poem', 'poem_correction', 'author_bio')),
+			entity_id INTEGER,
+			changes TEXT NOT NULL,
+			comment TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+			submitted_by TEXT NOT NULL,
+			submitted_at TEXT NOT NULL,
+			reviewed_by TEXT,
+			reviewed_at TEXT,
+			review_comment TEXT,
+			previous TEXT,
+			revision_id INTEGER
+		);
+		CREATE INDEX idx_submissions_status ON submissions (status, submission_id)`)
+		return err
+	}},
+	{7, "poem title index", func(tx *sql.Tx) error {
+		// GraphQL 的相似诗作按诗题查找同题之作
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_poems_title ON Poems (title)`)
+		return err
+	}},
+	{8, "poem sources", migratePoemSources},
+	{9, "poem source hashes", func(tx *sql.Tx) error {
+		// sync 命令记录上次同步时原始数据的摘要，用于区分原始数据的更新和本地修改
+		return addColumn(tx, "Poems", "source_hash", "TEXT")
+	}},
+}
+
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("query schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.version, m.name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", m.version, err)
+		}
+		slog.Info("Applied migration", "version", m.version, "name", m.name)
+	}
+	return nil
+}
+
+// addColumn 在列不存在时为表添加列。
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// migrateSoftDelete 为作者和诗作添加删除标记，并重建统计视图以排除已删除的数据。
+func migrateSoftDelete(tx *sql.Tx) error {
+	for _, table := range []string{"Authors", "Poems"} {
+		if err := addColumn(tx, table, "deleted_at", "TEXT"); err != nil {
+			return err
+		}
+		if err := addColumn(tx, table, "deleted_by", "TEXT"); err != nil {
+			return err
+		}
+	}
+
+	stmts := []string{
+		`CREATE INDEX IF NOT EXISTS idx_poems_author_id ON Poems (author_id)`,
+		`DROP VIEW IF EXISTS stats_view`,
+		`CREATE VIEW stats_view AS
+			SELECT 1 AS id, 'poets' AS name, (SELECT COUNT(*) FROM Authors WHERE deleted_at IS NULL) AS value
+			UNION ALL
+			SELECT 2, 'poems', (SELECT COUNT(*) FROM Poems WHERE deleted_at IS NULL)
+			UNION ALL
+			SELECT 3, 'words', (SELECT COALESCE(SUM(LENGTH(REPLACE(content, char(10), ''))), 0) FROM Poems WHERE deleted_at IS NULL)`,
+		`DROP VIEW IF EXISTS echart_two`,
+		`CREATE VIEW echart_two AS
+			SELECT a.author_id, a.name AS author_name,
+				COUNT(p.poem_id) AS poem_count,
+				COALESCE(SUM(LENGTH(REPLACE(p.content, char(10), ''))), 0) AS word_count
+			FROM Authors a
+			LEFT JOIN Poems p ON p.author_id = a.author_id AND p.deleted_at IS NULL
+			WHERE a.deleted_at IS NULL
+			GROUP BY a.author_id`,
+		`DROP VIEW IF EXISTS data_table`,
+		`CREATE VIEW data_table AS
+			SELECT a.author_id, a.name AS author_name, '唐' AS dynasty,
+				COUNT(p.poem_id) AS poem_count,
+				COALESCE(SUM(LENGTH(REPLACE(p.content, char(10), ''))), 0) AS word_count
+			FROM Authors a
+			LEFT JOIN Poems p ON p.author_id = a.author_id AND p.deleted_at IS NULL
+			WHERE a.deleted_at IS NULL
+			GROUP BY a.author_id`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
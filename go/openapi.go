@@ -0,0 +1,190 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// openAPISpec 是手写的接口文档，新增或修改接口时同步更新 docs/openapi.json
+//
+//go:embed docs/openapi.json
+var openAPISpec []byte
+
+// swaggerInitializer 替换 Swagger UI 自带的初始化脚本，加载本服务的接口文档
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "` + apiPrefix + `/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// openAPIRoutes 注册接口文档和 Swagger UI。只挂在 /api 下，不提供根路径的别名。
+func openAPIRoutes(r *gin.RouterGroup) {
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+	})
+
+	ui := http.FileServer(http.FS(swaggerFiles.FS))
+	r.GET("/docs/*filepath", func(c *gin.Context) {
+		if c.Param("filepath") == "/swagger-initializer.js" {
+			c.Data(http.StatusOK, "text/javascript; charset=utf-8", []byte(swaggerInitializer))
+			return
+		}
+		c.Request.URL.Path = c.Param("filepath")
+		ui.ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// 不需要写入接口文档的路由：Swagger UI 的静态文件
+var undocumentedRoutes = map[string]bool{
+	"GET " + apiPrefix + "/docs/*filepath": true,
+}
+
+// openAPIParameter 是接口文档中的一个参数，$ref 指向 components.parameters
+type openAPIParameter struct {
+	Ref  string `json:"$ref"`
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+// specProblems 检查已注册的路由与 docs/openapi.json 是否一致：每个路由都要写入文档，
+// 文档中的路径参数与路由的参数相同。返回的问题格式为 "GET /api/authors/:id: ..."。
+// 根路径下的旧地址是 /api 的别名，不单独检查。
+func specProblems(routes gin.RoutesInfo) ([]string, error) {
+	var spec struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Parameters map[string]openAPIParameter `json:"parameters"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+	// pathParams 返回参数列表中的路径参数名，$ref 指向的参数一并解析
+	pathParams := func(raw json.RawMessage, names map[string]bool) error {
+		var op struct {
+			Parameters []openAPIParameter `json:"parameters"`
+		}
+		if raw[0] == '[' {
+			if err := json.Unmarshal(raw, &op.Parameters); err != nil {
+				return err
+			}
+		} else if err := json.Unmarshal(raw, &op); err != nil {
+			return err
+		}
+		for _, p := range op.Parameters {
+			if name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/"); ok {
+				resolved, found := spec.Components.Parameters[name]
+				if !found {
+					return fmt.Errorf("unknown parameter %s", p.Ref)
+				}
+				p = resolved
+			}
+			if p.In == "path" {
+				names[p.Name] = true
+			}
+		}
+		return nil
+	}
+
+	var problems []string
+	for _, r := range routes {
+		route := r.Method + " " + r.Path
+		if undocumentedRoutes[route] {
+			continue
+		}
+		path, isAPI := strings.CutPrefix(r.Path, apiPrefix)
+		if !isAPI && !opsRoutes[r.Path] {
+			continue
+		}
+		item := spec.Paths[openAPIPath(path)]
+		op, ok := item[strings.ToLower(r.Method)]
+		if !ok {
+			problems = append(problems, route+": not documented")
+			continue
+		}
+		documented := map[string]bool{}
+		if shared, ok := item["parameters"]; ok {
+			if err := pathParams(shared, documented); err != nil {
+				return nil, fmt.Errorf("%s: %w", route, err)
+			}
+		}
+		if err := pathParams(op, documented); err != nil {
+			return nil, fmt.Errorf("%s: %w", route, err)
+		}
+		for _, s := range strings.Split(path, "/") {
+			if name, ok := strings.CutPrefix(s, ":"); ok {
+				if !documented[name] {
+					problems = append(problems, fmt.Sprintf("%s: path parameter %s not documented", route, name))
+				}
+				delete(documented, name)
+			}
+		}
+		for name := range documented {
+			problems = append(problems, fmt.Sprintf("%s: documented path parameter %s not in route", route, name))
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// opsRoutes 是挂在根路径下的运维接口，其余根路径路由都是 /api 的旧地址
+var opsRoutes = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// openAPIPath 把 gin 的路径参数 :id 转换为 OpenAPI 的 {id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// runOpenAPI 输出接口文档；openapi check 检查路由与文档是否一致，有问题时以非零状态退出。
+func runOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: poetry openapi [check]")
+	}
+	fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "":
+		_, err := os.Stdout.Write(openAPISpec)
+		return err
+	case "check":
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown openapi command %q", fs.Arg(0))
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	problems, err := specProblems(setupRouter().Routes())
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		return fmt.Errorf("%d problems in docs/openapi.json", len(problems))
+	}
+	fmt.Println("All routes are documented")
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+	"poetry/textdiff"
+)
+
+// 每个已注册的路由都要写入 docs/openapi.json，路径参数与路由相同
+func TestOpenAPIDocumentsAllRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	problems, err := specProblems(setupRouter().Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Error(p)
+	}
+}
+
+// schemaTypes 是 docs/openapi.json 中与 Go 结构体对应的 schema
+var schemaTypes = map[string]any{
+	"Author":              Author{},
+	"Poem":                Poem{},
+	"PoemSource":          PoemSource{},
+	"Revision":            Revision{},
+	"Edit":                textdiff.Edit{},
+	"TrashItem":           TrashItem{},
+	"Submission":          Submission{},
+	"AuditRecord":         AuditRecord{},
+	"ExportPoem":          ExportPoem{},
+	"ExportAuthor":        ExportAuthor{},
+	"ChinesePoetryPoem":   chinesePoetryPoem{},
+	"ChinesePoetryAuthor": chinesePoetryAuthor{},
+	"ImportItem":          ImportItem{},
+	"ImportReport":        ImportReport{},
+	"QualityItem":         QualityItem{},
+	"QualityCheck":        QualityCheck{},
+	"QualityReport":       QualityReport{},
+	"Error":               apierror.Envelope{},
+}
+
+// 请求体的 schema 只能包含对应结构体中的字段
+var inputSchemas = map[string]string{
+	"AuthorInput": "Author",
+	"AuthorPatch": "Author",
+	"PoemInput":   "Poem",
+	"PoemPatch":   "Poem",
+}
+
+// routeResponses 是成功时返回 Go 结构体的路由，切片表示分页返回的 data
+var routeResponses = map[string]any{
+	"GET /authors":                []Author{},
+	"GET /authors/{id}":           Author{},
+	"GET /authors/{id}/poems":     []Poem{},
+	"GET /authors/{id}/revisions": []Revision{},
+	"GET /poems":                  []Poem{},
+	"GET /poems/{id}":             Poem{},
+	"GET /poems/by-source/{uuid}": Poem{},
+	"GET /poems/{id}/revisions":   []Revision{},
+	"GET /search/authors":         []Author{},
+	"GET /search/poems":           []Poem{},
+	"GET /reports/quality":        QualityReport{},
+	"GET /trash":                  []TrashItem{},
+	"GET /submissions":            []Submission{},
+	"GET /submissions/{id}":       Submission{},
+	"GET /admin/audit":            []AuditRecord{},
+	"POST /import":                ImportReport{},
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Required   []string                  `json:"required"`
+	Items      *openAPISchema            `json:"items"`
+	AllOf      []*openAPISchema          `json:"allOf"`
+}
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// jsonFields 返回结构体序列化后的字段名，值表示是否带 omitempty
+func jsonFields(typ reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = slices.Contains(strings.Split(opts, ","), "omitempty")
+	}
+	return fields
+}
+
+// schema 的字段与 Go 结构体相同，必填字段不能带 omitempty
+func TestOpenAPISchemas(t *testing.T) {
+	doc := loadOpenAPI(t)
+	for name, v := range schemaTypes {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s: not in docs/openapi.json", name)
+			continue
+		}
+		fields := jsonFields(reflect.TypeOf(v))
+		for f := range fields {
+			if _, ok := schema.Properties[f]; !ok {
+				t.Errorf("schema %s: field %s of %T not documented", name, f, v)
+			}
+		}
+		for p := range schema.Properties {
+			if _, ok := fields[p]; !ok {
+				t.Errorf("schema %s: property %s not in %T", name, p, v)
+			}
+		}
+		for _, r := range schema.Required {
+			if fields[r] {
+				t.Errorf("schema %s: required property %s is omitempty in %T", name, r, v)
+			}
+		}
+	}
+	for name, target := range inputSchemas {
+		fields := jsonFields(reflect.TypeOf(schemaTypes[target]))
+		for p := range doc.Components.Schemas[name].Properties {
+			if _, ok := fields[p]; !ok {
+				t.Errorf("schema %s: property %s not in %s", name, p, target)
+			}
+		}
+	}
+}
+
+// responseSchema 返回成功响应引用的 schema 名，分页响应返回 "[]" 加 data 的元素类型，
+// 没有引用 schemaTypes 中的 schema 时返回空串
+func responseSchema(doc openAPIDoc, raw json.RawMessage) (string, error) {
+	var op struct {
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema *openAPISchema `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(raw, &op); err != nil {
+		return "", err
+	}
+	ref := func(s *openAPISchema) string {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if _, ok := schemaTypes[name]; !ok {
+			return ""
+		}
+		return name
+	}
+	for code, resp := range op.Responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		s := resp.Content["application/json"].Schema
+		switch {
+		case s == nil:
+		case s.Ref != "":
+			return ref(s), nil
+		case len(s.AllOf) == 2 && s.AllOf[0].Ref == "#/components/schemas/Page":
+			if data := s.AllOf[1].Properties["data"]; data != nil && data.Items != nil {
+				if name := ref(data.Items); name != "" {
+					return "[]" + name, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// 每个路由文档中的成功响应与处理函数返回的 Go 结构体一致
+func TestOpenAPIResponses(t *testing.T) {
+	doc := loadOpenAPI(t)
+	names := map[reflect.Type]string{}
+	for name, v := range schemaTypes {
+		names[reflect.TypeOf(v)] = name
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if method == "parameters" || method == "servers" {
+				continue
+			}
+			route := strings.ToUpper(method) + " " + path
+			got, err := responseSchema(doc, raw)
+			if err != nil {
+				t.Fatalf("%s: %v", route, err)
+			}
+			if got == "" {
+				continue
+			}
+			documented[route] = true
+			v, ok := routeResponses[route]
+			if !ok {
+				t.Errorf("%s: responds with %s, add the Go type to routeResponses", route, got)
+				continue
+			}
+			typ, want := reflect.TypeOf(v), ""
+			if typ.Kind() == reflect.Slice {
+				want = "[]" + names[typ.Elem()]
+			} else {
+				want = names[typ]
+			}
+			if got != want {
+				t.Errorf("%s: documented response %s, handler returns %T", route, got, v)
+			}
+		}
+	}
+	for route := range routeResponses {
+		if !documented[route] {
+			t.Errorf("%s: response schema not documented", route)
+		}
+	}
+}
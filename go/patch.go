@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// mergePatch 按 RFC 7386 (JSON Merge Patch) 把 patch 合并到 target 上：
+// 对象逐个成员合并，值为 null 的成员被删除，其他类型整体替换。
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// bodyError 转换读取或解析请求体时的错误，超过 limitRequestSize 的上限时返回 413。
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apierror.TooLarge(apierror.CodeBodyTooLarge, gin.H{"max_bytes": tooLarge.Limit}).WithCause(err)
+	}
+	return apierror.Validation(apierror.CodeInvalidJSON, nil).WithCause(err)
+}
+
+// applyBody 把请求体作为 merge patch 应用到 dst 指向的当前记录上，见 readPatch 和 applyPatch。
+func applyBody(c *gin.Context, dst any, required ...string) error {
+	patch, err := readPatch(c, required...)
+	if err != nil {
+		return err
+	}
+	return applyPatch(dst, patch)
+}
+
+// readPatch 读取请求体中的 merge patch。
+// PUT 请求传入 required，要求这些字段都出现且不为 null；未出现的其余字段保持原值。
+func readPatch(c *gin.Context, required ...string) (map[string]any, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, bodyError(err)
+	}
+	patch, err := decodeJSON(body)
+	if err != nil {
+		return nil, apierror.Validation(apierror.CodeInvalidJSON, nil).WithCause(err)
+	}
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return nil, apierror.Validation(apierror.CodeInvalidJSON, nil)
+	}
+
+	var fe fieldErrors
+	for _, field := range required {
+		if obj[field] == nil {
+			fe = append(fe, FieldError{Field: field, Rule: "required"})
+		}
+	}
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// applyPatch 把 patch 合并到 dst 指向的当前记录上。
+func applyPatch(dst any, patch map[string]any) error {
+	current, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	target, err := decodeJSON(current)
+	if err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+
+	// 先清零，使被 null 删除的字段回到零值
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	if err := json.Unmarshal(merged, dst); err != nil {
+		return apierror.Validation(apierror.CodeInvalidJSON, nil).WithCause(err)
+	}
+	return nil
+}
+
+func decodeJSON(data []byte) (any, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
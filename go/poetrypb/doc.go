@@ -0,0 +1,9 @@
+// Package poetrypb 是 poetry.proto 生成的 gRPC 消息和服务代码，服务端实现在 main 包的 grpc.go 和 grpcservice.go。
+//
+// 生成需要 protoc、protoc-gen-go 和 protoc-gen-go-grpc：
+//
+//	go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.11
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+package poetrypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative poetry.proto
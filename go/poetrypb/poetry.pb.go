@@ -0,0 +1,1961 @@
+// 唐诗数据库的 gRPC 接口，与 REST 接口（docs/API.md）共用数据和校验规则。
+// 修改后在 go 目录下运行 go generate ./poetrypb 重新生成代码。
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: poetry.proto
+
+package poetrypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 作者的诗作的处理方式，同 REST 的 ?poems=
+type DeleteAuthorRequest_PoemsMode int32
+
+const (
+	// 作者仍有诗作时拒绝删除
+	DeleteAuthorRequest_POEMS_MODE_RESTRICT DeleteAuthorRequest_PoemsMode = 0
+	// 连同诗作一起删除
+	DeleteAuthorRequest_POEMS_MODE_CASCADE DeleteAuthorRequest_PoemsMode = 1
+	// 把诗作转给 reassign_to 指定的作者
+	DeleteAuthorRequest_POEMS_MODE_REASSIGN DeleteAuthorRequest_PoemsMode = 2
+)
+
+// Enum value maps for DeleteAuthorRequest_PoemsMode.
+var (
+	DeleteAuthorRequest_PoemsMode_name = map[int32]string{
+		0: "POEMS_MODE_RESTRICT",
+		1: "POEMS_MODE_CASCADE",
+		2: "POEMS_MODE_REASSIGN",
+	}
+	DeleteAuthorRequest_PoemsMode_value = map[string]int32{
+		"POEMS_MODE_RESTRICT": 0,
+		"POEMS_MODE_CASCADE":  1,
+		"POEMS_MODE_REASSIGN": 2,
+	}
+)
+
+func (x DeleteAuthorRequest_PoemsMode) Enum() *DeleteAuthorRequest_PoemsMode {
+	p := new(DeleteAuthorRequest_PoemsMode)
+	*p = x
+	return p
+}
+
+func (x DeleteAuthorRequest_PoemsMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeleteAuthorRequest_PoemsMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_poetry_proto_enumTypes[0].Descriptor()
+}
+
+func (DeleteAuthorRequest_PoemsMode) Type() protoreflect.EnumType {
+	return &file_poetry_proto_enumTypes[0]
+}
+
+func (x DeleteAuthorRequest_PoemsMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeleteAuthorRequest_PoemsMode.Descriptor instead.
+func (DeleteAuthorRequest_PoemsMode) EnumDescriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type Author struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId    int64                  `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// 头像地址
+	ImgUrl string `protobuf:"bytes,4,opt,name=img_url,json=imgUrl,proto3" json:"img_url,omitempty"`
+	// 诗作总数，请求 include.total 时填写
+	TotalPoems int32 `protobuf:"varint,5,opt,name=total_poems,json=totalPoems,proto3" json:"total_poems,omitempty"`
+	// 前几首诗作，请求 include.poems 时填写
+	Poems         []*Poem `protobuf:"bytes,6,rep,name=poems,proto3" json:"poems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Author) Reset() {
+	*x = Author{}
+	mi := &file_poetry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Author) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Author) ProtoMessage() {}
+
+func (x *Author) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Author.ProtoReflect.Descriptor instead.
+func (*Author) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Author) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *Author) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Author) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Author) GetImgUrl() string {
+	if x != nil {
+		return x.ImgUrl
+	}
+	return ""
+}
+
+func (x *Author) GetTotalPoems() int32 {
+	if x != nil {
+		return x.TotalPoems
+	}
+	return 0
+}
+
+func (x *Author) GetPoems() []*Poem {
+	if x != nil {
+		return x.Poems
+	}
+	return nil
+}
+
+type Poem struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PoemId   int64                  `protobuf:"varint,1,opt,name=poem_id,json=poemId,proto3" json:"poem_id,omitempty"`
+	Title    string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	AuthorId int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// 每行一句，以换行分隔
+	Content string `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	// 在原始数据中的出处，通过接口新建的诗作没有
+	Source        *PoemSource `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Poem) Reset() {
+	*x = Poem{}
+	mi := &file_poetry_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Poem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Poem) ProtoMessage() {}
+
+func (x *Poem) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Poem.ProtoReflect.Descriptor instead.
+func (*Poem) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Poem) GetPoemId() int64 {
+	if x != nil {
+		return x.PoemId
+	}
+	return 0
+}
+
+func (x *Poem) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Poem) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *Poem) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Poem) GetSource() *PoemSource {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+// PoemSource 是诗作在 chinese-poetry 原始数据中的出处
+type PoemSource struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 原始数据中的 UUID，唐诗补录.json 中部分诗作没有
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// 如 poet.tang.0.json
+	File string `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	// 在文件中的序号，从 0 开始
+	Index         int32  `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	Notes         string `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoemSource) Reset() {
+	*x = PoemSource{}
+	mi := &file_poetry_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoemSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoemSource) ProtoMessage() {}
+
+func (x *PoemSource) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoemSource.ProtoReflect.Descriptor instead.
+func (*PoemSource) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PoemSource) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PoemSource) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *PoemSource) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PoemSource) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+// AuthorInclude 对应 REST 的 ?include=poems,total
+type AuthorInclude struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Poems         bool                   `protobuf:"varint,1,opt,name=poems,proto3" json:"poems,omitempty"`
+	Total         bool                   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthorInclude) Reset() {
+	*x = AuthorInclude{}
+	mi := &file_poetry_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorInclude) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorInclude) ProtoMessage() {}
+
+func (x *AuthorInclude) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthorInclude.ProtoReflect.Descriptor instead.
+func (*AuthorInclude) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AuthorInclude) GetPoems() bool {
+	if x != nil {
+		return x.Poems
+	}
+	return false
+}
+
+func (x *AuthorInclude) GetTotal() bool {
+	if x != nil {
+		return x.Total
+	}
+	return false
+}
+
+type GetAuthorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId      int64                  `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuthorRequest) Reset() {
+	*x = GetAuthorRequest{}
+	mi := &file_poetry_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuthorRequest) ProtoMessage() {}
+
+func (x *GetAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuthorRequest.ProtoReflect.Descriptor instead.
+func (*GetAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetAuthorRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+type ListAuthorsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 从 1 开始，默认 1
+	Page int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	// 默认 6，最大 100
+	PageSize      int32          `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Include       *AuthorInclude `protobuf:"bytes,3,opt,name=include,proto3" json:"include,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuthorsRequest) Reset() {
+	*x = ListAuthorsRequest{}
+	mi := &file_poetry_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuthorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuthorsRequest) ProtoMessage() {}
+
+func (x *ListAuthorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuthorsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuthorsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListAuthorsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListAuthorsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuthorsRequest) GetInclude() *AuthorInclude {
+	if x != nil {
+		return x.Include
+	}
+	return nil
+}
+
+type SearchAuthorsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 按名字模糊匹配，必填
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Page     int32  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// 不填时同 REST，附带诗作预览和总数
+	Include       *AuthorInclude `protobuf:"bytes,4,opt,name=include,proto3" json:"include,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchAuthorsRequest) Reset() {
+	*x = SearchAuthorsRequest{}
+	mi := &file_poetry_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchAuthorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchAuthorsRequest) ProtoMessage() {}
+
+func (x *SearchAuthorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchAuthorsRequest.ProtoReflect.Descriptor instead.
+func (*SearchAuthorsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchAuthorsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SearchAuthorsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchAuthorsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchAuthorsRequest) GetInclude() *AuthorInclude {
+	if x != nil {
+		return x.Include
+	}
+	return nil
+}
+
+type ListAuthorsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Authors       []*Author              `protobuf:"bytes,4,rep,name=authors,proto3" json:"authors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuthorsResponse) Reset() {
+	*x = ListAuthorsResponse{}
+	mi := &file_poetry_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuthorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuthorsResponse) ProtoMessage() {}
+
+func (x *ListAuthorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuthorsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuthorsResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListAuthorsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListAuthorsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuthorsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListAuthorsResponse) GetAuthors() []*Author {
+	if x != nil {
+		return x.Authors
+	}
+	return nil
+}
+
+type ListAuthorPoemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId      int64                  `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuthorPoemsRequest) Reset() {
+	*x = ListAuthorPoemsRequest{}
+	mi := &file_poetry_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuthorPoemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuthorPoemsRequest) ProtoMessage() {}
+
+func (x *ListAuthorPoemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuthorPoemsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuthorPoemsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListAuthorPoemsRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *ListAuthorPoemsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListAuthorPoemsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type CreateAuthorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	ImgUrl        string                 `protobuf:"bytes,3,opt,name=img_url,json=imgUrl,proto3" json:"img_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAuthorRequest) Reset() {
+	*x = CreateAuthorRequest{}
+	mi := &file_poetry_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAuthorRequest) ProtoMessage() {}
+
+func (x *CreateAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAuthorRequest.ProtoReflect.Descriptor instead.
+func (*CreateAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateAuthorRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAuthorRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateAuthorRequest) GetImgUrl() string {
+	if x != nil {
+		return x.ImgUrl
+	}
+	return ""
+}
+
+// UpdateAuthorRequest 只修改设置了的字段，同 REST 的 PATCH
+type UpdateAuthorRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId    int64                  `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Name        *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Description *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	ImgUrl      *string                `protobuf:"bytes,4,opt,name=img_url,json=imgUrl,proto3,oneof" json:"img_url,omitempty"`
+	// 修改说明，随修订记录保存
+	Comment       string `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAuthorRequest) Reset() {
+	*x = UpdateAuthorRequest{}
+	mi := &file_poetry_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAuthorRequest) ProtoMessage() {}
+
+func (x *UpdateAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAuthorRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateAuthorRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *UpdateAuthorRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateAuthorRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateAuthorRequest) GetImgUrl() string {
+	if x != nil && x.ImgUrl != nil {
+		return *x.ImgUrl
+	}
+	return ""
+}
+
+func (x *UpdateAuthorRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type UpdateAuthorResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Author *Author                `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	// 字段没有变化时为 0
+	RevisionId    int64 `protobuf:"varint,2,opt,name=revision_id,json=revisionId,proto3" json:"revision_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAuthorResponse) Reset() {
+	*x = UpdateAuthorResponse{}
+	mi := &file_poetry_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAuthorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAuthorResponse) ProtoMessage() {}
+
+func (x *UpdateAuthorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAuthorResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAuthorResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateAuthorResponse) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *UpdateAuthorResponse) GetRevisionId() int64 {
+	if x != nil {
+		return x.RevisionId
+	}
+	return 0
+}
+
+type DeleteAuthorRequest struct {
+	state         protoimpl.MessageState        `protogen:"open.v1"`
+	AuthorId      int64                         `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Poems         DeleteAuthorRequest_PoemsMode `protobuf:"varint,2,opt,name=poems,proto3,enum=poetry.v1.DeleteAuthorRequest_PoemsMode" json:"poems,omitempty"`
+	ReassignTo    int64                         `protobuf:"varint,3,opt,name=reassign_to,json=reassignTo,proto3" json:"reassign_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAuthorRequest) Reset() {
+	*x = DeleteAuthorRequest{}
+	mi := &file_poetry_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAuthorRequest) ProtoMessage() {}
+
+func (x *DeleteAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAuthorRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteAuthorRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *DeleteAuthorRequest) GetPoems() DeleteAuthorRequest_PoemsMode {
+	if x != nil {
+		return x.Poems
+	}
+	return DeleteAuthorRequest_POEMS_MODE_RESTRICT
+}
+
+func (x *DeleteAuthorRequest) GetReassignTo() int64 {
+	if x != nil {
+		return x.ReassignTo
+	}
+	return 0
+}
+
+type DeleteAuthorResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PoemsDeleted    int64                  `protobuf:"varint,1,opt,name=poems_deleted,json=poemsDeleted,proto3" json:"poems_deleted,omitempty"`
+	PoemsReassigned int64                  `protobuf:"varint,2,opt,name=poems_reassigned,json=poemsReassigned,proto3" json:"poems_reassigned,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteAuthorResponse) Reset() {
+	*x = DeleteAuthorResponse{}
+	mi := &file_poetry_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAuthorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAuthorResponse) ProtoMessage() {}
+
+func (x *DeleteAuthorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAuthorResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAuthorResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteAuthorResponse) GetPoemsDeleted() int64 {
+	if x != nil {
+		return x.PoemsDeleted
+	}
+	return 0
+}
+
+func (x *DeleteAuthorResponse) GetPoemsReassigned() int64 {
+	if x != nil {
+		return x.PoemsReassigned
+	}
+	return 0
+}
+
+type GetPoemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoemId        int64                  `protobuf:"varint,1,opt,name=poem_id,json=poemId,proto3" json:"poem_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPoemRequest) Reset() {
+	*x = GetPoemRequest{}
+	mi := &file_poetry_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPoemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPoemRequest) ProtoMessage() {}
+
+func (x *GetPoemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPoemRequest.ProtoReflect.Descriptor instead.
+func (*GetPoemRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetPoemRequest) GetPoemId() int64 {
+	if x != nil {
+		return x.PoemId
+	}
+	return 0
+}
+
+type ListPoemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPoemsRequest) Reset() {
+	*x = ListPoemsRequest{}
+	mi := &file_poetry_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPoemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPoemsRequest) ProtoMessage() {}
+
+func (x *ListPoemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPoemsRequest.ProtoReflect.Descriptor instead.
+func (*ListPoemsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListPoemsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPoemsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type SearchPoemsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 按标题或内容模糊匹配，必填
+	Text          string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Page          int32  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPoemsRequest) Reset() {
+	*x = SearchPoemsRequest{}
+	mi := &file_poetry_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPoemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPoemsRequest) ProtoMessage() {}
+
+func (x *SearchPoemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPoemsRequest.ProtoReflect.Descriptor instead.
+func (*SearchPoemsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SearchPoemsRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SearchPoemsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchPoemsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListPoemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Poems         []*Poem                `protobuf:"bytes,4,rep,name=poems,proto3" json:"poems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPoemsResponse) Reset() {
+	*x = ListPoemsResponse{}
+	mi := &file_poetry_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPoemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPoemsResponse) ProtoMessage() {}
+
+func (x *ListPoemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPoemsResponse.ProtoReflect.Descriptor instead.
+func (*ListPoemsResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListPoemsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPoemsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListPoemsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListPoemsResponse) GetPoems() []*Poem {
+	if x != nil {
+		return x.Poems
+	}
+	return nil
+}
+
+type CreatePoemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePoemRequest) Reset() {
+	*x = CreatePoemRequest{}
+	mi := &file_poetry_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePoemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePoemRequest) ProtoMessage() {}
+
+func (x *CreatePoemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePoemRequest.ProtoReflect.Descriptor instead.
+func (*CreatePoemRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreatePoemRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreatePoemRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *CreatePoemRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// UpdatePoemRequest 只修改设置了的字段，同 REST 的 PATCH
+type UpdatePoemRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	PoemId   int64                  `protobuf:"varint,1,opt,name=poem_id,json=poemId,proto3" json:"poem_id,omitempty"`
+	Title    *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	AuthorId *int64                 `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3,oneof" json:"author_id,omitempty"`
+	Content  *string                `protobuf:"bytes,4,opt,name=content,proto3,oneof" json:"content,omitempty"`
+	// 修改说明，随修订记录保存
+	Comment       string `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePoemRequest) Reset() {
+	*x = UpdatePoemRequest{}
+	mi := &file_poetry_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePoemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePoemRequest) ProtoMessage() {}
+
+func (x *UpdatePoemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePoemRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePoemRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdatePoemRequest) GetPoemId() int64 {
+	if x != nil {
+		return x.PoemId
+	}
+	return 0
+}
+
+func (x *UpdatePoemRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdatePoemRequest) GetAuthorId() int64 {
+	if x != nil && x.AuthorId != nil {
+		return *x.AuthorId
+	}
+	return 0
+}
+
+func (x *UpdatePoemRequest) GetContent() string {
+	if x != nil && x.Content != nil {
+		return *x.Content
+	}
+	return ""
+}
+
+func (x *UpdatePoemRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type UpdatePoemResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Poem  *Poem                  `protobuf:"bytes,1,opt,name=poem,proto3" json:"poem,omitempty"`
+	// 字段没有变化时为 0
+	RevisionId    int64 `protobuf:"varint,2,opt,name=revision_id,json=revisionId,proto3" json:"revision_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePoemResponse) Reset() {
+	*x = UpdatePoemResponse{}
+	mi := &file_poetry_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePoemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePoemResponse) ProtoMessage() {}
+
+func (x *UpdatePoemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePoemResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePoemResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdatePoemResponse) GetPoem() *Poem {
+	if x != nil {
+		return x.Poem
+	}
+	return nil
+}
+
+func (x *UpdatePoemResponse) GetRevisionId() int64 {
+	if x != nil {
+		return x.RevisionId
+	}
+	return 0
+}
+
+type DeletePoemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoemId        int64                  `protobuf:"varint,1,opt,name=poem_id,json=poemId,proto3" json:"poem_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePoemRequest) Reset() {
+	*x = DeletePoemRequest{}
+	mi := &file_poetry_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePoemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePoemRequest) ProtoMessage() {}
+
+func (x *DeletePoemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePoemRequest.ProtoReflect.Descriptor instead.
+func (*DeletePoemRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeletePoemRequest) GetPoemId() int64 {
+	if x != nil {
+		return x.PoemId
+	}
+	return 0
+}
+
+type DeletePoemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePoemResponse) Reset() {
+	*x = DeletePoemResponse{}
+	mi := &file_poetry_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePoemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePoemResponse) ProtoMessage() {}
+
+func (x *DeletePoemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePoemResponse.ProtoReflect.Descriptor instead.
+func (*DeletePoemResponse) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{22}
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_poetry_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{23}
+}
+
+type Stats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Poets         int32                  `protobuf:"varint,1,opt,name=poets,proto3" json:"poets,omitempty"`
+	Poems         int32                  `protobuf:"varint,2,opt,name=poems,proto3" json:"poems,omitempty"`
+	Words         int64                  `protobuf:"varint,3,opt,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_poetry_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Stats) GetPoets() int32 {
+	if x != nil {
+		return x.Poets
+	}
+	return 0
+}
+
+func (x *Stats) GetPoems() int32 {
+	if x != nil {
+		return x.Poems
+	}
+	return 0
+}
+
+func (x *Stats) GetWords() int64 {
+	if x != nil {
+		return x.Words
+	}
+	return 0
+}
+
+type StreamAuthorsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 按名字模糊匹配，不填时返回全部作者
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 只返回 author_id 大于该值的作者，用于断点续传
+	AfterId int64 `protobuf:"varint,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	// 为 true 时填写每位作者的 total_poems
+	IncludeTotal  bool `protobuf:"varint,3,opt,name=include_total,json=includeTotal,proto3" json:"include_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAuthorsRequest) Reset() {
+	*x = StreamAuthorsRequest{}
+	mi := &file_poetry_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAuthorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAuthorsRequest) ProtoMessage() {}
+
+func (x *StreamAuthorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAuthorsRequest.ProtoReflect.Descriptor instead.
+func (*StreamAuthorsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StreamAuthorsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StreamAuthorsRequest) GetAfterId() int64 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+func (x *StreamAuthorsRequest) GetIncludeTotal() bool {
+	if x != nil {
+		return x.IncludeTotal
+	}
+	return false
+}
+
+type StreamPoemsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 只返回该作者的诗作
+	AuthorId int64 `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// 按标题或内容模糊匹配
+	Text string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	// 只返回 poem_id 大于该值的诗作，用于断点续传
+	AfterId       int64 `protobuf:"varint,3,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPoemsRequest) Reset() {
+	*x = StreamPoemsRequest{}
+	mi := &file_poetry_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPoemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPoemsRequest) ProtoMessage() {}
+
+func (x *StreamPoemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poetry_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPoemsRequest.ProtoReflect.Descriptor instead.
+func (*StreamPoemsRequest) Descriptor() ([]byte, []int) {
+	return file_poetry_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StreamPoemsRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *StreamPoemsRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *StreamPoemsRequest) GetAfterId() int64 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+var File_poetry_proto protoreflect.FileDescriptor
+
+const file_poetry_proto_rawDesc = "" +
+	"\n" +
+	"\fpoetry.proto\x12\tpoetry.v1\"\xbc\x01\n" +
+	"\x06Author\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x17\n" +
+	"\aimg_url\x18\x04 \x01(\tR\x06imgUrl\x12\x1f\n" +
+	"\vtotal_poems\x18\x05 \x01(\x05R\n" +
+	"totalPoems\x12%\n" +
+	"\x05poems\x18\x06 \x03(\v2\x0f.poetry.v1.PoemR\x05poems\"\x9b\x01\n" +
+	"\x04Poem\x12\x17\n" +
+	"\apoem_id\x18\x01 \x01(\x03R\x06poemId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12-\n" +
+	"\x06source\x18\x05 \x01(\v2\x15.poetry.v1.PoemSourceR\x06source\"\\\n" +
+	"\n" +
+	"PoemSource\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12\x14\n" +
+	"\x05index\x18\x03 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notes\";\n" +
+	"\rAuthorInclude\x12\x14\n" +
+	"\x05poems\x18\x01 \x01(\bR\x05poems\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\bR\x05total\"/\n" +
+	"\x10GetAuthorRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\"y\n" +
+	"\x12ListAuthorsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x122\n" +
+	"\ainclude\x18\x03 \x01(\v2\x18.poetry.v1.AuthorIncludeR\ainclude\"\x8f\x01\n" +
+	"\x14SearchAuthorsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x122\n" +
+	"\ainclude\x18\x04 \x01(\v2\x18.poetry.v1.AuthorIncludeR\ainclude\"\x89\x01\n" +
+	"\x13ListAuthorsResponse\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12+\n" +
+	"\aauthors\x18\x04 \x03(\v2\x11.poetry.v1.AuthorR\aauthors\"f\n" +
+	"\x16ListAuthorPoemsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"d\n" +
+	"\x13CreateAuthorRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x17\n" +
+	"\aimg_url\x18\x03 \x01(\tR\x06imgUrl\"\xcf\x01\n" +
+	"\x13UpdateAuthorRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x12\x1c\n" +
+	"\aimg_url\x18\x04 \x01(\tH\x02R\x06imgUrl\x88\x01\x01\x12\x18\n" +
+	"\acomment\x18\x05 \x01(\tR\acommentB\a\n" +
+	"\x05_nameB\x0e\n" +
+	"\f_descriptionB\n" +
+	"\n" +
+	"\b_img_url\"b\n" +
+	"\x14UpdateAuthorResponse\x12)\n" +
+	"\x06author\x18\x01 \x01(\v2\x11.poetry.v1.AuthorR\x06author\x12\x1f\n" +
+	"\vrevision_id\x18\x02 \x01(\x03R\n" +
+	"revisionId\"\xea\x01\n" +
+	"\x13DeleteAuthorRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12>\n" +
+	"\x05poems\x18\x02 \x01(\x0e2(.poetry.v1.DeleteAuthorRequest.PoemsModeR\x05poems\x12\x1f\n" +
+	"\vreassign_to\x18\x03 \x01(\x03R\n" +
+	"reassignTo\"U\n" +
+	"\tPoemsMode\x12\x17\n" +
+	"\x13POEMS_MODE_RESTRICT\x10\x00\x12\x16\n" +
+	"\x12POEMS_MODE_CASCADE\x10\x01\x12\x17\n" +
+	"\x13POEMS_MODE_REASSIGN\x10\x02\"f\n" +
+	"\x14DeleteAuthorResponse\x12#\n" +
+	"\rpoems_deleted\x18\x01 \x01(\x03R\fpoemsDeleted\x12)\n" +
+	"\x10poems_reassigned\x18\x02 \x01(\x03R\x0fpoemsReassigned\")\n" +
+	"\x0eGetPoemRequest\x12\x17\n" +
+	"\apoem_id\x18\x01 \x01(\x03R\x06poemId\"C\n" +
+	"\x10ListPoemsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"Y\n" +
+	"\x12SearchPoemsRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x81\x01\n" +
+	"\x11ListPoemsResponse\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12%\n" +
+	"\x05poems\x18\x04 \x03(\v2\x0f.poetry.v1.PoemR\x05poems\"`\n" +
+	"\x11CreatePoemRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\"\xc6\x01\n" +
+	"\x11UpdatePoemRequest\x12\x17\n" +
+	"\apoem_id\x18\x01 \x01(\x03R\x06poemId\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12 \n" +
+	"\tauthor_id\x18\x03 \x01(\x03H\x01R\bauthorId\x88\x01\x01\x12\x1d\n" +
+	"\acontent\x18\x04 \x01(\tH\x02R\acontent\x88\x01\x01\x12\x18\n" +
+	"\acomment\x18\x05 \x01(\tR\acommentB\b\n" +
+	"\x06_titleB\f\n" +
+	"\n" +
+	"_author_idB\n" +
+	"\n" +
+	"\b_content\"Z\n" +
+	"\x12UpdatePoemResponse\x12#\n" +
+	"\x04poem\x18\x01 \x01(\v2\x0f.poetry.v1.PoemR\x04poem\x12\x1f\n" +
+	"\vrevision_id\x18\x02 \x01(\x03R\n" +
+	"revisionId\",\n" +
+	"\x11DeletePoemRequest\x12\x17\n" +
+	"\apoem_id\x18\x01 \x01(\x03R\x06poemId\"\x14\n" +
+	"\x12DeletePoemResponse\"\x11\n" +
+	"\x0fGetStatsRequest\"I\n" +
+	"\x05Stats\x12\x14\n" +
+	"\x05poets\x18\x01 \x01(\x05R\x05poets\x12\x14\n" +
+	"\x05poems\x18\x02 \x01(\x05R\x05poems\x12\x14\n" +
+	"\x05words\x18\x03 \x01(\x03R\x05words\"j\n" +
+	"\x14StreamAuthorsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\x03R\aafterId\x12#\n" +
+	"\rinclude_total\x18\x03 \x01(\bR\fincludeTotal\"`\n" +
+	"\x12StreamPoemsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x19\n" +
+	"\bafter_id\x18\x03 \x01(\x03R\aafterId2\x85\t\n" +
+	"\rPoetryService\x12;\n" +
+	"\tGetAuthor\x12\x1b.poetry.v1.GetAuthorRequest\x1a\x11.poetry.v1.Author\x12L\n" +
+	"\vListAuthors\x12\x1d.poetry.v1.ListAuthorsRequest\x1a\x1e.poetry.v1.ListAuthorsResponse\x12P\n" +
+	"\rSearchAuthors\x12\x1f.poetry.v1.SearchAuthorsRequest\x1a\x1e.poetry.v1.ListAuthorsResponse\x12R\n" +
+	"\x0fListAuthorPoems\x12!.poetry.v1.ListAuthorPoemsRequest\x1a\x1c.poetry.v1.ListPoemsResponse\x12A\n" +
+	"\fCreateAuthor\x12\x1e.poetry.v1.CreateAuthorRequest\x1a\x11.poetry.v1.Author\x12O\n" +
+	"\fUpdateAuthor\x12\x1e.poetry.v1.UpdateAuthorRequest\x1a\x1f.poetry.v1.UpdateAuthorResponse\x12O\n" +
+	"\fDeleteAuthor\x12\x1e.poetry.v1.DeleteAuthorRequest\x1a\x1f.poetry.v1.DeleteAuthorResponse\x125\n" +
+	"\aGetPoem\x12\x19.poetry.v1.GetPoemRequest\x1a\x0f.poetry.v1.Poem\x12F\n" +
+	"\tListPoems\x12\x1b.poetry.v1.ListPoemsRequest\x1a\x1c.poetry.v1.ListPoemsResponse\x12J\n" +
+	"\vSearchPoems\x12\x1d.poetry.v1.SearchPoemsRequest\x1a\x1c.poetry.v1.ListPoemsResponse\x12;\n" +
+	"\n" +
+	"CreatePoem\x12\x1c.poetry.v1.CreatePoemRequest\x1a\x0f.poetry.v1.Poem\x12I\n" +
+	"\n" +
+	"UpdatePoem\x12\x1c.poetry.v1.UpdatePoemRequest\x1a\x1d.poetry.v1.UpdatePoemResponse\x12I\n" +
+	"\n" +
+	"DeletePoem\x12\x1c.poetry.v1.DeletePoemRequest\x1a\x1d.poetry.v1.DeletePoemResponse\x128\n" +
+	"\bGetStats\x12\x1a.poetry.v1.GetStatsRequest\x1a\x10.poetry.v1.Stats\x12E\n" +
+	"\rStreamAuthors\x12\x1f.poetry.v1.StreamAuthorsRequest\x1a\x11.poetry.v1.Author0\x01\x12?\n" +
+	"\vStreamPoems\x12\x1d.poetry.v1.StreamPoemsRequest\x1a\x0f.poetry.v1.Poem0\x01B\x11Z\x0fpoetry/poetrypbb\x06proto3"
+
+var (
+	file_poetry_proto_rawDescOnce sync.Once
+	file_poetry_proto_rawDescData []byte
+)
+
+func file_poetry_proto_rawDescGZIP() []byte {
+	file_poetry_proto_rawDescOnce.Do(func() {
+		file_poetry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_poetry_proto_rawDesc), len(file_poetry_proto_rawDesc)))
+	})
+	return file_poetry_proto_rawDescData
+}
+
+var file_poetry_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_poetry_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_poetry_proto_goTypes = []any{
+	(DeleteAuthorRequest_PoemsMode)(0), // 0: poetry.v1.DeleteAuthorRequest.PoemsMode
+	(*Author)(nil),                     // 1: poetry.v1.Author
+	(*Poem)(nil),                       // 2: poetry.v1.Poem
+	(*PoemSource)(nil),                 // 3: poetry.v1.PoemSource
+	(*AuthorInclude)(nil),              // 4: poetry.v1.AuthorInclude
+	(*GetAuthorRequest)(nil),           // 5: poetry.v1.GetAuthorRequest
+	(*ListAuthorsRequest)(nil),         // 6: poetry.v1.ListAuthorsRequest
+	(*SearchAuthorsRequest)(nil),       // 7: poetry.v1.SearchAuthorsRequest
+	(*ListAuthorsResponse)(nil),        // 8: poetry.v1.ListAuthorsResponse
+	(*ListAuthorPoemsRequest)(nil),     // 9: poetry.v1.ListAuthorPoemsRequest
+	(*CreateAuthorRequest)(nil),        // 10: poetry.v1.CreateAuthorRequest
+	(*UpdateAuthorRequest)(nil),        // 11: poetry.v1.UpdateAuthorRequest
+	(*UpdateAuthorResponse)(nil),       // 12: poetry.v1.UpdateAuthorResponse
+	(*DeleteAuthorRequest)(nil),        // 13: poetry.v1.DeleteAuthorRequest
+	(*DeleteAuthorResponse)(nil),       // 14: poetry.v1.DeleteAuthorResponse
+	(*GetPoemRequest)(nil),             // 15: poetry.v1.GetPoemRequest
+	(*ListPoemsRequest)(nil),           // 16: poetry.v1.ListPoemsRequest
+	(*SearchPoemsRequest)(nil),         // 17: poetry.v1.SearchPoemsRequest
+	(*ListPoemsResponse)(nil),          // 18: poetry.v1.ListPoemsResponse
+	(*CreatePoemRequest)(nil),          // 19: poetry.v1.CreatePoemRequest
+	(*UpdatePoemRequest)(nil),          // 20: poetry.v1.UpdatePoemRequest
+	(*UpdatePoemResponse)(nil),         // 21: poetry.v1.UpdatePoemResponse
+	(*DeletePoemRequest)(nil),          // 22: poetry.v1.DeletePoemRequest
+	(*DeletePoemResponse)(nil),         // 23: poetry.v1.DeletePoemResponse
+	(*GetStatsRequest)(nil),            // 24: poetry.v1.GetStatsRequest
+	(*Stats)(nil),                      // 25: poetry.v1.Stats
+	(*StreamAuthorsRequest)(nil),       // 26: poetry.v1.StreamAuthorsRequest
+	(*StreamPoemsRequest)(nil),         // 27: poetry.v1.StreamPoemsRequest
+}
+var file_poetry_proto_depIdxs = []int32{
+	2,  // 0: poetry.v1.Author.poems:type_name -> poetry.v1.Poem
+	3,  // 1: poetry.v1.Poem.source:type_name -> poetry.v1.PoemSource
+	4,  // 2: poetry.v1.ListAuthorsRequest.include:type_name -> poetry.v1.AuthorInclude
+	4,  // 3: poetry.v1.SearchAuthorsRequest.include:type_name -> poetry.v1.AuthorInclude
+	1,  // 4: poetry.v1.ListAuthorsResponse.authors:type_name -> poetry.v1.Author
+	1,  // 5: poetry.v1.UpdateAuthorResponse.author:type_name -> poetry.v1.Author
+	0,  // 6: poetry.v1.DeleteAuthorRequest.poems:type_name -> poetry.v1.DeleteAuthorRequest.PoemsMode
+	2,  // 7: poetry.v1.ListPoemsResponse.poems:type_name -> poetry.v1.Poem
+	2,  // 8: poetry.v1.UpdatePoemResponse.poem:type_name -> poetry.v1.Poem
+	5,  // 9: poetry.v1.PoetryService.GetAuthor:input_type -> poetry.v1.GetAuthorRequest
+	6,  // 10: poetry.v1.PoetryService.ListAuthors:input_type -> poetry.v1.ListAuthorsRequest
+	7,  // 11: poetry.v1.PoetryService.SearchAuthors:input_type -> poetry.v1.SearchAuthorsRequest
+	9,  // 12: poetry.v1.PoetryService.ListAuthorPoems:input_type -> poetry.v1.ListAuthorPoemsRequest
+	10, // 13: poetry.v1.PoetryService.CreateAuthor:input_type -> poetry.v1.CreateAuthorRequest
+	11, // 14: poetry.v1.PoetryService.UpdateAuthor:input_type -> poetry.v1.UpdateAuthorRequest
+	13, // 15: poetry.v1.PoetryService.DeleteAuthor:input_type -> poetry.v1.DeleteAuthorRequest
+	15, // 16: poetry.v1.PoetryService.GetPoem:input_type -> poetry.v1.GetPoemRequest
+	16, // 17: poetry.v1.PoetryService.ListPoems:input_type -> poetry.v1.ListPoemsRequest
+	17, // 18: poetry.v1.PoetryService.SearchPoems:input_type -> poetry.v1.SearchPoemsRequest
+	19, // 19: poetry.v1.PoetryService.CreatePoem:input_type -> poetry.v1.CreatePoemRequest
+	20, // 20: poetry.v1.PoetryService.UpdatePoem:input_type -> poetry.v1.UpdatePoemRequest
+	22, // 21: poetry.v1.PoetryService.DeletePoem:input_type -> poetry.v1.DeletePoemRequest
+	24, // 22: poetry.v1.PoetryService.GetStats:input_type -> poetry.v1.GetStatsRequest
+	26, // 23: poetry.v1.PoetryService.StreamAuthors:input_type -> poetry.v1.StreamAuthorsRequest
+	27, // 24: poetry.v1.PoetryService.StreamPoems:input_type -> poetry.v1.StreamPoemsRequest
+	1,  // 25: poetry.v1.PoetryService.GetAuthor:output_type -> poetry.v1.Author
+	8,  // 26: poetry.v1.PoetryService.ListAuthors:output_type -> poetry.v1.ListAuthorsResponse
+	8,  // 27: poetry.v1.PoetryService.SearchAuthors:output_type -> poetry.v1.ListAuthorsResponse
+	18, // 28: poetry.v1.PoetryService.ListAuthorPoems:output_type -> poetry.v1.ListPoemsResponse
+	1,  // 29: poetry.v1.PoetryService.CreateAuthor:output_type -> poetry.v1.Author
+	12, // 30: poetry.v1.PoetryService.UpdateAuthor:output_type -> poetry.v1.UpdateAuthorResponse
+	14, // 31: poetry.v1.PoetryService.DeleteAuthor:output_type -> poetry.v1.DeleteAuthorResponse
+	2,  // 32: poetry.v1.PoetryService.GetPoem:output_type -> poetry.v1.Poem
+	18, // 33: poetry.v1.PoetryService.ListPoems:output_type -> poetry.v1.ListPoemsResponse
+	18, // 34: poetry.v1.PoetryService.SearchPoems:output_type -> poetry.v1.ListPoemsResponse
+	2,  // 35: poetry.v1.PoetryService.CreatePoem:output_type -> poetry.v1.Poem
+	21, // 36: poetry.v1.PoetryService.UpdatePoem:output_type -> poetry.v1.UpdatePoemResponse
+	23, // 37: poetry.v1.PoetryService.DeletePoem:output_type -> poetry.v1.DeletePoemResponse
+	25, // 38: poetry.v1.PoetryService.GetStats:output_type -> poetry.v1.Stats
+	1,  // 39: poetry.v1.PoetryService.StreamAuthors:output_type -> poetry.v1.Author
+	2,  // 40: poetry.v1.PoetryService.StreamPoems:output_type -> poetry.v1.Poem
+	25, // [25:41] is the sub-list for method output_type
+	9,  // [9:25] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_poetry_proto_init() }
+func file_poetry_proto_init() {
+	if File_poetry_proto != nil {
+		return
+	}
+	file_poetry_proto_msgTypes[10].OneofWrappers = []any{}
+	file_poetry_proto_msgTypes[19].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_poetry_proto_rawDesc), len(file_poetry_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_poetry_proto_goTypes,
+		DependencyIndexes: file_poetry_proto_depIdxs,
+		EnumInfos:         file_poetry_proto_enumTypes,
+		MessageInfos:      file_poetry_proto_msgTypes,
+	}.Build()
+	File_poetry_proto = out.File
+	file_poetry_proto_goTypes = nil
+	file_poetry_proto_depIdxs = nil
+}
@@ -0,0 +1,713 @@
+// 唐诗数据库的 gRPC 接口，与 REST 接口（docs/API.md）共用数据和校验规则。
+// 修改后在 go 目录下运行 go generate ./poetrypb 重新生成代码。
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: poetry.proto
+
+package poetrypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PoetryService_GetAuthor_FullMethodName       = "/poetry.v1.PoetryService/GetAuthor"
+	PoetryService_ListAuthors_FullMethodName     = "/poetry.v1.PoetryService/ListAuthors"
+	PoetryService_SearchAuthors_FullMethodName   = "/poetry.v1.PoetryService/SearchAuthors"
+	PoetryService_ListAuthorPoems_FullMethodName = "/poetry.v1.PoetryService/ListAuthorPoems"
+	PoetryService_CreateAuthor_FullMethodName    = "/poetry.v1.PoetryService/CreateAuthor"
+	PoetryService_UpdateAuthor_FullMethodName    = "/poetry.v1.PoetryService/UpdateAuthor"
+	PoetryService_DeleteAuthor_FullMethodName    = "/poetry.v1.PoetryService/DeleteAuthor"
+	PoetryService_GetPoem_FullMethodName         = "/poetry.v1.PoetryService/GetPoem"
+	PoetryService_ListPoems_FullMethodName       = "/poetry.v1.PoetryService/ListPoems"
+	PoetryService_SearchPoems_FullMethodName     = "/poetry.v1.PoetryService/SearchPoems"
+	PoetryService_CreatePoem_FullMethodName      = "/poetry.v1.PoetryService/CreatePoem"
+	PoetryService_UpdatePoem_FullMethodName      = "/poetry.v1.PoetryService/UpdatePoem"
+	PoetryService_DeletePoem_FullMethodName      = "/poetry.v1.PoetryService/DeletePoem"
+	PoetryService_GetStats_FullMethodName        = "/poetry.v1.PoetryService/GetStats"
+	PoetryService_StreamAuthors_FullMethodName   = "/poetry.v1.PoetryService/StreamAuthors"
+	PoetryService_StreamPoems_FullMethodName     = "/poetry.v1.PoetryService/StreamPoems"
+)
+
+// PoetryServiceClient is the client API for PoetryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PoetryService 对应 REST 的作者、诗作、搜索和统计接口，另有不分页的流式导出。
+// 读接口允许匿名调用；写接口要求 editor 及以上角色，凭据通过 metadata 传入：
+// x-api-key: <API Key> 或 authorization: Bearer <JWT>。
+type PoetryServiceClient interface {
+	GetAuthor(ctx context.Context, in *GetAuthorRequest, opts ...grpc.CallOption) (*Author, error)
+	ListAuthors(ctx context.Context, in *ListAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error)
+	SearchAuthors(ctx context.Context, in *SearchAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error)
+	ListAuthorPoems(ctx context.Context, in *ListAuthorPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error)
+	CreateAuthor(ctx context.Context, in *CreateAuthorRequest, opts ...grpc.CallOption) (*Author, error)
+	UpdateAuthor(ctx context.Context, in *UpdateAuthorRequest, opts ...grpc.CallOption) (*UpdateAuthorResponse, error)
+	DeleteAuthor(ctx context.Context, in *DeleteAuthorRequest, opts ...grpc.CallOption) (*DeleteAuthorResponse, error)
+	GetPoem(ctx context.Context, in *GetPoemRequest, opts ...grpc.CallOption) (*Poem, error)
+	ListPoems(ctx context.Context, in *ListPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error)
+	SearchPoems(ctx context.Context, in *SearchPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error)
+	CreatePoem(ctx context.Context, in *CreatePoemRequest, opts ...grpc.CallOption) (*Poem, error)
+	UpdatePoem(ctx context.Context, in *UpdatePoemRequest, opts ...grpc.CallOption) (*UpdatePoemResponse, error)
+	DeletePoem(ctx context.Context, in *DeletePoemRequest, opts ...grpc.CallOption) (*DeletePoemResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+	// 按 author_id 顺序返回全部符合条件的作者
+	StreamAuthors(ctx context.Context, in *StreamAuthorsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Author], error)
+	// 按 poem_id 顺序返回全部符合条件的诗作
+	StreamPoems(ctx context.Context, in *StreamPoemsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Poem], error)
+}
+
+type poetryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPoetryServiceClient(cc grpc.ClientConnInterface) PoetryServiceClient {
+	return &poetryServiceClient{cc}
+}
+
+func (c *poetryServiceClient) GetAuthor(ctx context.Context, in *GetAuthorRequest, opts ...grpc.CallOption) (*Author, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Author)
+	err := c.cc.Invoke(ctx, PoetryService_GetAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) ListAuthors(ctx context.Context, in *ListAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuthorsResponse)
+	err := c.cc.Invoke(ctx, PoetryService_ListAuthors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) SearchAuthors(ctx context.Context, in *SearchAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuthorsResponse)
+	err := c.cc.Invoke(ctx, PoetryService_SearchAuthors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) ListAuthorPoems(ctx context.Context, in *ListAuthorPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPoemsResponse)
+	err := c.cc.Invoke(ctx, PoetryService_ListAuthorPoems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) CreateAuthor(ctx context.Context, in *CreateAuthorRequest, opts ...grpc.CallOption) (*Author, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Author)
+	err := c.cc.Invoke(ctx, PoetryService_CreateAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) UpdateAuthor(ctx context.Context, in *UpdateAuthorRequest, opts ...grpc.CallOption) (*UpdateAuthorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAuthorResponse)
+	err := c.cc.Invoke(ctx, PoetryService_UpdateAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) DeleteAuthor(ctx context.Context, in *DeleteAuthorRequest, opts ...grpc.CallOption) (*DeleteAuthorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAuthorResponse)
+	err := c.cc.Invoke(ctx, PoetryService_DeleteAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) GetPoem(ctx context.Context, in *GetPoemRequest, opts ...grpc.CallOption) (*Poem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poem)
+	err := c.cc.Invoke(ctx, PoetryService_GetPoem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) ListPoems(ctx context.Context, in *ListPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPoemsResponse)
+	err := c.cc.Invoke(ctx, PoetryService_ListPoems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) SearchPoems(ctx context.Context, in *SearchPoemsRequest, opts ...grpc.CallOption) (*ListPoemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPoemsResponse)
+	err := c.cc.Invoke(ctx, PoetryService_SearchPoems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) CreatePoem(ctx context.Context, in *CreatePoemRequest, opts ...grpc.CallOption) (*Poem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poem)
+	err := c.cc.Invoke(ctx, PoetryService_CreatePoem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) UpdatePoem(ctx context.Context, in *UpdatePoemRequest, opts ...grpc.CallOption) (*UpdatePoemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdatePoemResponse)
+	err := c.cc.Invoke(ctx, PoetryService_UpdatePoem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) DeletePoem(ctx context.Context, in *DeletePoemRequest, opts ...grpc.CallOption) (*DeletePoemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePoemResponse)
+	err := c.cc.Invoke(ctx, PoetryService_DeletePoem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, PoetryService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *poetryServiceClient) StreamAuthors(ctx context.Context, in *StreamAuthorsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Author], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PoetryService_ServiceDesc.Streams[0], PoetryService_StreamAuthors_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAuthorsRequest, Author]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PoetryService_StreamAuthorsClient = grpc.ServerStreamingClient[Author]
+
+func (c *poetryServiceClient) StreamPoems(ctx context.Context, in *StreamPoemsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Poem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PoetryService_ServiceDesc.Streams[1], PoetryService_StreamPoems_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamPoemsRequest, Poem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PoetryService_StreamPoemsClient = grpc.ServerStreamingClient[Poem]
+
+// PoetryServiceServer is the server API for PoetryService service.
+// All implementations must embed UnimplementedPoetryServiceServer
+// for forward compatibility.
+//
+// PoetryService 对应 REST 的作者、诗作、搜索和统计接口，另有不分页的流式导出。
+// 读接口允许匿名调用；写接口要求 editor 及以上角色，凭据通过 metadata 传入：
+// x-api-key: <API Key> 或 authorization: Bearer <JWT>。
+type PoetryServiceServer interface {
+	GetAuthor(context.Context, *GetAuthorRequest) (*Author, error)
+	ListAuthors(context.Context, *ListAuthorsRequest) (*ListAuthorsResponse, error)
+	SearchAuthors(context.Context, *SearchAuthorsRequest) (*ListAuthorsResponse, error)
+	ListAuthorPoems(context.Context, *ListAuthorPoemsRequest) (*ListPoemsResponse, error)
+	CreateAuthor(context.Context, *CreateAuthorRequest) (*Author, error)
+	UpdateAuthor(context.Context, *UpdateAuthorRequest) (*UpdateAuthorResponse, error)
+	DeleteAuthor(context.Context, *DeleteAuthorRequest) (*DeleteAuthorResponse, error)
+	GetPoem(context.Context, *GetPoemRequest) (*Poem, error)
+	ListPoems(context.Context, *ListPoemsRequest) (*ListPoemsResponse, error)
+	SearchPoems(context.Context, *SearchPoemsRequest) (*ListPoemsResponse, error)
+	CreatePoem(context.Context, *CreatePoemRequest) (*Poem, error)
+	UpdatePoem(context.Context, *UpdatePoemRequest) (*UpdatePoemResponse, error)
+	DeletePoem(context.Context, *DeletePoemRequest) (*DeletePoemResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	// 按 author_id 顺序返回全部符合条件的作者
+	StreamAuthors(*StreamAuthorsRequest, grpc.ServerStreamingServer[Author]) error
+	// 按 poem_id 顺序返回全部符合条件的诗作
+	StreamPoems(*StreamPoemsRequest, grpc.ServerStreamingServer[Poem]) error
+	mustEmbedUnimplementedPoetryServiceServer()
+}
+
+// UnimplementedPoetryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPoetryServiceServer struct{}
+
+func (UnimplementedPoetryServiceServer) GetAuthor(context.Context, *GetAuthorRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthor not implemented")
+}
+func (UnimplementedPoetryServiceServer) ListAuthors(context.Context, *ListAuthorsRequest) (*ListAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthors not implemented")
+}
+func (UnimplementedPoetryServiceServer) SearchAuthors(context.Context, *SearchAuthorsRequest) (*ListAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchAuthors not implemented")
+}
+func (UnimplementedPoetryServiceServer) ListAuthorPoems(context.Context, *ListAuthorPoemsRequest) (*ListPoemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthorPoems not implemented")
+}
+func (UnimplementedPoetryServiceServer) CreateAuthor(context.Context, *CreateAuthorRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAuthor not implemented")
+}
+func (UnimplementedPoetryServiceServer) UpdateAuthor(context.Context, *UpdateAuthorRequest) (*UpdateAuthorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAuthor not implemented")
+}
+func (UnimplementedPoetryServiceServer) DeleteAuthor(context.Context, *DeleteAuthorRequest) (*DeleteAuthorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAuthor not implemented")
+}
+func (UnimplementedPoetryServiceServer) GetPoem(context.Context, *GetPoemRequest) (*Poem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoem not implemented")
+}
+func (UnimplementedPoetryServiceServer) ListPoems(context.Context, *ListPoemsRequest) (*ListPoemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPoems not implemented")
+}
+func (UnimplementedPoetryServiceServer) SearchPoems(context.Context, *SearchPoemsRequest) (*ListPoemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchPoems not implemented")
+}
+func (UnimplementedPoetryServiceServer) CreatePoem(context.Context, *CreatePoemRequest) (*Poem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePoem not implemented")
+}
+func (UnimplementedPoetryServiceServer) UpdatePoem(context.Context, *UpdatePoemRequest) (*UpdatePoemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePoem not implemented")
+}
+func (UnimplementedPoetryServiceServer) DeletePoem(context.Context, *DeletePoemRequest) (*DeletePoemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePoem not implemented")
+}
+func (UnimplementedPoetryServiceServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedPoetryServiceServer) StreamAuthors(*StreamAuthorsRequest, grpc.ServerStreamingServer[Author]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAuthors not implemented")
+}
+func (UnimplementedPoetryServiceServer) StreamPoems(*StreamPoemsRequest, grpc.ServerStreamingServer[Poem]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPoems not implemented")
+}
+func (UnimplementedPoetryServiceServer) mustEmbedUnimplementedPoetryServiceServer() {}
+func (UnimplementedPoetryServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafePoetryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PoetryServiceServer will
+// result in compilation errors.
+type UnsafePoetryServiceServer interface {
+	mustEmbedUnimplementedPoetryServiceServer()
+}
+
+func RegisterPoetryServiceServer(s grpc.ServiceRegistrar, srv PoetryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPoetryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PoetryService_ServiceDesc, srv)
+}
+
+func _PoetryService_GetAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).GetAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_GetAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).GetAuthor(ctx, req.(*GetAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_ListAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuthorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).ListAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_ListAuthors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).ListAuthors(ctx, req.(*ListAuthorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_SearchAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchAuthorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).SearchAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_SearchAuthors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).SearchAuthors(ctx, req.(*SearchAuthorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_ListAuthorPoems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuthorPoemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).ListAuthorPoems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_ListAuthorPoems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).ListAuthorPoems(ctx, req.(*ListAuthorPoemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_CreateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).CreateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_CreateAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).CreateAuthor(ctx, req.(*CreateAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_UpdateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).UpdateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_UpdateAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).UpdateAuthor(ctx, req.(*UpdateAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_DeleteAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).DeleteAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_DeleteAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).DeleteAuthor(ctx, req.(*DeleteAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_GetPoem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPoemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).GetPoem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_GetPoem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).GetPoem(ctx, req.(*GetPoemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_ListPoems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPoemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).ListPoems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_ListPoems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).ListPoems(ctx, req.(*ListPoemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_SearchPoems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPoemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).SearchPoems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_SearchPoems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).SearchPoems(ctx, req.(*SearchPoemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_CreatePoem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePoemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).CreatePoem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_CreatePoem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).CreatePoem(ctx, req.(*CreatePoemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_UpdatePoem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePoemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).UpdatePoem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_UpdatePoem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).UpdatePoem(ctx, req.(*UpdatePoemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_DeletePoem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePoemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).DeletePoem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_DeletePoem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).DeletePoem(ctx, req.(*DeletePoemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PoetryServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PoetryService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PoetryServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PoetryService_StreamAuthors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAuthorsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PoetryServiceServer).StreamAuthors(m, &grpc.GenericServerStream[StreamAuthorsRequest, Author]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PoetryService_StreamAuthorsServer = grpc.ServerStreamingServer[Author]
+
+func _PoetryService_StreamPoems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPoemsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PoetryServiceServer).StreamPoems(m, &grpc.GenericServerStream[StreamPoemsRequest, Poem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PoetryService_StreamPoemsServer = grpc.ServerStreamingServer[Poem]
+
+// PoetryService_ServiceDesc is the grpc.ServiceDesc for PoetryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PoetryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "poetry.v1.PoetryService",
+	HandlerType: (*PoetryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAuthor",
+			Handler:    _PoetryService_GetAuthor_Handler,
+		},
+		{
+			MethodName: "ListAuthors",
+			Handler:    _PoetryService_ListAuthors_Handler,
+		},
+		{
+			MethodName: "SearchAuthors",
+			Handler:    _PoetryService_SearchAuthors_Handler,
+		},
+		{
+			MethodName: "ListAuthorPoems",
+			Handler:    _PoetryService_ListAuthorPoems_Handler,
+		},
+		{
+			MethodName: "CreateAuthor",
+			Handler:    _PoetryService_CreateAuthor_Handler,
+		},
+		{
+			MethodName: "UpdateAuthor",
+			Handler:    _PoetryService_UpdateAuthor_Handler,
+		},
+		{
+			MethodName: "DeleteAuthor",
+			Handler:    _PoetryService_DeleteAuthor_Handler,
+		},
+		{
+			MethodName: "GetPoem",
+			Handler:    _PoetryService_GetPoem_Handler,
+		},
+		{
+			MethodName: "ListPoems",
+			Handler:    _PoetryService_ListPoems_Handler,
+		},
+		{
+			MethodName: "SearchPoems",
+			Handler:    _PoetryService_SearchPoems_Handler,
+		},
+		{
+			MethodName: "CreatePoem",
+			Handler:    _PoetryService_CreatePoem_Handler,
+		},
+		{
+			MethodName: "UpdatePoem",
+			Handler:    _PoetryService_UpdatePoem_Handler,
+		},
+		{
+			MethodName: "DeletePoem",
+			Handler:    _PoetryService_DeletePoem_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _PoetryService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAuthors",
+			Handler:       _PoetryService_StreamAuthors_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamPoems",
+			Handler:       _PoetryService_StreamPoems_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "poetry.proto",
+}
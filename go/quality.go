@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 数据质量检查项，与 ?check= 参数一致
+const (
+	checkEmptyTitle         = "empty_title"
+	checkEmptyParagraph     = "empty_paragraph"
+	checkOversizedParagraph = "oversized_paragraph"
+	checkLineLength         = "line_length_mismatch"
+	checkLatin              = "latin"
+	checkPunctuation        = "unbalanced_punctuation"
+	checkPlaceholder        = "placeholder"
+	checkAuthorNoPoems      = "author_without_poems"
+	checkUnknownAuthor      = "unknown_author"
+)
+
+// 报告中每项默认和最多列出的记录数
+const (
+	defaultQualityLimit = 20
+	maxQualityLimit     = 1000
+)
+
+// maxParagraphRunes 是一段的最大字数，原始数据中超过的多为误把序文或多首诗合为一段
+const maxParagraphRunes = 100
+
+// QualityItem 是一条有问题的记录。
+type QualityItem struct {
+	Entity string `json:"entity"` // poem 或 author
+	ID     int    `json:"id"`
+	Title  string `json:"title"` // 诗题或作者名
+	Detail string `json:"detail,omitempty"`
+	Link   string `json:"link"`
+}
+
+// QualityCheck 是一项检查的结果，items 最多列出 limit 条。
+type QualityCheck struct {
+	Check       string        `json:"check"`
+	Description string        `json:"description"`
+	Count       int           `json:"count"`
+	Skipped     string        `json:"skipped,omitempty"` // 无法检查的原因
+	Items       []QualityItem `json:"items"`
+}
+
+// QualityReport 是数据质量报告，total 为全部检查项的问题数之和。
+type QualityReport struct {
+	Total  int            `json:"total"`
+	Checks []QualityCheck `json:"checks"`
+}
+
+// qualityPoem 是检查时读取的诗作。
+type qualityPoem struct {
+	id      int
+	title   string
+	content string
+	author  string
+}
+
+// poemCheck 检查一首诗作，有问题时返回说明和 true。
+type poemCheck struct {
+	name        string
+	description string
+	check       func(p qualityPoem) (string, bool)
+}
+
+var (
+	latinPattern       = regexp.MustCompile(`[A-Za-z]+`)
+	placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+	notePattern        = regexp.MustCompile(`[（(][^（()）]*[）)]`)
+)
+
+// punctuationPairs 是需要成对出现的标点
+var punctuationPairs = [][2]rune{{'（', '）'}, {'《', '》'}, {'「', '」'}, {'『', '』'}, {'【', '】'}, {'〔', '〕'}, {'(', ')'}, {'[', ']'}, {'{', '}'}}
+
+var poemChecks = []poemCheck{
+	{checkEmptyTitle, "诗题为空", func(p qualityPoem) (string, bool) {
+		return "", strings.TrimSpace(p.title) == ""
+	}},
+	{checkEmptyParagraph, "内容为空或有空段", func(p qualityPoem) (string, bool) {
+		if strings.TrimSpace(p.content) == "" {
+			return "内容为空", true
+		}
+		for i, line := range strings.Split(p.content, "\n") {
+			if strings.TrimSpace(line) == "" {
+				return fmt.Sprintf("第 %d 段为空", i+1), true
+			}
+		}
+		return "", false
+	}},
+	{checkOversizedParagraph, fmt.Sprintf("一段超过 %d 字", maxParagraphRunes), func(p qualityPoem) (string, bool) {
+		for i, line := range strings.Split(p.content, "\n") {
+			if n := utf8.RuneCountInString(line); n > maxParagraphRunes {
+				return fmt.Sprintf("第 %d 段 %d 字", i+1, n), true
+			}
+		}
+		return "", false
+	}},
+	{checkLineLength, "近体诗中个别句子的字数与其他句不同", lineLengthMismatch},
+	{checkLatin, "含有拉丁字母", func(p qualityPoem) (string, bool) {
+		found := latinPattern.FindAllString(p.title+"\n"+p.content, 5)
+		return strings.Join(found, "、"), len(found) > 0
+	}},
+	{checkPunctuation, "括号、引号等标点不成对", unbalancedPunctuation},
+	{checkPlaceholder, "含有未转换的缺字占位符 {...}", func(p qualityPoem) (string, bool) {
+		found := placeholderPattern.FindAllString(p.title+"\n"+p.content, 5)
+		return strings.Join(found, "、"), len(found) > 0
+	}},
+}
+
+// verseLength 返回一句的字数：不计标点和夹注（一作……），缺字占位符 {...} 和 ⿰ 等表意文字描述序列算一个字。
+func verseLength(v string) int {
+	n := 0
+	for _, r := range v {
+		switch {
+		case r == '⿲' || r == '⿳':
+			n -= 2
+		case r >= '⿰' && r <= '⿻':
+			n--
+		case r == '□' || unicode.Is(unicode.Han, r):
+			n++
+		}
+	}
+	return n
+}
+
+// lineLengthMismatch 检查句数符合绝句、律诗或五言排律，其他句都是五言或七言、只有一句多一字或少一字的诗作，
+// 这类诗作多为原始数据脱字或衍字。七言的长篇多为歌行，常有「君不見」等八言句，不检查；带「兮」的句子为骚体，也不检查。
+func lineLengthMismatch(p qualityPoem) (string, bool) {
+	content := placeholderPattern.ReplaceAllString(notePattern.ReplaceAllString(p.content, ""), "□")
+	vs := verses(content)
+	count := len(vs)
+	regulated := count == 4 || count == 8
+	if !regulated && (count < 10 || count%2 != 0) {
+		return "", false
+	}
+	lengths := map[int]int{}
+	for _, v := range vs {
+		lengths[verseLength(v)]++
+	}
+	for _, n := range []int{5, 7} {
+		if lengths[n] != count-1 || n == 7 && !regulated {
+			continue
+		}
+		for i, v := range vs {
+			if l := verseLength(v); (l == n-1 || l == n+1) && !strings.ContainsRune(v, '兮') {
+				return fmt.Sprintf("第 %d 句「%s」%d 字，其他句 %d 字", i+1, v, l, n), true
+			}
+		}
+	}
+	return "", false
+}
+
+// unbalancedPunctuation 检查诗题和内容中成对的标点是否数量相同且先开后闭。
+func unbalancedPunctuation(p qualityPoem) (string, bool) {
+	text := p.title + "\n" + p.content
+	for _, pair := range punctuationPairs {
+		depth, open, closed := 0, 0, 0
+		early := false
+		for _, r := range text {
+			switch r {
+			case pair[0]:
+				depth++
+				open++
+			case pair[1]:
+				depth--
+				closed++
+				early = early || depth < 0
+			}
+		}
+		if open != closed {
+			return fmt.Sprintf("%c %d 个，%c %d 个", pair[0], open, pair[1], closed), true
+		}
+		if early {
+			return fmt.Sprintf("%c 出现在 %c 之前", pair[1], pair[0]), true
+		}
+	}
+	return "", false
+}
+
+func poemLink(id int) string {
+	return apiPrefix + "/poems/" + strconv.Itoa(id)
+}
+
+func authorLink(id int) string {
+	return apiPrefix + "/authors/" + strconv.Itoa(id)
+}
+
+// loadCorpusAuthors 读取 corpusDir 中 authors.tang.json 的作者名。
+func loadCorpusAuthors() (map[string]bool, error) {
+	body, err := os.ReadFile(filepath.Join(corpusDir, "authors.tang.json"))
+	if err != nil {
+		return nil, err
+	}
+	var authors []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &authors); err != nil {
+		return nil, fmt.Errorf("authors.tang.json: %w", err)
+	}
+	names := make(map[string]bool, len(authors))
+	for _, a := range authors {
+		names[a.Name] = true
+	}
+	return names, nil
+}
+
+// qualityChecks 是全部检查项的名称，用于校验 ?check= 参数
+func qualityChecks() []string {
+	names := make([]string, 0, len(poemChecks)+2)
+	for _, pc := range poemChecks {
+		names = append(names, pc.name)
+	}
+	return append(names, checkAuthorNoPoems, checkUnknownAuthor)
+}
+
+// buildQualityReport 检查全部未删除的诗作和作者，only 不为空时只执行该项，每项最多列出 limit 条记录。
+func buildQualityReport(ctx context.Context, only string, limit int) (*QualityReport, error) {
+	report := &QualityReport{Checks: []QualityCheck{}}
+	results := map[string]*QualityCheck{}
+	add := func(name, description string) {
+		if only == "" || only == name {
+			report.Checks = append(report.Checks, QualityCheck{Check: name, Description: description, Items: []QualityItem{}})
+		}
+	}
+	for _, pc := range poemChecks {
+		add(pc.name, pc.description)
+	}
+	add(checkAuthorNoPoems, "作者没有诗作")
+	add(checkUnknownAuthor, "诗作的作者不在 authors.tang.json 中")
+	for i := range report.Checks {
+		results[report.Checks[i].Check] = &report.Checks[i]
+	}
+	record := func(qc *QualityCheck, item QualityItem) {
+		qc.Count++
+		if len(qc.Items) < limit {
+			qc.Items = append(qc.Items, item)
+		}
+	}
+
+	var corpusAuthors map[string]bool
+	if qc := results[checkUnknownAuthor]; qc != nil {
+		names, err := loadCorpusAuthors()
+		if errors.Is(err, fs.ErrNotExist) {
+			qc.Skipped = "authors.tang.json not found in " + corpusDir
+		} else if err != nil {
+			return nil, apierror.Storage(err)
+		} else {
+			corpusAuthors = names
+		}
+	}
+
+	rows, err := query(ctx, "quality_poems", `SELECT p.poem_id, p.title, p.content, COALESCE(a.name, '')
+		FROM Poems p LEFT JOIN Authors a ON a.author_id = p.author_id
+		WHERE p.deleted_at IS NULL ORDER BY p.poem_id`)
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query poems: %w", err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p qualityPoem
+		if err := rows.Scan(&p.id, &p.title, &p.content, &p.author); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan poem: %w", err))
+		}
+		for _, pc := range poemChecks {
+			qc := results[pc.name]
+			if qc == nil {
+				continue
+			}
+			if detail, bad := pc.check(p); bad {
+				record(qc, QualityItem{Entity: entityPoem, ID: p.id, Title: p.title, Detail: detail, Link: poemLink(p.id)})
+			}
+		}
+		if qc := results[checkUnknownAuthor]; qc != nil && corpusAuthors != nil && !corpusAuthors[p.author] {
+			record(qc, QualityItem{Entity: entityPoem, ID: p.id, Title: p.title, Detail: p.author, Link: poemLink(p.id)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read poems: %w", err))
+	}
+
+	if qc := results[checkAuthorNoPoems]; qc != nil {
+		rows, err := query(ctx, "quality_authors", `SELECT a.author_id, a.name FROM Authors a
+			WHERE a.deleted_at IS NULL AND NOT EXISTS (
+				SELECT 1 FROM Poems p WHERE p.author_id = a.author_id AND p.deleted_at IS NULL)
+			ORDER BY a.author_id`)
+		if err != nil {
+			return nil, apierror.Storage(fmt.Errorf("query authors: %w", err))
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				return nil, apierror.Storage(fmt.Errorf("scan author: %w", err))
+			}
+			record(qc, QualityItem{Entity: entityAuthor, ID: id, Title: name, Link: authorLink(id)})
+		}
+		if err := rows.Err(); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("read authors: %w", err))
+		}
+	}
+
+	for _, qc := range report.Checks {
+		report.Total += qc.Count
+	}
+	return report, nil
+}
+
+// 数据质量报告：?check= 只执行一项检查，?limit= 为每项列出的记录数（默认 20，最多 1000）。
+// 需要检查全部诗作，结果与统计数据一起缓存。
+func qualityReport(c *gin.Context) {
+	check := c.Query("check")
+	if check != "" && !slices.Contains(qualityChecks(), check) {
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "check", "allowed": qualityChecks()}))
+		return
+	}
+	limit := defaultQualityLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxQualityLimit {
+			apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "limit", "max": maxQualityLimit}))
+			return
+		}
+		limit = n
+	}
+
+	aggregateCache.serve(c, fmt.Sprintf("quality:%s:%d", check, limit), func() (any, int, error) {
+		report, err := buildQualityReport(c.Request.Context(), check, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return report, report.Total, nil
+	})
+}
+
+// runLint 实现 lint 命令：go run . lint [-check latin] [-limit 20]，列出每项检查的问题数和记录，
+// 有问题时以非零状态退出。
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	check := fs.String("check", "", "只执行一项检查："+strings.Join(qualityChecks(), "、"))
+	limit := fs.Int("limit", defaultQualityLimit, "每项最多列出的记录数")
+	fs.Parse(args)
+	if *check != "" && !slices.Contains(qualityChecks(), *check) {
+		return fmt.Errorf("lint: unknown check %q, available: %s", *check, strings.Join(qualityChecks(), ", "))
+	}
+
+	report, err := buildQualityReport(context.Background(), *check, *limit)
+	if err != nil {
+		return err
+	}
+	for _, qc := range report.Checks {
+		if qc.Skipped != "" {
+			fmt.Printf("%s: skipped (%s)\n", qc.Check, qc.Skipped)
+			continue
+		}
+		fmt.Printf("%s: %d (%s)\n", qc.Check, qc.Count, qc.Description)
+		for _, item := range qc.Items {
+			title := item.Title
+			if r := []rune(title); len(r) > 30 {
+				title = string(r[:30]) + "…"
+			}
+			line := fmt.Sprintf("  %s %d %s", item.Entity, item.ID, title)
+			if item.Detail != "" {
+				line += "  " + item.Detail
+			}
+			fmt.Println(line + "  " + item.Link)
+		}
+		if qc.Count > len(qc.Items) {
+			fmt.Printf("  ... and %d more\n", qc.Count-len(qc.Items))
+		}
+	}
+	if report.Total == 0 {
+		fmt.Println("OK: no problems found")
+		return nil
+	}
+	return fmt.Errorf("lint found %d problems", report.Total)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"poetry/apierror"
+)
+
+// 作者和诗作的列表、搜索查询，REST、GraphQL 和 gRPC 共用。结果按 ID 排序，
+// page 从 1 开始，返回当前页的记录和符合条件的总数。
+
+const authorColumns = "author_id, name, description, COALESCE(imgUrl, '')"
+
+// listAuthors 返回第 page 页作者。
+func listAuthors(ctx context.Context, page, size int) ([]Author, int, error) {
+	var total int
+	if err := queryRow(ctx, "count_authors", "SELECT COUNT(*) FROM Authors WHERE deleted_at IS NULL").Scan(&total); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query total authors: %w", err))
+	}
+	authors, err := queryAuthors(ctx, "list_authors",
+		"SELECT "+authorColumns+" FROM Authors WHERE deleted_at IS NULL ORDER BY author_id LIMIT ? OFFSET ?",
+		size, (page-1)*size)
+	return authors, total, err
+}
+
+// firstAuthors 返回前 n 位作者和作者总数。
+func firstAuthors(ctx context.Context, n int) ([]Author, int, error) {
+	return listAuthors(ctx, 1, n)
+}
+
+// findAuthors 按名字模糊搜索作者，返回第 page 页。
+func findAuthors(ctx context.Context, name string, page, size int) ([]Author, int, error) {
+	pattern := "%" + name + "%"
+	var total int
+	if err := queryRow(ctx, "search_authors_count", "SELECT COUNT(*) FROM Authors WHERE deleted_at IS NULL AND name LIKE ?", pattern).Scan(&total); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query total authors: %w", err))
+	}
+	authors, err := queryAuthors(ctx, "search_authors",
+		"SELECT "+authorColumns+" FROM Authors WHERE deleted_at IS NULL AND name LIKE ? ORDER BY author_id LIMIT ? OFFSET ?",
+		pattern, size, (page-1)*size)
+	return authors, total, err
+}
+
+// listPoems 返回第 page 页诗作。
+func listPoems(ctx context.Context, page, size int) ([]Poem, int, error) {
+	var total int
+	if err := queryRow(ctx, "count_poems", "SELECT COUNT(*) FROM Poems WHERE deleted_at IS NULL").Scan(&total); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query total poems: %w", err))
+	}
+	poems, err := queryPoems(ctx, "list_poems",
+		"SELECT "+poemColumns+" FROM Poems WHERE deleted_at IS NULL ORDER BY poem_id LIMIT ? OFFSET ?",
+		size, (page-1)*size)
+	return poems, total, err
+}
+
+// findPoems 按标题或内容模糊搜索诗作，返回第 page 页。
+func findPoems(ctx context.Context, text string, page, size int) ([]Poem, int, error) {
+	pattern := "%" + text + "%"
+	var total int
+	if err := queryRow(ctx, "search_poems_count", "SELECT COUNT(*) FROM Poems WHERE deleted_at IS NULL AND (title LIKE ? OR content LIKE ?)",
+		pattern, pattern).Scan(&total); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query total poems: %w", err))
+	}
+	poems, err := queryPoems(ctx, "search_poems",
+		"SELECT "+poemColumns+" FROM Poems WHERE deleted_at IS NULL AND (title LIKE ? OR content LIKE ?) ORDER BY poem_id LIMIT ? OFFSET ?",
+		pattern, pattern, size, (page-1)*size)
+	return poems, total, err
+}
+
+// listAuthorPoems 返回作者的第 page 页诗作。
+func listAuthorPoems(ctx context.Context, authorID, page, size int) ([]Poem, int, error) {
+	var total int
+	if err := queryRow(ctx, "author_poems_count", "SELECT COUNT(*) FROM Poems WHERE author_id = ? AND deleted_at IS NULL", authorID).Scan(&total); err != nil {
+		return nil, 0, apierror.Storage(fmt.Errorf("query total poems for author %d: %w", authorID, err))
+	}
+	poems, err := queryPoems(ctx, "author_poems",
+		"SELECT "+poemColumns+" FROM Poems WHERE author_id = ? AND deleted_at IS NULL ORDER BY poem_id LIMIT ? OFFSET ?",
+		authorID, size, (page-1)*size)
+	return poems, total, err
+}
+
+// queryAuthors 执行选出 authorColumns 的查询。
+func queryAuthors(ctx context.Context, name, q string, args ...any) ([]Author, error) {
+	rows, err := query(ctx, name, q, args...)
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query authors: %w", err))
+	}
+	defer rows.Close()
+
+	authors := []Author{}
+	for rows.Next() {
+		var a Author
+		if err := rows.Scan(&a.AuthorID, &a.Name, &a.Description, &a.ImgUrl); err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan author: %w", err))
+		}
+		authors = append(authors, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read authors: %w", err))
+	}
+	return authors, nil
+}
+
+// queryPoems 执行选出 poemColumns 的查询。
+func queryPoems(ctx context.Context, name, q string, args ...any) ([]Poem, error) {
+	rows, err := query(ctx, name, q, args...)
+	if err != nil {
+		return nil, apierror.Storage(fmt.Errorf("query poems: %w", err))
+	}
+	defer rows.Close()
+
+	poems := []Poem{}
+	for rows.Next() {
+		p, err := scanPoem(rows)
+		if err != nil {
+			return nil, apierror.Storage(fmt.Errorf("scan poem: %w", err))
+		}
+		poems = append(poems, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apierror.Storage(fmt.Errorf("read poems: %w", err))
+	}
+	return poems, nil
+}
@@ -0,0 +1,87 @@
+// Package ratelimit 实现按客户端分桶的令牌桶限流。
+//
+// 每个客户端（按键区分，如 IP 或用户）一个桶，桶中最多 Burst 个令牌，
+// 每秒补充 Rate 个。请求按代价扣除令牌，令牌不足时拒绝并给出需要等待的时间。
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 是清理空闲桶的最小间隔。桶补满后与新建的桶等价，可以删除以释放内存。
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter 是并发安全的令牌桶集合。
+type Limiter struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst float64 // 桶容量，即允许的突发代价
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New 返回每秒补充 rate 个令牌、容量为 burst 的限流器。
+func New(rate, burst float64) *Limiter {
+	return &Limiter{Rate: rate, Burst: burst, buckets: map[string]*bucket{}, now: time.Now}
+}
+
+// Take 从 key 的桶中扣除 cost 个令牌。令牌不足时不扣除，返回 false 和
+// 攒够令牌还需等待的时间；cost 超过桶容量的请求永远不会被放行。
+func (l *Limiter) Take(key string, cost float64) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: l.Burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.Burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+
+	if b.tokens >= cost {
+		b.tokens -= cost
+		return true, 0
+	}
+	wait := (cost - b.tokens) / l.Rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// Remaining 返回 key 当前可用的令牌数（向下取整）。
+func (l *Limiter) Remaining(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, found := l.buckets[key]
+	if !found {
+		return int(l.Burst)
+	}
+	tokens := math.Min(l.Burst, b.tokens+l.now().Sub(b.last).Seconds()*l.Rate)
+	return int(tokens)
+}
+
+// sweep 删除已经补满的桶，调用方需持有锁。
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	full := time.Duration(l.Burst / l.Rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock 返回可以手动前进的时钟
+func fakeClock(l *Limiter) func(time.Duration) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTake(t *testing.T) {
+	type take struct {
+		advance time.Duration // 请求前经过的时间
+		key     string
+		cost    float64
+		ok      bool
+		retry   time.Duration
+	}
+	tests := []struct {
+		name  string
+		takes []take
+	}{
+		{"burst then reject", []take{
+			{0, "a", 4, true, 0},
+			{0, "a", 6, true, 0},
+			{0, "a", 1, false, 500 * time.Millisecond},
+		}},
+		{"refill", []take{
+			{0, "a", 10, true, 0},
+			{time.Second, "a", 3, false, 500 * time.Millisecond},
+			{time.Second, "a", 3, true, 0},
+		}},
+		{"refill capped at burst", []take{
+			{0, "a", 10, true, 0},
+			{time.Hour, "a", 10, true, 0},
+			{0, "a", 1, false, 500 * time.Millisecond},
+		}},
+		{"keys are independent", []take{
+			{0, "a", 10, true, 0},
+			{0, "b", 10, true, 0},
+			{0, "a", 1, false, 500 * time.Millisecond},
+		}},
+		{"rejected take keeps tokens", []take{
+			{0, "a", 8, true, 0},
+			{0, "a", 3, false, 500 * time.Millisecond},
+			{0, "a", 2, true, 0},
+		}},
+		{"cost above burst never passes", []take{
+			{0, "a", 11, false, 500 * time.Millisecond},
+			{time.Hour, "a", 11, false, 500 * time.Millisecond},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(2, 10)
+			advance := fakeClock(l)
+			for i, tk := range tt.takes {
+				advance(tk.advance)
+				ok, retry := l.Take(tk.key, tk.cost)
+				if ok != tk.ok || retry != tk.retry {
+					t.Errorf("take %d: %v %v, want %v %v", i, ok, retry, tk.ok, tk.retry)
+				}
+			}
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	l := New(2, 10)
+	advance := fakeClock(l)
+	if n := l.Remaining("a"); n != 10 {
+		t.Errorf("new key: %d, want 10", n)
+	}
+	l.Take("a", 7)
+	if n := l.Remaining("a"); n != 3 {
+		t.Errorf("after take: %d, want 3", n)
+	}
+	advance(1500 * time.Millisecond)
+	if n := l.Remaining("a"); n != 6 {
+		t.Errorf("after refill: %d, want 6", n)
+	}
+}
+
+// 补满的桶在清理时删除，删除后与新建的桶等价
+func TestSweep(t *testing.T) {
+	l := New(2, 10)
+	advance := fakeClock(l)
+	l.Take("a", 10)
+	l.Take("b", 10)
+	advance(sweepInterval)
+	l.Take("b", 1)
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("full bucket a not swept")
+	}
+	if n := l.Remaining("a"); n != 10 {
+		t.Errorf("swept key: %d, want 10", n)
+	}
+	if n := l.Remaining("b"); n != 9 {
+		t.Errorf("bucket b: %d, want 9", n)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+	"poetry/cache"
+)
+
+// 缓存默认值，可通过环境变量调整
+const (
+	defaultCacheSize = 1000            // 诗作、作者缓存各自的最大条目数
+	defaultCacheTTL  = 5 * time.Minute // 条目过期时间，兜底其他进程（如 app.py、命令行）的修改
+)
+
+// 浏览器和代理的缓存策略。统计数据允许短时间不一致；单条记录修改后应立即可见，
+// 每次都用 ETag 向服务端确认，未修改时返回 304。
+const (
+	aggregateCacheControl = "public, max-age=60"
+	entityCacheControl    = "no-cache"
+)
+
+// cachedResponse 是序列化后的响应体及其 ETag。
+type cachedResponse struct {
+	body  []byte
+	etag  string
+	count int // 列表的条数，写入访问日志；单条记录为 -1
+}
+
+// responseCache 缓存一类接口的响应。
+type responseCache[K comparable] struct {
+	name    string // 指标中的 cache 标签
+	control string // Cache-Control 响应头
+	entries *cache.Cache[K, cachedResponse]
+}
+
+var (
+	aggregateCache *responseCache[string] // 统计与图表：stats、echart_two、data_table，以及质量报告
+	poemCache      *responseCache[int]
+	authorCache    *responseCache[int]
+)
+
+// setupCaches 按 POETRY_CACHE_SIZE 和 POETRY_CACHE_TTL 创建缓存，POETRY_CACHE_SIZE=0 时关闭服务端缓存，
+// ETag 和 Cache-Control 仍然有效。
+func setupCaches() {
+	size := int(envFloat("POETRY_CACHE_SIZE", defaultCacheSize))
+	ttl := defaultCacheTTL
+	if v := os.Getenv("POETRY_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fatal("Invalid POETRY_CACHE_TTL", "value", v)
+		}
+		ttl = d
+	}
+
+	aggregates := 0
+	if size > 0 {
+		aggregates = 8 // 三个统计接口，其余给不同参数的质量报告
+	}
+	aggregateCache = &responseCache[string]{"aggregate", aggregateCacheControl, cache.New[string, cachedResponse](aggregates, ttl)}
+	poemCache = &responseCache[int]{"poem", entityCacheControl, cache.New[int, cachedResponse](size, ttl)}
+	authorCache = &responseCache[int]{"author", entityCacheControl, cache.New[int, cachedResponse](size, ttl)}
+}
+
+// serve 返回 key 的缓存响应，未命中时调用 load 生成并写入缓存。load 返回的 count 为列表条数，单条记录为 -1。
+func (rc *responseCache[K]) serve(c *gin.Context, key K, load func() (v any, count int, err error)) {
+	resp, hit := rc.entries.Get(key)
+	cacheLookup(rc.name, hit)
+	if !hit {
+		version := rc.entries.Version()
+		v, count, err := load()
+		if err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		body, err := json.Marshal(v)
+		if err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		sum := sha256.Sum256(body)
+		resp = cachedResponse{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`, count: count}
+		// 读取期间有写操作时不写入，避免缓存失效前的旧数据
+		rc.entries.SetIfUnchanged(key, resp, version)
+	}
+
+	if resp.count >= 0 {
+		setResultCount(c, resp.count)
+	}
+	c.Header("ETag", resp.etag)
+	c.Header("Cache-Control", rc.control)
+	if etagMatch(c.GetHeader("If-None-Match"), resp.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", resp.body)
+}
+
+// etagMatch 判断 If-None-Match 是否包含 etag，按弱比较忽略 W/ 前缀。
+func etagMatch(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate 在写操作提交后删除受影响记录的缓存。统计与图表依赖全部作者和诗作，任何修改都会使其失效。
+func invalidate(entity string, ids ...int) {
+	switch entity {
+	case entityAuthor:
+		authorCache.entries.Delete(ids...)
+	case entityPoem:
+		poemCache.entries.Delete(ids...)
+	}
+	aggregateCache.entries.Clear()
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+	"poetry/textdiff"
+)
+
+// 修订记录所属的实体类型
+const (
+	entityAuthor = "author"
+	entityPoem   = "poem"
+)
+
+// Revision 是一次修改的记录，保存修改前后的字段值。
+type Revision struct {
+	RevisionID int                        `json:"revision_id"`
+	Entity     string                     `json:"entity"`
+	EntityID   int                        `json:"entity_id"`
+	Actor      string                     `json:"actor"` // 修订人
+	CreatedAt  string                     `json:"created_at"`
+	Comment    string                     `json:"comment"`
+	Previous   map[string]any             `json:"previous"`
+	Data       map[string]any             `json:"data"`
+	Diff       map[string][]textdiff.Edit `json:"diff"` // 每个变化字段的字符级差异
+}
+
+func authorFields(a Author) map[string]any {
+	return map[string]any{"name": a.Name, "description": a.Description, "imgUrl": a.ImgUrl}
+}
+
+func poemFields(p Poem) map[string]any {
+	return map[string]any{"title": p.Title, "author_id": p.AuthorID, "content": p.Content}
+}
+
+// recordRevision 在 tx 中写入一条修订记录，返回修订 ID。
+func recordRevision(tx *tracedTx, entity string, id int, previous, data map[string]any, by, comment string) (int64, error) {
+	prev, err := json.Marshal(previous)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.exec("insert_revision", `INSERT INTO revisions (entity, entity_id, actor, created_at, comment, previous, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, entity, id, by, now(), comment, string(prev), string(cur))
+	if err != nil {
+		return 0, fmt.Errorf("insert revision: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// updateAuthorRow 在一个事务中读取作者、调用 edit 修改并写回，同时记录修订和审计记录。
+// 读取和写入在同一事务中，并发的修改不会互相覆盖，修订中的修改前内容就是被覆盖的内容。
+// 字段没有变化时不写入，返回修改后的作者和修订 ID（无变化时为 0）。
+func updateAuthorRow(ctx context.Context, id int, a requestAudit, comment string, edit func(*Author) error) (Author, int64, error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return Author{}, 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	previous, err := loadAuthorTx(tx, id)
+	if err != nil {
+		return Author{}, 0, err
+	}
+	author := previous
+	if err := edit(&author); err != nil {
+		return Author{}, 0, err
+	}
+	rev, err := updateAuthorTx(tx, id, previous, author, a, comment)
+	if err != nil {
+		return Author{}, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Author{}, 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityAuthor, id)
+	return author, rev, nil
+}
+
+// updateAuthorTx 是在调用方事务中执行的 updateAuthorRow。
+func updateAuthorTx(tx *tracedTx, id int, previous, author Author, a requestAudit, comment string) (int64, error) {
+	before, after := authorFields(previous), authorFields(author)
+	if reflect.DeepEqual(before, after) {
+		return 0, nil
+	}
+
+	if _, err := tx.exec("update_author", "UPDATE Authors SET name = ?, description = ?, imgUrl = ? WHERE author_id = ?",
+		author.Name, author.Description, author.ImgUrl, id); err != nil {
+		return 0, authorWriteError(tx, err, author.Name)
+	}
+	rev, err := recordRevision(tx, entityAuthor, id, before, after, a.Actor, comment)
+	if err != nil {
+		return 0, apierror.Storage(err)
+	}
+	if err := a.record(tx, auditUpdate, entityAuthor, int64(id), before, after); err != nil {
+		return 0, apierror.Storage(err)
+	}
+	return rev, nil
+}
+
+// updatePoemRow 在一个事务中读取诗作、调用 edit 修改，在同一事务中校验后写回，规则同 updateAuthorRow。
+func updatePoemRow(ctx context.Context, id int, a requestAudit, comment string, edit func(*Poem) error) (Poem, int64, error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return Poem{}, 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	previous, err := loadPoemTx(tx, id)
+	if err != nil {
+		return Poem{}, 0, err
+	}
+	poem := previous
+	if err := edit(&poem); err != nil {
+		return Poem{}, 0, err
+	}
+	if err := validatePoemTx(tx, poem); err != nil {
+		return Poem{}, 0, err
+	}
+	rev, err := updatePoemTx(tx, id, previous, poem, a, comment)
+	if err != nil {
+		return Poem{}, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Poem{}, 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityPoem, id)
+	return poem, rev, nil
+}
+
+// updatePoemTx 是在调用方事务中执行的 updatePoemRow。
+func updatePoemTx(tx *tracedTx, id int, previous, poem Poem, a requestAudit, comment string) (int64, error) {
+	before, after := poemFields(previous), poemFields(poem)
+	if reflect.DeepEqual(before, after) {
+		return 0, nil
+	}
+
+	if _, err := tx.exec("update_poem", "UPDATE Poems SET title = ?, author_id = ?, content = ? WHERE poem_id = ?",
+		poem.Title, poem.AuthorID, poem.Content, id); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("update poem %d: %w", id, err))
+	}
+	rev, err := recordRevision(tx, entityPoem, id, before, after, a.Actor, comment)
+	if err != nil {
+		return 0, apierror.Storage(err)
+	}
+	if err := a.record(tx, auditUpdate, entityPoem, int64(id), before, after); err != nil {
+		return 0, apierror.Storage(err)
+	}
+	return rev, nil
+}
+
+// 修订历史和投稿详情对 reader 开放，每页最多计算 20 条记录的差异，需要限制计算量：
+// 修改前后合计超过 maxDiffRunes 个字符的字段整段替换，其余字段的编辑距离超过 maxDiffEdits 时
+// 公共前后缀之间整段替换。
+const (
+	maxDiffRunes = 4000
+	maxDiffEdits = 500
+)
+
+// diffFields 计算两组字段值之间每个变化字段的字符级差异。
+func diffFields(previous, data map[string]any) map[string][]textdiff.Edit {
+	keys := map[string]bool{}
+	for k := range previous {
+		keys[k] = true
+	}
+	for k := range data {
+		keys[k] = true
+	}
+	diff := map[string][]textdiff.Edit{}
+	for k := range keys {
+		before, after := fieldText(previous[k]), fieldText(data[k])
+		switch {
+		case before == after:
+		case utf8.RuneCountInString(before)+utf8.RuneCountInString(after) > maxDiffRunes:
+			diff[k] = replaceField(before, after)
+		default:
+			diff[k] = textdiff.RunesWithin(before, after, maxDiffEdits)
+		}
+	}
+	return diff
+}
+
+// replaceField 返回整段删除 before、插入 after 的差异。
+func replaceField(before, after string) []textdiff.Edit {
+	var edits []textdiff.Edit
+	if before != "" {
+		edits = append(edits, textdiff.Edit{Op: textdiff.Delete, Text: before})
+	}
+	if after != "" {
+		edits = append(edits, textdiff.Edit{Op: textdiff.Insert, Text: after})
+	}
+	return edits
+}
+
+// fieldText 返回用于比较的字段文本，缺失的字段视为空字符串。
+func fieldText(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func getAuthorRevisions(c *gin.Context) { listRevisions(c, entityAuthor) }
+func getPoemRevisions(c *gin.Context)   { listRevisions(c, entityPoem) }
+
+// 获取作者或诗作的修订历史（分页，最新的在前），每条修订附带与修改前的差异
+func listRevisions(c *gin.Context, entity string) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	const pageSize = 20
+	offset := (page - 1) * pageSize
+
+	var total int
+	err := queryRow(c.Request.Context(), "count_revisions", "SELECT COUNT(*) FROM revisions WHERE entity = ? AND entity_id = ?", entity, id).Scan(&total)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("count revisions: %w", err)))
+		return
+	}
+
+	rows, err := query(c.Request.Context(), "list_revisions", `SELECT revision_id, entity, entity_id, actor, created_at, comment, previous, data
+		FROM revisions WHERE entity = ? AND entity_id = ?
+		ORDER BY revision_id DESC LIMIT ? OFFSET ?`, entity, id, pageSize, offset)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("query revisions: %w", err)))
+		return
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		rev.Diff = diffFields(rev.Previous, rev.Data)
+		revisions = append(revisions, rev)
+	}
+
+	setResultCount(c, len(revisions))
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"data":      revisions,
+	})
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRevision(row rowScanner) (Revision, error) {
+	var rev Revision
+	var previous, data string
+	if err := row.Scan(&rev.RevisionID, &rev.Entity, &rev.EntityID, &rev.Actor, &rev.CreatedAt, &rev.Comment, &previous, &data); err != nil {
+		return rev, err
+	}
+	if err := json.Unmarshal([]byte(previous), &rev.Previous); err != nil {
+		return rev, fmt.Errorf("decode revision %d: %w", rev.RevisionID, err)
+	}
+	if err := json.Unmarshal([]byte(data), &rev.Data); err != nil {
+		return rev, fmt.Errorf("decode revision %d: %w", rev.RevisionID, err)
+	}
+	return rev, nil
+}
+
+// loadRevision 读取属于指定实体的一条修订。
+func loadRevision(ctx context.Context, entity string, id, revisionID int) (Revision, error) {
+	row := queryRow(ctx, "get_revision", `SELECT revision_id, entity, entity_id, actor, created_at, comment, previous, data
+		FROM revisions WHERE revision_id = ? AND entity = ? AND entity_id = ?`, revisionID, entity, id)
+	rev, err := scanRevision(row)
+	if err == sql.ErrNoRows {
+		return rev, apierror.NotFound(apierror.CodeRevisionNotFound, gin.H{"revision_id": revisionID})
+	}
+	if err != nil {
+		return rev, apierror.Storage(fmt.Errorf("query revision %d: %w", revisionID, err))
+	}
+	return rev, nil
+}
+
+// revertComment 返回回退操作的修订说明，默认注明回退的修订号。
+func revertComment(c *gin.Context, revisionID int) string {
+	if comment := c.Query("comment"); comment != "" {
+		return comment
+	}
+	return fmt.Sprintf("revert revision %d", revisionID)
+}
+
+// 回退作者：恢复到指定修订之前的字段值，回退本身也记录为一条新修订
+func revertAuthor(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	revID, ok := paramID(c, "rev")
+	if !ok {
+		return
+	}
+	rev, err := loadRevision(c.Request.Context(), entityAuthor, id, revID)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	_, newRev, err := updateAuthorRow(c.Request.Context(), id, auditOf(c), revertComment(c, revID), func(author *Author) error {
+		if err := decodeFields(rev.Previous, author); err != nil {
+			return apierror.Storage(err)
+		}
+		return validateAuthor(*author)
+	})
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Author reverted", "revision_id": newRev})
+}
+
+// 回退诗作，规则同回退作者
+func revertPoem(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	revID, ok := paramID(c, "rev")
+	if !ok {
+		return
+	}
+	rev, err := loadRevision(c.Request.Context(), entityPoem, id, revID)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	_, newRev, err := updatePoemRow(c.Request.Context(), id, auditOf(c), revertComment(c, revID), func(poem *Poem) error {
+		if err := decodeFields(rev.Previous, poem); err != nil {
+			return apierror.Storage(err)
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Poem reverted", "revision_id": newRev})
+}
+
+// decodeFields 把修订中保存的字段值写回 dst。
+func decodeFields(fields map[string]any, dst any) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
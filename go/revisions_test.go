@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"poetry/textdiff"
+)
+
+// 长字段和编辑距离过大的字段不逐字比较
+func TestDiffFields(t *testing.T) {
+	long := strings.Repeat("春", maxDiffRunes/2)
+	tests := []struct {
+		name          string
+		before, after string
+		want          []textdiff.Edit
+	}{
+		{"short", "床前明月光", "床前看月光", []textdiff.Edit{{Op: textdiff.Equal, Text: "床前"}, {Op: textdiff.Delete, Text: "明"}, {Op: textdiff.Insert, Text: "看"}, {Op: textdiff.Equal, Text: "月光"}}},
+		{"at limit", long, long[:len(long)-len("春")] + "秋", []textdiff.Edit{{Op: textdiff.Equal, Text: long[:len(long)-len("春")]}, {Op: textdiff.Delete, Text: "春"}, {Op: textdiff.Insert, Text: "秋"}}},
+		{"above limit", long, long + "秋", []textdiff.Edit{{Op: textdiff.Delete, Text: long}, {Op: textdiff.Insert, Text: long + "秋"}}},
+		{"above limit from empty", "", long + long + "秋", []textdiff.Edit{{Op: textdiff.Insert, Text: long + long + "秋"}}},
+		{"too many edits", strings.Repeat("一甲", maxDiffEdits/2+1), strings.Repeat("一乙", maxDiffEdits/2+1), []textdiff.Edit{
+			{Op: textdiff.Equal, Text: "一"},
+			{Op: textdiff.Delete, Text: strings.Repeat("甲一", maxDiffEdits/2) + "甲"},
+			{Op: textdiff.Insert, Text: strings.Repeat("乙一", maxDiffEdits/2) + "乙"},
+		}},
+	}
+	for _, tt := range tests {
+		got := diffFields(map[string]any{"content": tt.before}, map[string]any{"content": tt.after})
+		if !reflect.DeepEqual(got["content"], tt.want) {
+			t.Errorf("%s: diff %v, want %v", tt.name, got["content"], tt.want)
+		}
+	}
+}
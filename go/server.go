@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// HTTP 服务的超时设置。导出等流式接口由 extendWriteDeadline 延长到 streamWriteTimeout，
+// 导入等大文件上传由 extendReadDeadline 延长到 uploadReadTimeout。
+const (
+	readHeaderTimeout  = 5 * time.Second
+	readTimeout        = 15 * time.Second
+	uploadReadTimeout  = 15 * time.Minute
+	writeTimeout       = 2 * time.Minute
+	streamWriteTimeout = 30 * time.Minute
+	idleTimeout        = 2 * time.Minute
+	shutdownTimeout    = 30 * time.Second
+	readyCheckTimeout  = 2 * time.Second
+)
+
+// shuttingDown 在收到退出信号后置位，/readyz 随即返回 503，负载均衡停止转发新请求
+var shuttingDown atomic.Bool
+
+// serve 在 addr 上启动 HTTP 服务，rpc 不为 nil 时同时启动 gRPC 服务。收到 SIGINT/SIGTERM 后停止接收新连接，
+// 等待进行中的请求和调用完成（最多 shutdownTimeout）后返回。任一服务异常退出时关闭另一个并返回错误。
+func serve(addr string, handler http.Handler, rpc *rpcServer) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rpcErrc := make(chan error, 1)
+	if rpc != nil {
+		if err := rpc.start(rpcErrc); err != nil {
+			return fmt.Errorf("start gRPC server: %w", err)
+		}
+	}
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "addr", addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		if rpc != nil {
+			rpc.srv.Stop()
+		}
+		return err
+	case err := <-rpcErrc:
+		srv.Close()
+		return fmt.Errorf("gRPC server: %w", err)
+	case <-ctx.Done():
+	}
+	// 再次收到信号时按默认行为立即退出
+	stop()
+
+	slog.Info("Shutting down, draining in-flight requests", "timeout", shutdownTimeout.String())
+	shuttingDown.Store(true)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if rpc != nil {
+		go rpc.shutdown(shutdownCtx)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	if rpc != nil {
+		// GracefulStop 完成后 Serve 返回 nil
+		if err := <-rpcErrc; err != nil {
+			return fmt.Errorf("gRPC server: %w", err)
+		}
+	}
+	slog.Info("Server stopped")
+	return nil
+}
+
+// extendWriteDeadline 把当前响应的写超时延长到 streamWriteTimeout，在开始写出流式响应前调用。
+// 全部诗作的导出在慢速连接上会超过 writeTimeout。
+func extendWriteDeadline(c *gin.Context) {
+	err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger(c).Warn("Failed to extend write deadline", "error", err)
+	}
+}
+
+// abortKey 标记需要中断连接的请求，见 abortResponse
+const abortKey = "abort_response"
+
+// abortResponse 在流式响应已开始后出错时调用：记录错误并标记请求，由 abortConnection 中断连接。
+// 客户端读到不完整的分块编码（unexpected EOF），不会把截断的导出当作完整的文件。
+// 还没有写出任何内容时照常返回错误响应。
+func abortResponse(c *gin.Context, err error) {
+	if !c.Writer.Written() {
+		c.Header("Content-Disposition", "")
+		apierror.Write(c, err)
+		return
+	}
+	c.Error(err)
+	c.Set(abortKey, true)
+	c.Abort()
+}
+
+// abortConnection 是最外层的中间件：日志、指标和追踪照常记录后，
+// 以 http.ErrAbortHandler 中断被 abortResponse 标记的请求的连接。
+func abortConnection(c *gin.Context) {
+	c.Next()
+	if c.GetBool(abortKey) {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// extendReadDeadline 把当前请求的读超时延长到 uploadReadTimeout，在读取请求体前调用。
+// readTimeout 包含读取请求体的时间，接近 maxImportBytes 的上传在慢速连接上会被中途断开。
+// 写超时从读完请求头开始计算，同时延长，读完请求体后仍有 writeTimeout 写出响应。
+func extendReadDeadline(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	now := time.Now()
+	err := rc.SetReadDeadline(now.Add(uploadReadTimeout))
+	if err == nil {
+		err = rc.SetWriteDeadline(now.Add(uploadReadTimeout + writeTimeout))
+	}
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger(c).Warn("Failed to extend read deadline", "error", err)
+	}
+}
+
+// 存活检查：进程能处理请求即返回 200
+func healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// 就绪检查：数据库可连接、数据库结构变更已全部执行、诗词数据已导入时返回 200，否则返回 503。
+// 接口无需认证，数据库错误只写入日志，响应中为 error。
+func readyz(c *gin.Context) {
+	checks := gin.H{}
+	ready := true
+	fail := func(name, reason string) {
+		checks[name] = reason
+		ready = false
+	}
+	failErr := func(name string, err error) {
+		logger(c).Error("Readiness check error", "check", name, "error", err)
+		fail(name, "error")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyCheckTimeout)
+	defer cancel()
+
+	if shuttingDown.Load() {
+		fail("server", "shutting down")
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		failErr("database", err)
+	} else {
+		checks["database"] = "ok"
+
+		var version int
+		latest := migrations[len(migrations)-1].version
+		if err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+			failErr("migrations", err)
+		} else if version < latest {
+			fail("migrations", "pending")
+		} else {
+			checks["migrations"] = "ok"
+		}
+
+		// app.py 导入完成后作者和诗作都不为空
+		var authors, poems bool
+		err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM Authors), EXISTS (SELECT 1 FROM Poems)`).Scan(&authors, &poems)
+		switch {
+		case err != nil:
+			failErr("import", err)
+		case !authors || !poems:
+			fail("import", "no data, run app.py")
+		default:
+			checks["import"] = "ok"
+		}
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+		logger(c).Warn("Readiness check failed", "checks", checks)
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// PoemSource 是诗作在 chinese-poetry 原始数据中的出处，重新导入后仍可据此引用同一首诗。
+type PoemSource struct {
+	ID    string `json:"id,omitempty"`   // 原始数据中的 UUID，唐诗补录.json 中部分诗作没有
+	File  string `json:"file,omitempty"` // 如 poet.tang.0.json，通过 NDJSON 请求体导入时为空
+	Index int    `json:"index"`          // 在文件中的序号，从 0 开始
+	Notes string `json:"notes,omitempty"`
+}
+
+// poemColumns 是读取 Poem 时的列，与 scanPoem 对应
+const poemColumns = "poem_id, title, author_id, content, source_id, source_file, source_index, source_notes"
+
+// scanPoem 读取按 poemColumns 查询的一行，prefix 是查询结果中位于这些列之前的列。
+func scanPoem(row rowScanner, prefix ...any) (Poem, error) {
+	var p Poem
+	var id, file, notes sql.NullString
+	var index sql.NullInt64
+	dest := append(prefix, &p.PoemID, &p.Title, &p.AuthorID, &p.Content, &id, &file, &index, &notes)
+	if err := row.Scan(dest...); err != nil {
+		return p, err
+	}
+	if file.Valid {
+		p.Source = &PoemSource{ID: id.String, File: file.String, Index: int(index.Int64), Notes: notes.String}
+	}
+	return p, nil
+}
+
+// setPoemSource 在 tx 中记录诗作的出处。
+func setPoemSource(tx *tracedTx, id int, src PoemSource) error {
+	_, err := tx.exec("set_poem_source", `UPDATE Poems SET source_id = NULLIF(?, ''), source_file = ?, source_index = ?, source_notes = NULLIF(?, '')
+		WHERE poem_id = ?`, strings.ToLower(src.ID), src.File, src.Index, src.Notes, id)
+	if err != nil {
+		return fmt.Errorf("set source of poem %d: %w", id, err)
+	}
+	return nil
+}
+
+// migratePoemSources 为诗作添加出处列。app.py 导入时会写入出处；此前导入的数据库从 corpusDir 中的原始数据补全。
+func migratePoemSources(tx *sql.Tx) error {
+	for _, col := range [][2]string{
+		{"source_id", "TEXT"}, {"source_file", "TEXT"}, {"source_index", "INTEGER"}, {"source_notes", "TEXT"},
+	} {
+		if err := addColumn(tx, "Poems", col[0], col[1]); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_poems_source_id ON Poems (source_id)"); err != nil {
+		return err
+	}
+	return backfillPoemSources(tx)
+}
+
+// backfillPoemSources 把原始数据中的诗作对应到还没有出处的诗作上：先按作者、标题和内容精确匹配
+// （app.py 按文件顺序导入，重复的诗按顺序对应），剩下的按作者和标题匹配，只在两边都唯一时对应，
+// 以覆盖导入后修改过内容的诗作。
+func backfillPoemSources(tx *sql.Tx) error {
+	if _, err := os.Stat(corpusDir); errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Corpus not found, poem sources not filled", "dir", corpusDir)
+		return nil
+	}
+	ttx := withTx(context.Background(), tx)
+
+	authors := map[string]int{}
+	rows, err := tx.Query("SELECT author_id, name FROM Authors")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		authors[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// 按作者、标题和内容排队的诗作 ID，按 poem_id 顺序
+	exact := map[string][]int{}
+	rows, err = tx.Query("SELECT poem_id, author_id, title, content FROM Poems WHERE source_file IS NULL ORDER BY poem_id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, authorID int
+		var title, content string
+		if err := rows.Scan(&id, &authorID, &title, &content); err != nil {
+			return err
+		}
+		key := titleKey(authorID, title) + "\x00" + content
+		exact[key] = append(exact[key], id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	matched := 0
+	unmatched := map[string][]PoemSource{} // 未精确匹配的原始诗作，按作者和标题
+	err = eachCorpusFile(corpusDir, func(file string, poems []sourcePoem) error {
+		for _, sp := range poems {
+			authorID, ok := authors[sp.Author]
+			if !ok {
+				continue
+			}
+			src := PoemSource{ID: sp.ID, File: file, Index: sp.index, Notes: string(sp.Notes)}
+			key := titleKey(authorID, sp.Title) + "\x00" + sp.content()
+			ids := exact[key]
+			if len(ids) == 0 {
+				tk := titleKey(authorID, sp.Title)
+				unmatched[tk] = append(unmatched[tk], src)
+				continue
+			}
+			if err := setPoemSource(ttx, ids[0], src); err != nil {
+				return err
+			}
+			exact[key] = ids[1:]
+			matched++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 剩下的诗作按作者和标题分组
+	remaining := map[string][]int{}
+	for key, ids := range exact {
+		tk := key[:strings.LastIndex(key, "\x00")]
+		remaining[tk] = append(remaining[tk], ids...)
+	}
+	left := 0
+	for tk, ids := range remaining {
+		if srcs := unmatched[tk]; len(ids) == 1 && len(srcs) == 1 {
+			if err := setPoemSource(ttx, ids[0], srcs[0]); err != nil {
+				return err
+			}
+			matched++
+			continue
+		}
+		left += len(ids)
+	}
+	slog.Info("Filled poem sources", "matched", matched, "unmatched", left)
+	return nil
+}
+
+// 按原始数据中的 UUID 查找诗作，UUID 不区分大小写
+func getPoemBySource(c *gin.Context) {
+	uuid := strings.ToLower(c.Param("uuid"))
+	if !isUUID(uuid) {
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidID, gin.H{"param": "uuid"}))
+		return
+	}
+	poem, err := scanPoem(queryRow(c.Request.Context(), "get_poem_by_source",
+		"SELECT "+poemColumns+" FROM Poems WHERE source_id = ? AND deleted_at IS NULL", uuid))
+	if err == sql.ErrNoRows {
+		apierror.Write(c, apierror.NotFound(apierror.CodePoemNotFound, gin.H{"source_id": uuid}))
+		return
+	}
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("query poem by source %s: %w", uuid, err)))
+		return
+	}
+	c.JSON(http.StatusOK, poem)
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+	"poetry/textdiff"
+)
+
+// 投稿类型：新诗、诗作勘误、作者小传
+const (
+	kindNewPoem        = "new_poem"
+	kindPoemCorrection = "poem_correction"
+	kindAuthorBio      = "author_bio"
+)
+
+// 投稿状态
+const (
+	statusPending  = "pending"
+	statusApproved = "approved"
+	statusRejected = "rejected"
+)
+
+// Submission 是待审核的投稿。Changes 只包含投稿修改的字段，审核通过时应用到当时的记录上，
+// 因此投稿之后编辑对其他字段的修改不会被覆盖。
+type Submission struct {
+	SubmissionID  int                        `json:"submission_id"`
+	Kind          string                     `json:"kind"`
+	EntityID      *int                       `json:"entity_id"` // 新诗在审核通过后才有 ID
+	Changes       map[string]any             `json:"changes"`
+	Comment       string                     `json:"comment"` // 投稿说明
+	Status        string                     `json:"status"`
+	SubmittedBy   string                     `json:"submitted_by"`
+	SubmittedAt   string                     `json:"submitted_at"`
+	ReviewedBy    *string                    `json:"reviewed_by"`
+	ReviewedAt    *string                    `json:"reviewed_at"`
+	ReviewComment *string                    `json:"review_comment"`
+	RevisionID    *int64                     `json:"revision_id,omitempty"`
+	Diff          map[string][]textdiff.Edit `json:"diff"` // 已通过的投稿与通过前的记录比较，其余与当前记录比较
+
+	previous map[string]any
+}
+
+// changedFields 返回 after 中与 before 不同的字段。
+func changedFields(before, after map[string]any) map[string]any {
+	changes := map[string]any{}
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			changes[k] = v
+		}
+	}
+	return changes
+}
+
+// insertSubmission 保存一条待审核的投稿并返回 201。
+func insertSubmission(c *gin.Context, kind string, entityID *int, changes map[string]any) {
+	if len(changes) == 0 {
+		apierror.Write(c, apierror.Validation(apierror.CodeNoChanges, nil))
+		return
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	result, err := exec(c.Request.Context(), "insert_submission", `INSERT INTO submissions (kind, entity_id, changes, comment, submitted_by, submitted_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, kind, entityID, string(data), c.Query("comment"), actor(c), now())
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("insert submission: %w", err)))
+		return
+	}
+	id, _ := result.LastInsertId()
+	c.JSON(http.StatusCreated, gin.H{"message": "Submission received", "submission_id": id, "status": statusPending})
+}
+
+// 投稿新诗，字段同创建诗作
+func submitPoem(c *gin.Context) {
+	var poem Poem
+	if err := c.ShouldBindJSON(&poem); err != nil {
+		apierror.Write(c, bodyError(err))
+		return
+	}
+	if err := validatePoem(c.Request.Context(), poem); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	insertSubmission(c, kindNewPoem, nil, poemFields(poem))
+}
+
+// 投稿诗作勘误，请求体按 JSON Merge Patch 修改 title、content
+func submitPoemCorrection(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	current, err := loadPoem(c.Request.Context(), id)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	fix := struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}{current.Title, current.Content}
+	if err := applyBody(c, &fix); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	poem := current
+	poem.Title, poem.Content = fix.Title, fix.Content
+	if err := validatePoem(c.Request.Context(), poem); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	insertSubmission(c, kindPoemCorrection, &id, changedFields(poemFields(current), poemFields(poem)))
+}
+
+// 投稿作者小传，请求体为 {"description": "..."}
+func submitAuthorBio(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	current, err := loadAuthor(c.Request.Context(), id)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	bio := struct {
+		Description string `json:"description"`
+	}{current.Description}
+	if err := applyBody(c, &bio, "description"); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	author := current
+	author.Description = bio.Description
+	if err := validateAuthor(author); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	insertSubmission(c, kindAuthorBio, &id, changedFields(authorFields(current), authorFields(author)))
+}
+
+const submissionColumns = `SELECT submission_id, kind, entity_id, changes, comment, status, submitted_by, submitted_at,
+	reviewed_by, reviewed_at, review_comment, previous, revision_id FROM submissions`
+
+func scanSubmission(row rowScanner) (Submission, error) {
+	var s Submission
+	var entityID sql.NullInt64
+	var changes string
+	var previous sql.NullString
+	var revisionID sql.NullInt64
+	if err := row.Scan(&s.SubmissionID, &s.Kind, &entityID, &changes, &s.Comment, &s.Status, &s.SubmittedBy, &s.SubmittedAt,
+		&s.ReviewedBy, &s.ReviewedAt, &s.ReviewComment, &previous, &revisionID); err != nil {
+		return s, err
+	}
+	if entityID.Valid {
+		id := int(entityID.Int64)
+		s.EntityID = &id
+	}
+	if revisionID.Valid {
+		s.RevisionID = &revisionID.Int64
+	}
+	if err := json.Unmarshal([]byte(changes), &s.Changes); err != nil {
+		return s, fmt.Errorf("decode submission %d: %w", s.SubmissionID, err)
+	}
+	if previous.Valid {
+		if err := json.Unmarshal([]byte(previous.String), &s.previous); err != nil {
+			return s, fmt.Errorf("decode submission %d: %w", s.SubmissionID, err)
+		}
+	}
+	return s, nil
+}
+
+// currentFields 返回投稿针对的记录当前的字段值，新诗或记录已删除时为空。
+func (s Submission) currentFields(ctx context.Context) (map[string]any, error) {
+	if s.EntityID == nil || s.Kind == kindNewPoem {
+		return map[string]any{}, nil
+	}
+	var fields map[string]any
+	var err error
+	switch s.Kind {
+	case kindPoemCorrection:
+		var p Poem
+		p, err = loadPoem(ctx, *s.EntityID)
+		fields = poemFields(p)
+	case kindAuthorBio:
+		var a Author
+		a, err = loadAuthor(ctx, *s.EntityID)
+		fields = authorFields(a)
+	}
+	var e *apierror.Error
+	if err != nil && errors.As(err, &e) && e.Kind == apierror.KindNotFound {
+		return map[string]any{}, nil
+	}
+	return fields, err
+}
+
+// withDiff 计算投稿修改的字段与基准记录之间的差异。
+func (s *Submission) withDiff(ctx context.Context) error {
+	base := s.previous
+	if s.Status != statusApproved {
+		current, err := s.currentFields(ctx)
+		if err != nil {
+			return err
+		}
+		base = current
+	}
+	before := map[string]any{}
+	for k := range s.Changes {
+		if v, ok := base[k]; ok {
+			before[k] = v
+		}
+	}
+	s.Diff = diffFields(before, s.Changes)
+	return nil
+}
+
+// loadSubmission 读取一条投稿，不存在时返回 404。
+func loadSubmission(ctx context.Context, id int) (Submission, error) {
+	s, err := scanSubmission(queryRow(ctx, "get_submission", submissionColumns+" WHERE submission_id = ?", id))
+	if err == sql.ErrNoRows {
+		return s, apierror.NotFound(apierror.CodeSubmissionNotFound, gin.H{"submission_id": id})
+	}
+	if err != nil {
+		return s, apierror.Storage(fmt.Errorf("query submission %d: %w", id, err))
+	}
+	return s, nil
+}
+
+// loadSubmissionTx 在事务中读取一条投稿，供审核使用。
+func loadSubmissionTx(tx *tracedTx, id int) (Submission, error) {
+	s, err := scanSubmission(tx.queryRow("get_submission", submissionColumns+" WHERE submission_id = ?", id))
+	if err == sql.ErrNoRows {
+		return s, apierror.NotFound(apierror.CodeSubmissionNotFound, gin.H{"submission_id": id})
+	}
+	if err != nil {
+		return s, apierror.Storage(fmt.Errorf("query submission %d: %w", id, err))
+	}
+	return s, nil
+}
+
+// canReview 判断调用方是否可以审核投稿（editor 及以上）。
+func canReview(c *gin.Context) bool {
+	id := identity(c)
+	return id != nil && roleLevels[id.Role] >= roleLevels[roleEditor]
+}
+
+// 获取投稿列表（分页，最新的在前），可用 ?status=&kind=&submitted_by= 过滤。
+// 编辑可以查看全部投稿，其他用户只能看到自己的投稿。
+func getSubmissions(c *gin.Context) {
+	status := c.DefaultQuery("status", statusPending)
+	var conds []string
+	var args []any
+	switch status {
+	case statusPending, statusApproved, statusRejected:
+		conds = append(conds, "status = ?")
+		args = append(args, status)
+	case "all":
+	default:
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{
+			"param":   "status",
+			"allowed": []string{statusPending, statusApproved, statusRejected, "all"},
+		}))
+		return
+	}
+	if kind := c.Query("kind"); kind != "" {
+		conds = append(conds, "kind = ?")
+		args = append(args, kind)
+	}
+	submitter := c.Query("submitted_by")
+	if !canReview(c) {
+		submitter = actor(c)
+	}
+	if submitter != "" {
+		conds = append(conds, "submitted_by = ?")
+		args = append(args, submitter)
+	}
+	where := " WHERE " + strings.Join(conds, " AND ")
+	if len(conds) == 0 {
+		where = ""
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	const pageSize = 20
+	offset := (page - 1) * pageSize
+
+	var total int
+	if err := queryRow(c.Request.Context(), "count_submissions", "SELECT COUNT(*) FROM submissions"+where, args...).Scan(&total); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("count submissions: %w", err)))
+		return
+	}
+
+	rows, err := query(c.Request.Context(), "list_submissions", submissionColumns+where+" ORDER BY submission_id DESC LIMIT ? OFFSET ?", append(args, pageSize, offset)...)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("query submissions: %w", err)))
+		return
+	}
+	submissions := []Submission{}
+	for rows.Next() {
+		s, err := scanSubmission(rows)
+		if err != nil {
+			rows.Close()
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		submissions = append(submissions, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		apierror.Write(c, apierror.Storage(err))
+		return
+	}
+
+	// 读完列表再逐条加载当前记录计算差异
+	for i := range submissions {
+		if err := submissions[i].withDiff(c.Request.Context()); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+	}
+
+	setResultCount(c, len(submissions))
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"data":      submissions,
+	})
+}
+
+// 获取单条投稿及其差异，非编辑只能查看自己的投稿
+func getSubmission(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	s, err := loadSubmission(c.Request.Context(), id)
+	if err == nil && !canReview(c) && s.SubmittedBy != actor(c) {
+		// 不暴露他人投稿是否存在
+		err = apierror.NotFound(apierror.CodeSubmissionNotFound, gin.H{"submission_id": id})
+	}
+	if err == nil {
+		err = s.withDiff(c.Request.Context())
+	}
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// 审核通过：把投稿应用到正式数据（新建诗作或记录一条修订），与投稿状态在同一事务中更新
+func approveSubmission(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	// 投稿和它修改的记录都在事务中读取和校验，审核期间记录被其他请求修改也不会被覆盖
+	tx, err := begin(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("begin transaction: %w", err)))
+		return
+	}
+	defer tx.Rollback()
+
+	s, err := loadSubmissionTx(tx, id)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	if s.Status != statusPending {
+		apierror.Write(c, apierror.Conflict(apierror.CodeSubmissionReviewed, gin.H{"submission_id": id, "status": s.Status}))
+		return
+	}
+
+	// 修订说明注明来源投稿，附上审核意见
+	comment := fmt.Sprintf("submission %d by %s", s.SubmissionID, s.SubmittedBy)
+	if rc := c.Query("comment"); rc != "" {
+		comment += ": " + rc
+	}
+
+	a := auditOf(c)
+	var entityID, revisionID int64
+	var previous map[string]any
+	switch s.Kind {
+	case kindNewPoem:
+		var poem Poem
+		if err := decodeFields(s.Changes, &poem); err != nil {
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		if err := validatePoemTx(tx, poem); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		if entityID, err = insertPoem(tx, poem, a); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+	case kindPoemCorrection:
+		current, err := loadPoemTx(tx, *s.EntityID)
+		if err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		poem := current
+		if err := decodeFields(s.Changes, &poem); err != nil {
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		if err := validatePoemTx(tx, poem); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		previous = poemFields(current)
+		entityID = int64(current.PoemID)
+		if revisionID, err = updatePoemTx(tx, current.PoemID, current, poem, a, comment); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+	case kindAuthorBio:
+		current, err := loadAuthorTx(tx, *s.EntityID)
+		if err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		author := current
+		if err := decodeFields(s.Changes, &author); err != nil {
+			apierror.Write(c, apierror.Storage(err))
+			return
+		}
+		if err := validateAuthor(author); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+		previous = authorFields(current)
+		entityID = int64(current.AuthorID)
+		if revisionID, err = updateAuthorTx(tx, current.AuthorID, current, author, a, comment); err != nil {
+			apierror.Write(c, err)
+			return
+		}
+	}
+
+	if err := reviewSubmission(tx, id, statusApproved, actor(c), c.Query("comment")); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	prev, err := auditJSON(previous)
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	rev := sql.NullInt64{Int64: revisionID, Valid: revisionID > 0}
+	if _, err := tx.exec("update_submission_entity", "UPDATE submissions SET entity_id = ?, previous = ?, revision_id = ? WHERE submission_id = ?",
+		entityID, prev, rev, id); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("update submission %d: %w", id, err)))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("commit: %w", err)))
+		return
+	}
+	if s.Kind == kindAuthorBio {
+		invalidate(entityAuthor, int(entityID))
+	} else {
+		invalidate(entityPoem, int(entityID))
+	}
+
+	resp := gin.H{"message": "Submission approved", "entity_id": entityID}
+	if rev.Valid {
+		resp["revision_id"] = revisionID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// 驳回投稿，必须通过 ?comment= 说明原因
+func rejectSubmission(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	comment := c.Query("comment")
+	if comment == "" {
+		apierror.Write(c, apierror.Validation(apierror.CodeMissingParam, gin.H{"param": "comment"}))
+		return
+	}
+
+	tx, err := begin(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("begin transaction: %w", err)))
+		return
+	}
+	defer tx.Rollback()
+
+	if err := reviewSubmission(tx, id, statusRejected, actor(c), comment); err != nil {
+		apierror.Write(c, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("commit: %w", err)))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Submission rejected"})
+}
+
+// reviewSubmission 把待审核的投稿标记为 status，投稿不存在返回 404，已审核返回 409。
+func reviewSubmission(tx *tracedTx, id int, status, by, comment string) error {
+	result, err := tx.exec("review_submission", `UPDATE submissions SET status = ?, reviewed_by = ?, reviewed_at = ?, review_comment = ?
+		WHERE submission_id = ? AND status = ?`, status, by, now(), comment, id, statusPending)
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("review submission %d: %w", id, err))
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var current string
+	err = tx.queryRow("submission_status", "SELECT status FROM submissions WHERE submission_id = ?", id).Scan(&current)
+	if err == sql.ErrNoRows {
+		return apierror.NotFound(apierror.CodeSubmissionNotFound, gin.H{"submission_id": id})
+	}
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("query submission %d: %w", id, err))
+	}
+	return apierror.Conflict(apierror.CodeSubmissionReviewed, gin.H{"submission_id": id, "status": current})
+}
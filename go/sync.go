@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"poetry/textdiff"
+)
+
+// 同步时每首诗作的处理方式
+const (
+	syncAdd      = "add"
+	syncChange   = "change"
+	syncDelete   = "delete"
+	syncConflict = "conflict"
+	syncSkip     = "skip"
+)
+
+// 冲突和跳过的原因
+const (
+	syncEdited        = "edited locally" // 本地修改过，原始数据也有变化
+	syncTrashed       = "in trash"       // 本地已删除，原始数据有变化
+	syncUnknownAuthor = "unknown author" // 作者不在 Authors 中
+	syncDuplicateID   = "duplicate id"   // 原始数据中有多首诗作使用同一个 id
+)
+
+// syncItem 是同步结果中的一首诗作。
+type syncItem struct {
+	action   string
+	reason   string
+	upstream string // 冲突时原始数据的变化：change 或 delete
+	id       string
+	poemID   int
+	author   string
+	title    string
+	source   PoemSource
+	previous Poem // 本地的诗作
+	poem     Poem // 原始数据中的诗作
+}
+
+// syncResult 是一次同步的结果，items 不含没有变化的诗作。
+type syncResult struct {
+	items     []syncItem
+	summary   map[string]int
+	unchanged int
+	noID      int            // 原始数据中没有 id、无法匹配的诗作
+	names     map[int]string // 作者名，包括已删除的作者
+}
+
+// syncLocal 是有原始 id 的本地诗作。
+type syncLocal struct {
+	poem     Poem
+	source   PoemSource
+	baseline string // 上次同步时原始数据的摘要
+	synced   bool   // 是否已记录摘要
+	deleted  bool
+	seen     bool
+}
+
+// sourceHash 返回诗作作者、标题和内容的摘要，用于判断原始数据和本地数据自上次同步以来是否有变化。
+func sourceHash(p Poem) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s", p.AuthorID, p.Title, p.Content)))
+	return hex.EncodeToString(sum[:])
+}
+
+func setSourceHash(tx *tracedTx, id int, hash string) error {
+	if _, err := tx.exec("set_source_hash", "UPDATE Poems SET source_hash = ? WHERE poem_id = ?", hash, id); err != nil {
+		return fmt.Errorf("set source hash of poem %d: %w", id, err)
+	}
+	return nil
+}
+
+// loadSyncLocals 读取有原始 id 的诗作。从未同步过的诗作以导入时的内容为基准：
+// 有修订时为第一次修订前的内容，否则为当前内容。
+func loadSyncLocals(tx *tracedTx) (map[string]*syncLocal, error) {
+	locals := map[string]*syncLocal{}
+	byPoem := map[int]*syncLocal{}
+	rows, err := tx.query("sync_poems", `SELECT COALESCE(source_hash, ''), deleted_at IS NOT NULL, `+poemColumns+`
+		FROM Poems WHERE source_file IS NOT NULL AND source_id IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("query poems: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		l := &syncLocal{}
+		var hash string
+		l.poem, err = scanPoem(rows, &hash, &l.deleted)
+		if err != nil {
+			return nil, fmt.Errorf("scan poem: %w", err)
+		}
+		l.source, l.poem.Source = *l.poem.Source, nil
+		l.baseline, l.synced = hash, hash != ""
+		if !l.synced {
+			l.baseline = sourceHash(l.poem)
+		}
+		locals[l.source.ID] = l
+		byPoem[l.poem.PoemID] = l
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read poems: %w", err)
+	}
+
+	rows, err = tx.query("sync_baselines", `SELECT entity_id, previous FROM revisions WHERE revision_id IN (
+		SELECT MIN(revision_id) FROM revisions WHERE entity = ? GROUP BY entity_id)`, entityPoem)
+	if err != nil {
+		return nil, fmt.Errorf("query revisions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var previous string
+		if err := rows.Scan(&id, &previous); err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		l := byPoem[id]
+		if l == nil || l.synced {
+			continue
+		}
+		var p Poem
+		if err := json.Unmarshal([]byte(previous), &p); err != nil {
+			return nil, fmt.Errorf("decode revision of poem %d: %w", id, err)
+		}
+		l.baseline = sourceHash(p)
+	}
+	return locals, rows.Err()
+}
+
+// syncCorpus 在 tx 中把 dir 中的原始数据同步到数据库：按 id 匹配，新增原始数据中新增的诗作，
+// 原始数据有变化而本地没有修改过的诗作更新为原始数据，原始数据中已删除的诗作移入回收站。
+// 本地修改过的诗作记为冲突，force 时以原始数据覆盖。只处理 dir 中存在的文件里的诗作。
+func syncCorpus(tx *tracedTx, dir string, force bool, a requestAudit, comment string) (*syncResult, error) {
+	result := &syncResult{summary: map[string]int{}, names: map[int]string{}}
+	authors := map[string]int{}
+	rows, err := tx.query("sync_authors", "SELECT author_id, name, deleted_at IS NOT NULL FROM Authors")
+	if err != nil {
+		return nil, fmt.Errorf("query authors: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name string
+		var deleted bool
+		if err := rows.Scan(&id, &name, &deleted); err != nil {
+			return nil, fmt.Errorf("scan author: %w", err)
+		}
+		result.names[id] = name
+		if !deleted {
+			authors[name] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read authors: %w", err)
+	}
+
+	locals, err := loadSyncLocals(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	err = eachCorpusFile(dir, func(file string, poems []sourcePoem) error {
+		files[file] = true
+		for _, sp := range poems {
+			id := strings.ToLower(sp.ID)
+			if !isUUID(id) {
+				result.noID++
+				continue
+			}
+			item := syncItem{id: id, author: sp.Author, title: sp.Title,
+				source: PoemSource{ID: id, File: file, Index: sp.index, Notes: string(sp.Notes)},
+				poem:   Poem{Title: sp.Title, AuthorID: authors[sp.Author], Content: sp.content()}}
+			if err := syncRecord(tx, locals, &item, force, a, comment); err != nil {
+				return err
+			}
+			result.add(item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 原始数据中已删除的诗作
+	var removed []*syncLocal
+	for _, l := range locals {
+		if !l.seen && !l.deleted && files[l.source.File] {
+			removed = append(removed, l)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].poem.PoemID < removed[j].poem.PoemID })
+	for _, l := range removed {
+		item := syncItem{action: syncDelete, id: l.source.ID, poemID: l.poem.PoemID, author: result.names[l.poem.AuthorID],
+			title: l.poem.Title, source: l.source, previous: l.poem}
+		if sourceHash(l.poem) != l.baseline && !force {
+			item.action, item.reason, item.upstream = syncConflict, syncEdited, syncDelete
+		} else if err := removePoemTx(tx, l.poem.PoemID, a); err != nil {
+			return nil, err
+		}
+		result.add(item)
+	}
+	return result, nil
+}
+
+// syncRecord 处理原始数据中的一首诗作并填写 item 的处理方式，没有变化时 action 为空。
+func syncRecord(tx *tracedTx, locals map[string]*syncLocal, item *syncItem, force bool, a requestAudit, comment string) error {
+	l := locals[item.id]
+	if l != nil && l.seen {
+		item.action, item.reason = syncSkip, syncDuplicateID
+		return nil
+	}
+	if item.poem.AuthorID == 0 {
+		if l != nil {
+			l.seen = true
+		}
+		item.action, item.reason = syncSkip, syncUnknownAuthor
+		return nil
+	}
+	upstream := sourceHash(item.poem)
+
+	if l == nil {
+		id, err := insertPoem(tx, item.poem, a)
+		if err != nil {
+			return err
+		}
+		item.action, item.poemID = syncAdd, int(id)
+		locals[item.id] = &syncLocal{seen: true}
+		if err := setPoemSource(tx, item.poemID, item.source); err != nil {
+			return err
+		}
+		return setSourceHash(tx, item.poemID, upstream)
+	}
+
+	l.seen = true
+	item.poemID, item.previous = l.poem.PoemID, l.poem
+	// 出处（文件、序号和说明）不能在本地修改，总是与原始数据一致
+	if l.source != item.source {
+		if err := setPoemSource(tx, item.poemID, item.source); err != nil {
+			return err
+		}
+	}
+
+	local := sourceHash(l.poem)
+	switch {
+	case upstream == l.baseline || upstream == local:
+		// 原始数据没有变化，或本地已与原始数据相同
+	case l.deleted:
+		item.action, item.reason, item.upstream = syncConflict, syncTrashed, syncChange
+		return nil
+	case local != l.baseline && !force:
+		item.action, item.reason, item.upstream = syncConflict, syncEdited, syncChange
+		return nil
+	default:
+		if _, err := updatePoemTx(tx, item.poemID, l.poem, item.poem, a, comment); err != nil {
+			return err
+		}
+		item.action = syncChange
+	}
+	if !l.synced || upstream != l.baseline {
+		return setSourceHash(tx, item.poemID, upstream)
+	}
+	return nil
+}
+
+func (r *syncResult) add(item syncItem) {
+	if item.action == "" {
+		r.unchanged++
+		return
+	}
+	r.summary[item.action]++
+	r.items = append(r.items, item)
+}
+
+// print 按 diff 的格式列出每首有变化的诗作：+ 新增、~ 更新、- 删除、! 冲突、? 跳过。
+// 更新和冲突列出变化的字段，内容只列出有差异的行，[-删除-]{+插入+}。
+func (r *syncResult) print(w io.Writer) {
+	marks := map[string]string{syncAdd: "+", syncChange: "~", syncDelete: "-", syncConflict: "!", syncSkip: "?"}
+	for _, item := range r.items {
+		line := fmt.Sprintf("%s %s %s《%s》", marks[item.action], item.id, item.author, item.title)
+		if item.poemID != 0 {
+			line += fmt.Sprintf(" poem_id=%d", item.poemID)
+		}
+		if item.reason != "" {
+			line += " (" + item.reason
+			if item.upstream == syncDelete {
+				line += ", deleted upstream"
+			}
+			line += ")"
+		}
+		fmt.Fprintln(w, line)
+		if item.action != syncChange && item.upstream != syncChange {
+			continue
+		}
+		if item.previous.Title != item.poem.Title {
+			fmt.Fprintf(w, "    title: %s → %s\n", item.previous.Title, item.poem.Title)
+		}
+		if item.previous.AuthorID != item.poem.AuthorID {
+			fmt.Fprintf(w, "    author: %s → %s\n", r.names[item.previous.AuthorID], r.names[item.poem.AuthorID])
+		}
+		for _, l := range diffLines(item.previous.Content, item.poem.Content) {
+			fmt.Fprintf(w, "    %s\n", l)
+		}
+	}
+}
+
+// diffLines 返回 a 变为 b 的字符级差异中有变化的行，换行符的增删显示为 ↵。
+func diffLines(a, b string) []string {
+	if a == b {
+		return nil
+	}
+	var sb strings.Builder
+	for _, e := range textdiff.Runes(a, b) {
+		switch e.Op {
+		case textdiff.Delete:
+			sb.WriteString("[-" + strings.ReplaceAll(e.Text, "\n", "↵") + "-]")
+		case textdiff.Insert:
+			sb.WriteString("{+" + strings.ReplaceAll(e.Text, "\n", "↵") + "+}")
+		default:
+			sb.WriteString(e.Text)
+		}
+	}
+	var lines []string
+	for _, l := range strings.Split(sb.String(), "\n") {
+		if strings.Contains(l, "[-") || strings.Contains(l, "{+") {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// runSync 实现 sync 命令：go run . sync [-dir ./全唐诗] [-dry-run] [-force] [-comment sync]
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", corpusDir, "chinese-poetry 的全唐诗目录")
+	dryRun := fs.Bool("dry-run", false, "只列出差异，不修改")
+	force := fs.Bool("force", false, "以原始数据覆盖本地修改过的诗作")
+	comment := fs.String("comment", "sync", "修订说明")
+	fs.Parse(args)
+
+	tx, err := begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := syncCorpus(tx, *dir, *force, cliAudit("sync"), *comment)
+	if err != nil {
+		return err
+	}
+	result.print(os.Stdout)
+
+	s := result.summary
+	summary := fmt.Sprintf("%d added, %d changed, %d deleted, %d conflicts, %d skipped, %d unchanged",
+		s[syncAdd], s[syncChange], s[syncDelete], s[syncConflict], s[syncSkip], result.unchanged)
+	if result.noID > 0 {
+		summary += fmt.Sprintf(", %d without id", result.noID)
+	}
+	if *dryRun {
+		fmt.Println("Would sync: " + summary)
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Println("Synced: " + summary)
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// syncID 返回测试中诗作 n 的原始 id
+func syncID(n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+}
+
+// writeCorpus 把 poems（原始 id 序号 -> 诗作）写成 dir 中的 poet.tang.0.json
+func writeCorpus(t *testing.T, dir string, poems map[int]Poem) {
+	t.Helper()
+	names := map[int]string{1: "李白", 2: "杜甫", 3: "王维"}
+	var recs []map[string]any
+	for n := 1; n <= 10; n++ {
+		p, ok := poems[n]
+		if !ok {
+			continue
+		}
+		recs = append(recs, map[string]any{"id": syncID(n), "author": names[p.AuthorID], "title": p.Title,
+			"paragraphs": strings.Split(p.Content, "\n")})
+	}
+	b, err := json.Marshal(recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "poet.tang.0.json"), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSyncBaseline 检查 sync 以上次同步时的原始数据为基准区分原始数据的变化和本地修改：
+// 从未同步过的诗作以第一次修订前的内容为基准，同步后以记录的摘要为基准。
+func TestSyncBaseline(t *testing.T) {
+	openTestDB(t)
+	srv := httptest.NewServer(setupRouter())
+	defer srv.Close()
+	dir := t.TempDir()
+
+	// 诗作 1 到 5 都来自原始数据，尚未同步过
+	original := map[int]Poem{}
+	rows, err := db.Query("SELECT poem_id, title, author_id, content FROM Poems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var p Poem
+		if err := rows.Scan(&p.PoemID, &p.Title, &p.AuthorID, &p.Content); err != nil {
+			t.Fatal(err)
+		}
+		original[p.PoemID] = p
+	}
+	rows.Close()
+	for id := range original {
+		if _, err := db.Exec("UPDATE Poems SET source_id = ?, source_file = 'poet.tang.0.json', source_index = ? WHERE poem_id = ?",
+			syncID(id), id-1, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edit := func(p Poem, line string) Poem {
+		p.Content += "\n" + line
+		return p
+	}
+	patch := func(id int, content string) {
+		b, _ := json.Marshal(map[string]string{"content": content})
+		doJSON(t, srv, http.MethodPatch, fmt.Sprintf("/api/poems/%d", id), strings.NewReader(string(b)), http.StatusOK, nil)
+	}
+	added := Poem{Title: "赠汪伦", AuthorID: 1, Content: "李白乘舟将欲行，忽闻岸上踏歌声。\n桃花潭水深千尺，不及汪伦送我情。"}
+
+	tests := []struct {
+		name   string
+		before func()       // 同步前的本地修改
+		corpus map[int]Poem // 原始数据
+		force  bool
+		want   map[int]string // 原始 id 序号 -> 处理方式，未列出的诗作没有变化
+		local  []int          // 保留本地内容、与原始数据不同的诗作
+	}{
+		{
+			name: "first sync",
+			before: func() {
+				patch(2, edit(original[2], "本地一").Content)
+				patch(3, edit(original[3], "本地二").Content)
+				doJSON(t, srv, http.MethodDelete, "/api/poems/5", nil, http.StatusOK, nil)
+			},
+			corpus: map[int]Poem{
+				1: edit(original[1], "上游一"), // 本地未修改：更新
+				2: original[2],              // 原始数据未变：保留本地修改
+				3: edit(original[3], "上游二"), // 两边都修改：冲突
+				// 4 在原始数据中删除，本地未修改：删除
+				5: edit(original[5], "上游三"), // 本地已删除：冲突
+				6: added,
+			},
+			want:  map[int]string{1: syncChange, 3: syncConflict, 4: syncDelete, 5: syncConflict, 6: syncAdd},
+			local: []int{2, 3, 5},
+		},
+		{
+			name:   "second sync uses recorded baseline",
+			before: func() { patch(1, edit(original[1], "上游一\n本地三").Content) },
+			corpus: map[int]Poem{
+				1: edit(original[1], "上游四"), // 同步后本地修改过：冲突
+				2: original[2],
+				3: edit(original[3], "上游二"),
+				5: edit(original[5], "上游三"),
+				6: edit(added, "上游五"), // 同步后本地未修改：更新
+			},
+			want:  map[int]string{1: syncConflict, 3: syncConflict, 5: syncConflict, 6: syncChange},
+			local: []int{1, 2, 3, 5},
+		},
+		{
+			name: "force",
+			corpus: map[int]Poem{
+				1: edit(original[1], "上游四"),
+				2: original[2],
+				3: edit(original[3], "上游二"),
+				5: edit(original[5], "上游三"),
+				6: edit(added, "上游五"),
+			},
+			force: true,
+			want:  map[int]string{1: syncChange, 3: syncChange, 5: syncConflict},
+			local: []int{2, 5},
+		},
+	}
+	for _, tt := range tests {
+		if tt.before != nil {
+			tt.before()
+		}
+		writeCorpus(t, dir, tt.corpus)
+
+		tx, err := begin(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := syncCorpus(tx, dir, tt.force, cliAudit("sync"), "sync")
+		if err != nil {
+			tx.Rollback()
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := map[int]string{}
+		for _, item := range result.items {
+			for n := range 10 {
+				if item.id == syncID(n) {
+					got[n] = item.action
+				}
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+
+		for n, p := range tt.corpus {
+			var content string
+			if err := db.QueryRow("SELECT content FROM Poems WHERE source_id = ?", syncID(n)).Scan(&content); err != nil {
+				t.Fatal(err)
+			}
+			if (content != p.Content) != slices.Contains(tt.local, n) {
+				t.Errorf("%s: poem %d content %q, upstream %q", tt.name, n, content, p.Content)
+			}
+		}
+	}
+}
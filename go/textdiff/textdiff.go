@@ -0,0 +1,154 @@
+// Package textdiff 计算两段文本之间按字符（rune）的差异，用于展示诗文修订。
+package textdiff
+
+// Op 是差异片段的类型。
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Edit 是一段连续的相同类型的差异。
+type Edit struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// MaxEditDistance 限制 Myers 算法的搜索深度。差异超过该值时，
+// 去掉公共前后缀后的中间部分直接表示为整段删除加整段插入。
+const MaxEditDistance = 2000
+
+// Runes 返回把 a 变为 b 的最短字符级编辑序列。
+func Runes(a, b string) []Edit {
+	return RunesWithin(a, b, MaxEditDistance)
+}
+
+// RunesWithin 同 Runes，但以 maxEdits 代替 MaxEditDistance 作为搜索深度。
+// 耗时为 O((N+M)·maxEdits)，内存为 O(maxEdits²)，供需要限制计算量的调用方使用。
+func RunesWithin(a, b string, maxEdits int) []Edit {
+	ra, rb := []rune(a), []rune(b)
+
+	// 先去掉公共前缀和后缀，诗文修订通常只改动个别字
+	prefix := 0
+	for prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(ra)-prefix && suffix < len(rb)-prefix && ra[len(ra)-1-suffix] == rb[len(rb)-1-suffix] {
+		suffix++
+	}
+
+	var edits []Edit
+	edits = appendEdit(edits, Equal, ra[:prefix])
+	midA, midB := ra[prefix:len(ra)-suffix], rb[prefix:len(rb)-suffix]
+	if middle, ok := myers(midA, midB, maxEdits); ok {
+		for _, e := range middle {
+			edits = appendEdit(edits, e.Op, []rune(e.Text))
+		}
+	} else {
+		edits = appendEdit(edits, Delete, midA)
+		edits = appendEdit(edits, Insert, midB)
+	}
+	edits = appendEdit(edits, Equal, ra[len(ra)-suffix:])
+	return edits
+}
+
+// appendEdit 追加一段差异，与前一段类型相同时合并。
+func appendEdit(edits []Edit, op Op, text []rune) []Edit {
+	if len(text) == 0 {
+		return edits
+	}
+	if n := len(edits); n > 0 && edits[n-1].Op == op {
+		edits[n-1].Text += string(text)
+		return edits
+	}
+	return append(edits, Edit{Op: op, Text: string(text)})
+}
+
+// myers 实现 Myers O(ND) 差异算法，编辑距离超过 maxEdits 时返回 false。
+func myers(a, b []rune, maxEdits int) ([]Edit, bool) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil, true
+	}
+	if max > 2*maxEdits+1 {
+		max = 2*maxEdits + 1
+	}
+	off := max + 1
+	v := make([]int, 2*off+1)
+	// trace[d] 保存第 d 步开始前 k ∈ [-d, d] 上的 v，用于回溯
+	var trace [][]int
+
+	for d := 0; d <= max && d <= maxEdits; d++ {
+		snap := make([]int, 2*d+1)
+		copy(snap, v[off-d:off+d+1])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(trace [][]int, a, b []rune) []Edit {
+	type step struct {
+		op Op
+		r  rune
+	}
+	var steps []step
+
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		get := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			steps = append(steps, step{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			steps = append(steps, step{Insert, b[y-1]})
+		} else {
+			steps = append(steps, step{Delete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		steps = append(steps, step{Equal, a[x-1]})
+		x--
+		y--
+	}
+
+	var edits []Edit
+	for i := len(steps) - 1; i >= 0; i-- {
+		edits = appendEdit(edits, steps[i].op, []rune{steps[i].r})
+	}
+	return edits
+}
@@ -0,0 +1,143 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunes(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Edit
+	}{
+		{"", "", nil},
+		{"春眠不觉晓", "春眠不觉晓", []Edit{{Equal, "春眠不觉晓"}}},
+		{"", "处处闻啼鸟", []Edit{{Insert, "处处闻啼鸟"}}},
+		{"处处闻啼鸟", "", []Edit{{Delete, "处处闻啼鸟"}}},
+		{"床前明月光", "床前看月光", []Edit{{Equal, "床前"}, {Delete, "明"}, {Insert, "看"}, {Equal, "月光"}}},
+		{"举头望明月", "举头望望明月", []Edit{{Equal, "举头望"}, {Insert, "望"}, {Equal, "明月"}}},
+		{"低头思故乡", "低头故乡", []Edit{{Equal, "低头"}, {Delete, "思"}, {Equal, "故乡"}}},
+		{"白日依山尽\n黄河入海流", "白日依山尽\n黄河入海流\n欲穷千里目", []Edit{{Equal, "白日依山尽\n黄河入海流"}, {Insert, "\n欲穷千里目"}}},
+		{"甲乙丙丁", "乙丁甲", []Edit{{Delete, "甲"}, {Equal, "乙"}, {Delete, "丙"}, {Equal, "丁"}, {Insert, "甲"}}},
+	}
+	for _, tt := range tests {
+		if got := Runes(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Runes(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// apply 返回编辑序列的原文和结果
+func apply(edits []Edit) (a, b string) {
+	var sa, sb strings.Builder
+	for _, e := range edits {
+		if e.Op != Insert {
+			sa.WriteString(e.Text)
+		}
+		if e.Op != Delete {
+			sb.WriteString(e.Text)
+		}
+	}
+	return sa.String(), sb.String()
+}
+
+// distance 返回编辑序列中插入和删除的字符数
+func distance(edits []Edit) int {
+	n := 0
+	for _, e := range edits {
+		if e.Op != Equal {
+			n += len([]rune(e.Text))
+		}
+	}
+	return n
+}
+
+// lcsDistance 用动态规划计算只有插入和删除时的最短编辑距离
+func lcsDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	dp := make([][]int, len(ra)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(rb)+1)
+	}
+	for i := len(ra) - 1; i >= 0; i-- {
+		for j := len(rb) - 1; j >= 0; j-- {
+			if ra[i] == rb[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return len(ra) + len(rb) - 2*dp[0][0]
+}
+
+// 编辑序列能还原两段文本，相邻片段类型不同，且编辑距离最短
+func TestRunesMinimal(t *testing.T) {
+	pairs := [][2]string{
+		{"ABCABBA", "CBABAC"},
+		{"国破山河在，城春草木深。", "国破山河在，城深草木春。"},
+		{"感时花溅泪，恨别鸟惊心。", "恨别鸟惊心，感时花溅泪。"},
+		{"烽火连三月，家书抵万金。", "烽火连三月\n家书抵万金"},
+		{"白头搔更短，浑欲不胜簪。", "白首搔更短，浑欲不胜簪"},
+		{strings.Repeat("一二三", 50), strings.Repeat("一三二", 50)},
+	}
+	for _, p := range pairs {
+		edits := Runes(p[0], p[1])
+		if a, b := apply(edits); a != p[0] || b != p[1] {
+			t.Errorf("Runes(%q, %q): applies to %q, %q", p[0], p[1], a, b)
+		}
+		for i := 1; i < len(edits); i++ {
+			if edits[i].Op == edits[i-1].Op {
+				t.Errorf("Runes(%q, %q): adjacent %s edits not merged", p[0], p[1], edits[i].Op)
+			}
+		}
+		if got, want := distance(edits), lcsDistance(p[0], p[1]); got != want {
+			t.Errorf("Runes(%q, %q): distance %d, want %d", p[0], p[1], got, want)
+		}
+	}
+}
+
+// 编辑距离超过 MaxEditDistance 时，公共前后缀之间整段删除再整段插入
+func TestRunesFallback(t *testing.T) {
+	tests := []struct {
+		n        int // 重复次数，编辑距离为 2n
+		fallback bool
+	}{
+		{MaxEditDistance / 2, false},
+		{MaxEditDistance/2 + 1, true},
+	}
+	for _, tt := range tests {
+		a := "首" + strings.Repeat("一甲", tt.n) + "尾"
+		b := "首" + strings.Repeat("一乙", tt.n) + "尾"
+		edits := Runes(a, b)
+		if ga, gb := apply(edits); ga != a || gb != b {
+			t.Errorf("n=%d: edits do not apply", tt.n)
+		}
+		want := []Edit{
+			{Equal, "首一"},
+			{Delete, strings.Repeat("甲一", tt.n-1) + "甲"},
+			{Insert, strings.Repeat("乙一", tt.n-1) + "乙"},
+			{Equal, "尾"},
+		}
+		if got := reflect.DeepEqual(edits, want); got != tt.fallback {
+			t.Errorf("n=%d: %d edits, fallback %v, want %v", tt.n, len(edits), got, tt.fallback)
+		}
+		if !tt.fallback && distance(edits) != 2*tt.n {
+			t.Errorf("n=%d: distance %d, want %d", tt.n, distance(edits), 2*tt.n)
+		}
+	}
+}
+
+// RunesWithin 以给定的编辑距离上限代替 MaxEditDistance
+func TestRunesWithin(t *testing.T) {
+	a, b := "床前明月光", "床前看月色"
+	want := []Edit{{Equal, "床前"}, {Delete, "明"}, {Insert, "看"}, {Equal, "月"}, {Delete, "光"}, {Insert, "色"}}
+	if got := RunesWithin(a, b, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("RunesWithin(%q, %q, 4) = %v, want %v", a, b, got, want)
+	}
+	want = []Edit{{Equal, "床前"}, {Delete, "明月光"}, {Insert, "看月色"}}
+	if got := RunesWithin(a, b, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("RunesWithin(%q, %q, 3) = %v, want %v", a, b, got, want)
+	}
+}
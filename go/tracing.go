@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"poetry/apierror"
+)
+
+// tracer 创建本服务的全部 span，setupTracing 之前为空实现
+var tracer = otel.Tracer("poetry")
+
+// setupTracing 按 POETRY_TRACE_EXPORTER 配置追踪导出：
+//
+//	none（默认）  只生成 trace ID 写入日志和错误响应，不导出
+//	otlp         通过 OTLP/HTTP 发送，地址等由 OTEL_EXPORTER_OTLP_* 环境变量设置
+//	stdout       以 JSON 输出到标准输出
+//	file         以 JSON 追加到 POETRY_TRACE_FILE（默认 traces.json）
+//
+// 返回的函数在退出前调用，导出缓冲中剩余的 span。
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	var opts []sdktrace.TracerProviderOption
+	var closeFile func() error
+
+	switch exporter := strings.ToLower(os.Getenv("POETRY_TRACE_EXPORTER")); exporter {
+	case "", "none":
+	case "otlp":
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "stdout":
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "file":
+		path := os.Getenv("POETRY_TRACE_FILE")
+		if path == "" {
+			path = "traces.json"
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("create file exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+		closeFile = f.Close
+	default:
+		return nil, fmt.Errorf("invalid POETRY_TRACE_EXPORTER %q", exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "poetry")),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME、OTEL_RESOURCE_ATTRIBUTES 可覆盖
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+	opts = append(opts, sdktrace.WithResource(res))
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+		return err
+	}, nil
+}
+
+// traceRequest 为每个请求创建一个 server span，沿用请求头 traceparent 中的上游追踪，
+// 并把 trace ID 放入上下文供日志和错误响应使用。
+func traceRequest(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+	route := c.FullPath()
+	name := c.Request.Method + " " + route
+	if route == "" {
+		name = c.Request.Method
+	}
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", c.Request.URL.Path),
+		),
+		trace.WithAttributes(routeAttrs(c)...),
+	)
+	defer span.End()
+
+	c.Request = c.Request.WithContext(ctx)
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		c.Set(apierror.TraceIDKey, sc.TraceID().String())
+	}
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	if n, ok := c.Get(resultCountKey); ok {
+		span.SetAttributes(attribute.Int("result_count", n.(int)))
+	}
+	if id := identity(c); id != nil {
+		span.SetAttributes(attribute.String("enduser.id", id.Name))
+	}
+}
+
+// routeAttrs 把路径中的实体 ID 记为 span 属性，如 /authors/:id 记为 author_id。
+func routeAttrs(c *gin.Context) []attribute.KeyValue {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil
+	}
+	switch route := apiRoute(c); {
+	case strings.HasPrefix(route, "/authors/"), strings.HasPrefix(route, "/submissions/authors/"):
+		return []attribute.KeyValue{attribute.Int("author_id", id)}
+	case strings.HasPrefix(route, "/poems/"), strings.HasPrefix(route, "/submissions/poems/"):
+		return []attribute.KeyValue{attribute.Int("poem_id", id)}
+	}
+	return nil
+}
+
+// traceAttrs 给当前请求的 span 添加属性，如 author_id。
+func traceAttrs(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
+
+// conn 是 *sql.DB 和 *sql.Tx 共有的方法，命名语句在事务内外的执行方式相同
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// query 执行命名查询，为其创建子 span 并记录耗时。name 用作指标标签和 span 名称，
+// 耗时和返回行数在 Close 时记录，覆盖读取结果的全过程。
+func query(ctx context.Context, name, q string, args ...any) (*tracedRows, error) {
+	return queryOn(ctx, db, name, q, args...)
+}
+
+// queryRow 执行只返回一行的命名查询，span 在 Scan 时结束。
+func queryRow(ctx context.Context, name, q string, args ...any) *tracedRow {
+	return queryRowOn(ctx, db, name, q, args...)
+}
+
+// exec 执行命名的写语句，为其创建子 span 并记录耗时。
+func exec(ctx context.Context, name, q string, args ...any) (sql.Result, error) {
+	return execOn(ctx, db, name, q, args...)
+}
+
+func queryOn(ctx context.Context, c conn, name, q string, args ...any) (*tracedRows, error) {
+	ctx, span := startQuery(ctx, name, q)
+	start := time.Now()
+	rows, err := c.QueryContext(ctx, q, args...)
+	if err != nil {
+		endQuery(span, name, start, 0, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, name: name, span: span, start: start}, nil
+}
+
+func queryRowOn(ctx context.Context, c conn, name, q string, args ...any) *tracedRow {
+	ctx, span := startQuery(ctx, name, q)
+	start := time.Now()
+	return &tracedRow{Row: c.QueryRowContext(ctx, q, args...), name: name, span: span, start: start}
+}
+
+func execOn(ctx context.Context, c conn, name, q string, args ...any) (sql.Result, error) {
+	ctx, span := startQuery(ctx, name, q)
+	start := time.Now()
+	result, err := c.ExecContext(ctx, q, args...)
+	dbQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err == nil {
+		n, _ := result.RowsAffected()
+		span.SetAttributes(attribute.Int64("db.response.affected_rows", n))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// tracedTx 是写事务，其中的语句是开始事务时所在 span 的子 span。
+// 事务不随请求取消：客户端断开后已开始的修改照常提交或回滚，与不带 context 的 db.Begin 相同。
+type tracedTx struct {
+	*sql.Tx
+	ctx context.Context
+}
+
+// begin 开始写事务，ctx 用于创建子 span。
+func begin(ctx context.Context) (*tracedTx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return withTx(ctx, tx), nil
+}
+
+// withTx 为已开始的事务（如数据库结构变更中的事务）添加追踪。
+func withTx(ctx context.Context, tx *sql.Tx) *tracedTx {
+	return &tracedTx{Tx: tx, ctx: context.WithoutCancel(ctx)}
+}
+
+func (tx *tracedTx) query(name, q string, args ...any) (*tracedRows, error) {
+	return queryOn(tx.ctx, tx.Tx, name, q, args...)
+}
+
+func (tx *tracedTx) queryRow(name, q string, args ...any) *tracedRow {
+	return queryRowOn(tx.ctx, tx.Tx, name, q, args...)
+}
+
+func (tx *tracedTx) exec(name, q string, args ...any) (sql.Result, error) {
+	return execOn(tx.ctx, tx.Tx, name, q, args...)
+}
+
+func startQuery(ctx context.Context, name, q string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "db "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "sqlite"),
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", q),
+		),
+	)
+}
+
+func endQuery(span trace.Span, name string, start time.Time, n int, err error) {
+	dbQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.Int("db.response.returned_rows", n))
+	endSpan(span, err)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedRows 统计读取的行数，Close 时结束 span。
+type tracedRows struct {
+	*sql.Rows
+	name  string
+	span  trace.Span
+	start time.Time
+	n     int
+	done  bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		r.n++
+		return true
+	}
+	return false
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if !r.done {
+		r.done = true
+		endQuery(r.span, r.name, r.start, r.n, errors.Join(r.Rows.Err(), err))
+	}
+	return err
+}
+
+// tracedRow 在 Scan 时结束 span。
+type tracedRow struct {
+	*sql.Row
+	name  string
+	span  trace.Span
+	start time.Time
+}
+
+func (r *tracedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	n := 1
+	if err != nil {
+		n = 0
+	}
+	endQuery(r.span, r.name, r.start, n, err)
+	return err
+}
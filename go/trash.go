@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 回收站中的条目类型，与 /trash/:type 路径参数一致
+const (
+	trashAuthors = "authors"
+	trashPoems   = "poems"
+)
+
+type TrashItem struct {
+	Type      string `json:"type"`
+	ID        int    `json:"id"`
+	Title     string `json:"title"` // 作者名或诗题
+	DeletedAt string `json:"deleted_at"`
+	DeletedBy string `json:"deleted_by"`
+}
+
+const trashQuery = `
+	SELECT type, id, title, deleted_at, deleted_by FROM (
+		SELECT 'authors' AS type, author_id AS id, name AS title, deleted_at, COALESCE(deleted_by, '') AS deleted_by
+		FROM Authors WHERE deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'poems', poem_id, title, deleted_at, COALESCE(deleted_by, '')
+		FROM Poems WHERE deleted_at IS NOT NULL
+	) WHERE ? = '' OR type = ?`
+
+// 获取回收站列表（分页），可用 ?type=authors|poems 过滤
+func getTrash(c *gin.Context) {
+	typ := c.Query("type")
+	if typ != "" && typ != trashAuthors && typ != trashPoems {
+		apierror.Write(c, apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "type", "allowed": []string{trashAuthors, trashPoems}}))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	const pageSize = 20
+	offset := (page - 1) * pageSize
+
+	var total int
+	err := queryRow(c.Request.Context(), "count_trash", "SELECT COUNT(*) FROM ("+trashQuery+")", typ, typ).Scan(&total)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("count trash: %w", err)))
+		return
+	}
+
+	rows, err := query(c.Request.Context(), "list_trash", trashQuery+" ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?", typ, typ, pageSize, offset)
+	if err != nil {
+		apierror.Write(c, apierror.Storage(fmt.Errorf("query trash: %w", err)))
+		return
+	}
+	defer rows.Close()
+
+	items := []TrashItem{}
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Type, &item.ID, &item.Title, &item.DeletedAt, &item.DeletedBy); err != nil {
+			apierror.Write(c, apierror.Storage(fmt.Errorf("scan row: %w", err)))
+			return
+		}
+		items = append(items, item)
+	}
+
+	setResultCount(c, len(items))
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"data":      items,
+	})
+}
+
+// 从回收站恢复作者或诗作。恢复作者时一并恢复与其同时删除的诗作。
+func restoreTrash(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var (
+		resp gin.H
+		err  error
+	)
+	switch typ := c.Param("type"); typ {
+	case trashAuthors:
+		var restored int64
+		restored, err = restoreAuthor(c.Request.Context(), id, auditOf(c))
+		resp = gin.H{"message": "Author restored", "poems_restored": restored}
+	case trashPoems:
+		err = restorePoem(c.Request.Context(), id, auditOf(c))
+		resp = gin.H{"message": "Poem restored"}
+	default:
+		err = apierror.Validation(apierror.CodeInvalidParam, gin.H{"param": "type", "allowed": []string{trashAuthors, trashPoems}})
+	}
+	if err != nil {
+		apierror.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func restoreAuthor(ctx context.Context, id int, a requestAudit) (int64, error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var author Author
+	var imgUrl sql.NullString
+	var deletedAt string
+	err = tx.queryRow("get_deleted_author", "SELECT name, description, imgUrl, deleted_at FROM Authors WHERE author_id = ? AND deleted_at IS NOT NULL", id).
+		Scan(&author.Name, &author.Description, &imgUrl, &deletedAt)
+	if err == sql.ErrNoRows {
+		return 0, apierror.NotFound(apierror.CodeNotInTrash, gin.H{"type": trashAuthors, "id": id})
+	}
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("query author %d: %w", id, err))
+	}
+
+	author.ImgUrl = imgUrl.String
+
+	if _, err := tx.exec("restore_author", "UPDATE Authors SET deleted_at = NULL, deleted_by = NULL WHERE author_id = ?", id); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("restore author %d: %w", id, err))
+	}
+	if err := a.record(tx, auditRestore, entityAuthor, int64(id), nil, authorFields(author)); err != nil {
+		return 0, apierror.Storage(err)
+	}
+
+	rows, err := tx.query("deleted_author_poems", "SELECT poem_id, title, author_id, content FROM Poems WHERE author_id = ? AND deleted_at = ?", id, deletedAt)
+	if err != nil {
+		return 0, apierror.Storage(fmt.Errorf("query poems for author %d: %w", id, err))
+	}
+	var poems []Poem
+	for rows.Next() {
+		var p Poem
+		if err := rows.Scan(&p.PoemID, &p.Title, &p.AuthorID, &p.Content); err != nil {
+			rows.Close()
+			return 0, apierror.Storage(fmt.Errorf("scan poem: %w", err))
+		}
+		poems = append(poems, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, apierror.Storage(err)
+	}
+
+	if _, err := tx.exec("restore_author_poems", "UPDATE Poems SET deleted_at = NULL, deleted_by = NULL WHERE author_id = ? AND deleted_at = ?", id, deletedAt); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("restore poems for author %d: %w", id, err))
+	}
+	for _, p := range poems {
+		if err := a.record(tx, auditRestore, entityPoem, int64(p.PoemID), nil, poemFields(p)); err != nil {
+			return 0, apierror.Storage(err)
+		}
+	}
+	restored := int64(len(poems))
+
+	if err := tx.Commit(); err != nil {
+		return 0, apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityAuthor, id)
+	invalidate(entityPoem, poemIDs(poems)...)
+	return restored, nil
+}
+
+func restorePoem(ctx context.Context, id int, a requestAudit) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var poem Poem
+	var authorDeleted bool
+	err = tx.queryRow("get_deleted_poem", `SELECT p.poem_id, p.title, p.author_id, p.content, a.deleted_at IS NOT NULL
+		FROM Poems p JOIN Authors a ON a.author_id = p.author_id
+		WHERE p.poem_id = ? AND p.deleted_at IS NOT NULL`, id).Scan(&poem.PoemID, &poem.Title, &poem.AuthorID, &poem.Content, &authorDeleted)
+	if err == sql.ErrNoRows {
+		return apierror.NotFound(apierror.CodeNotInTrash, gin.H{"type": trashPoems, "id": id})
+	}
+	if err != nil {
+		return apierror.Storage(fmt.Errorf("query poem %d: %w", id, err))
+	}
+	if authorDeleted {
+		// 作者仍在回收站中，需先恢复作者
+		return apierror.Conflict(apierror.CodeAuthorDeleted, gin.H{"author_id": poem.AuthorID})
+	}
+
+	if _, err := tx.exec("restore_poem", "UPDATE Poems SET deleted_at = NULL, deleted_by = NULL WHERE poem_id = ?", id); err != nil {
+		return apierror.Storage(fmt.Errorf("restore poem %d: %w", id, err))
+	}
+	if err := a.record(tx, auditRestore, entityPoem, int64(id), nil, poemFields(poem)); err != nil {
+		return apierror.Storage(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return apierror.Storage(fmt.Errorf("commit: %w", err))
+	}
+	invalidate(entityPoem, id)
+	return nil
+}
+
+// runPurge 永久删除在回收站中超过指定天数的作者和诗作。
+func runPurge(args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	days := fs.Int("days", 30, "永久删除移入回收站超过该天数的数据")
+	dryRun := fs.Bool("dry-run", false, "只统计数量，不删除")
+	fs.Parse(args)
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -*days).Format(time.RFC3339)
+
+	tx, err := begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 作者下仍有未删除的诗作时不清除作者，其余诗作随作者一起清除
+	const purgeAuthors = `FROM Authors WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM Poems p WHERE p.author_id = Authors.author_id AND p.deleted_at IS NULL)`
+	const purgePoems = `FROM Poems WHERE deleted_at IS NOT NULL
+		AND (deleted_at < ? OR author_id IN (SELECT author_id ` + purgeAuthors + `))`
+
+	// 永久删除前把被删除的内容写入审计记录
+	a := cliAudit("purge")
+	poemRows, err := tx.query("purgeable_poems", "SELECT poem_id, title, author_id, content "+purgePoems, cutoff, cutoff)
+	if err != nil {
+		return fmt.Errorf("query purgeable poems: %w", err)
+	}
+	var poems []Poem
+	for poemRows.Next() {
+		var p Poem
+		if err := poemRows.Scan(&p.PoemID, &p.Title, &p.AuthorID, &p.Content); err != nil {
+			poemRows.Close()
+			return err
+		}
+		poems = append(poems, p)
+	}
+	poemRows.Close()
+	for _, p := range poems {
+		if err := a.record(tx, auditPurge, entityPoem, int64(p.PoemID), poemFields(p), nil); err != nil {
+			return err
+		}
+	}
+
+	authorRows, err := tx.query("purgeable_authors", "SELECT author_id, name, description, COALESCE(imgUrl, '') "+purgeAuthors, cutoff)
+	if err != nil {
+		return fmt.Errorf("query purgeable authors: %w", err)
+	}
+	var authors []Author
+	for authorRows.Next() {
+		var au Author
+		if err := authorRows.Scan(&au.AuthorID, &au.Name, &au.Description, &au.ImgUrl); err != nil {
+			authorRows.Close()
+			return err
+		}
+		authors = append(authors, au)
+	}
+	authorRows.Close()
+	for _, au := range authors {
+		if err := a.record(tx, auditPurge, entityAuthor, int64(au.AuthorID), authorFields(au), nil); err != nil {
+			return err
+		}
+	}
+
+	poemResult, err := tx.exec("purge_poems", "DELETE "+purgePoems, cutoff, cutoff)
+	if err != nil {
+		return fmt.Errorf("purge poems: %w", err)
+	}
+	authorResult, err := tx.exec("purge_authors", "DELETE "+purgeAuthors, cutoff)
+	if err != nil {
+		return fmt.Errorf("purge authors: %w", err)
+	}
+	poemCount, _ := poemResult.RowsAffected()
+	authorCount, _ := authorResult.RowsAffected()
+
+	if *dryRun {
+		fmt.Printf("Would purge %d authors and %d poems deleted before %s\n", authorCount, poemCount, cutoff)
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d authors and %d poems deleted before %s\n", authorCount, poemCount, cutoff)
+	return nil
+}
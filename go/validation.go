@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"poetry/apierror"
+)
+
+// 字段长度上限（按字符计），留有余量以容纳语料中最长的记录。
+const (
+	maxAuthorNameLen  = 50
+	maxDescriptionLen = 5000
+	maxImgURLLen      = 2048
+	maxPoemTitleLen   = 500
+	maxPoemContentLen = 20000
+)
+
+// FieldError 描述一个未通过校验的字段，作为 validation_failed 的 details 返回。
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Max   int    `json:"max,omitempty"`
+	Line  int    `json:"line,omitempty"`
+}
+
+type fieldErrors []FieldError
+
+func (fe *fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		*fe = append(*fe, FieldError{Field: field, Rule: "required"})
+	}
+}
+
+func (fe *fieldErrors) maxLen(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		*fe = append(*fe, FieldError{Field: field, Rule: "max_length", Max: max})
+	}
+}
+
+func (fe fieldErrors) err() error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return apierror.Validation(apierror.CodeValidationFailed, gin.H{"fields": []FieldError(fe)})
+}
+
+// validateAuthor 校验作者的可写字段。
+func validateAuthor(a Author) error {
+	var fe fieldErrors
+	fe.required("name", a.Name)
+	fe.maxLen("name", a.Name, maxAuthorNameLen)
+	fe.maxLen("description", a.Description, maxDescriptionLen)
+	fe.maxLen("imgUrl", a.ImgUrl, maxImgURLLen)
+	return fe.err()
+}
+
+// validatePoem 校验诗作的可写字段，并确认 author_id 指向存在的作者。
+// 只用于写入前的预检，在事务中写入的调用方使用 validatePoemTx。
+func validatePoem(ctx context.Context, p Poem) error {
+	return validatePoemOn(ctx, db, p)
+}
+
+// validatePoemTx 在事务中校验诗作，作者是否存在与随后的写入读到同一份数据。
+func validatePoemTx(tx *tracedTx, p Poem) error {
+	return validatePoemOn(tx.ctx, tx.Tx, p)
+}
+
+func validatePoemOn(ctx context.Context, c conn, p Poem) error {
+	fe := poemFieldErrors(p)
+	if p.AuthorID < 1 {
+		fe = append(fe, FieldError{Field: "author_id", Rule: "required"})
+	}
+	if err := fe.err(); err != nil {
+		return err
+	}
+
+	exists, err := authorExistsOn(ctx, c, p.AuthorID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apierror.Validation(apierror.CodeUnknownAuthor, gin.H{"author_id": p.AuthorID})
+	}
+	return nil
+}
+
+// poemFieldErrors 校验诗作的标题和内容。
+func poemFieldErrors(p Poem) fieldErrors {
+	var fe fieldErrors
+	fe.required("title", p.Title)
+	fe.maxLen("title", p.Title, maxPoemTitleLen)
+	fe.required("content", p.Content)
+	fe.maxLen("content", p.Content, maxPoemContentLen)
+	if strings.TrimSpace(p.Content) != "" {
+		// 内容按行存储，每一行都不能为空
+		for i, line := range strings.Split(strings.TrimRight(p.Content, "\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				fe = append(fe, FieldError{Field: "content", Rule: "empty_line", Line: i + 1})
+				break
+			}
+		}
+	}
+	return fe
+}
+
+func authorExistsOn(ctx context.Context, c conn, id int) (bool, error) {
+	var one int
+	err := queryRowOn(ctx, c, "author_exists", "SELECT 1 FROM Authors WHERE author_id = ? AND deleted_at IS NULL", id).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, apierror.Storage(fmt.Errorf("query author %d: %w", id, err))
+	}
+	return true, nil
+}
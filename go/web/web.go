@@ -0,0 +1,136 @@
+// Package web 提供编译进二进制的前端页面（poet-ui 的构建产物）。
+//
+// 在 poet-ui 目录执行 npm run build 后，产物写入本包的 dist 目录，随 go build 嵌入。
+// 静态文件存在 .br 或 .gz 预压缩版本时，按请求的 Accept-Encoding 直接返回压缩后的内容。
+// 其余路径返回 index.html，由前端路由（history 模式）处理。
+package web
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// 预压缩文件的扩展名，按优先级排列
+var encodings = []struct {
+	name, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// UI 返回前端页面。
+type UI struct {
+	files fs.FS
+	etags map[string]string // 文件名到 ETag，预压缩文件各自计算
+}
+
+// New 返回嵌入的前端页面，dist 中没有 index.html（未构建前端）时返回 nil。
+func New() (*UI, error) {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(files, "index.html"); err != nil {
+		return nil, nil
+	}
+
+	u := &UI{files: files, etags: map[string]string{}}
+	err = fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		u.etags[name] = `"` + hex.EncodeToString(sum[:16]) + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Serve 返回请求的静态文件，没有对应文件时返回 index.html。
+// 请求的是不存在的静态资源（路径带扩展名，如 /assets/app.js）时不处理，返回 false。
+func (u *UI) Serve(w http.ResponseWriter, r *http.Request) bool {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	if _, ok := u.etags[name]; !ok {
+		if path.Ext(name) != "" {
+			return false
+		}
+		name = "index.html"
+	}
+	u.serveFile(w, r, name)
+	return true
+}
+
+func (u *UI) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	h := w.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	// 构建产物 assets/ 下的文件名带有内容哈希，可以长期缓存；index.html 等每次确认是否更新
+	if strings.HasPrefix(name, "assets/") {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+
+	file := name
+	for _, enc := range encodings {
+		if _, ok := u.etags[name+enc.ext]; !ok {
+			continue
+		}
+		h.Set("Vary", "Accept-Encoding")
+		if acceptsEncoding(r, enc.name) {
+			file = name + enc.ext
+			h.Set("Content-Encoding", enc.name)
+			break
+		}
+	}
+	h.Set("ETag", u.etags[file])
+
+	f, err := u.files.Open(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	// embed.FS 的文件都实现了 io.Seeker；嵌入的文件没有修改时间，缓存校验使用 ETag
+	http.ServeContent(w, r, name, time.Time{}, f.(io.ReadSeeker))
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受 enc，q=0 视为不接受。
+func acceptsEncoding(r *http.Request, enc string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), enc) {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		v, err := strconv.ParseFloat(q, 64)
+		return err == nil && v > 0
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+import axios from 'axios';
+
+// 基础 URL
+const BASE_URL = '/api';
+
+// 令牌缓存在 localStorage，过期前重复使用
+const TOKEN_KEY = 'poetry_token';
+
+// 获取写接口所需的 JWT：没有或已过期时提示输入 editor 的 API Key，换取新令牌
+export const getToken = async () => {
+  const saved = JSON.parse(localStorage.getItem(TOKEN_KEY) || 'null');
+  if (saved && new Date(saved.expires_at) > new Date()) {
+    return saved.token;
+  }
+  const apiKey = window.prompt('修改数据需要编辑权限，请输入 API Key');
+  if (!apiKey) {
+    throw new Error('API Key is required');
+  }
+  const response = await axios.post(`${BASE_URL}/auth/token`, null, {
+    headers: { 'X-API-Key': apiKey.trim() },
+  });
+  localStorage.setItem(TOKEN_KEY, JSON.stringify(response.data));
+  return response.data.token;
+};
+
+// 令牌被拒绝（401）后清除，下次写操作时重新获取
+export const clearToken = () => {
+  localStorage.removeItem(TOKEN_KEY);
+};
+
+// 写请求携带的认证头
+export const authHeaders = async () => ({
+  Authorization: `Bearer ${await getToken()}`,
+});
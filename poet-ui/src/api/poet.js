@@ -1,3 +1,5 @@
+import { authHeaders, clearToken } from './auth';
+
 // 基础 URL
 const BASE_URL = '/api';
 
@@ -8,9 +10,13 @@ export const createAuthor = async (authorData) => {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        ...(await authHeaders()),
       },
       body: JSON.stringify(authorData),
     });
+    if (response.status === 401) {
+      clearToken();
+    }
     if (!response.ok) {
       throw new Error(`HTTP error! status: ${response.status}`);
     }
@@ -70,9 +76,13 @@ export const updateAuthor = async (authorId, authorData) => {
       method: 'PUT',
       headers: {
         'Content-Type': 'application/json',
+        ...(await authHeaders()),
       },
       body: JSON.stringify(authorData),
     });
+    if (response.status === 401) {
+      clearToken();
+    }
     if (!response.ok) {
       throw new Error(`HTTP error! status: ${response.status}`);
     }
@@ -88,7 +98,11 @@ export const deleteAuthor = async (authorId) => {
   try {
     const response = await fetch(`${BASE_URL}/authors/${authorId}`, {
       method: 'DELETE',
+      headers: await authHeaders(),
     });
+    if (response.status === 401) {
+      clearToken();
+    }
     if (!response.ok) {
       throw new Error(`HTTP error! status: ${response.status}`);
     }
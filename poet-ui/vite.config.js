@@ -1,9 +1,46 @@
 import { defineConfig } from 'vite'
 import vue from '@vitejs/plugin-vue'
+import { readdirSync, readFileSync, statSync, writeFileSync } from 'node:fs'
+import { join, extname } from 'node:path'
+import { brotliCompressSync, gzipSync, constants } from 'node:zlib'
+
+// 构建产物输出到 Go 服务的 web/dist，随 go build 嵌入二进制
+const outDir = '../go/web/dist'
+
+// 为文本类资源生成 .br 和 .gz 预压缩文件，由 Go 服务按 Accept-Encoding 直接返回
+const compressible = ['.html', '.js', '.css', '.svg', '.json', '.txt', '.xml', '.wasm']
+
+function precompress() {
+  return {
+    name: 'precompress',
+    apply: 'build',
+    closeBundle() {
+      const walk = (dir) => {
+        for (const name of readdirSync(dir)) {
+          const file = join(dir, name)
+          if (statSync(file).isDirectory()) {
+            walk(file)
+            continue
+          }
+          if (!compressible.includes(extname(name))) continue
+          const data = readFileSync(file)
+          if (data.length < 1024) continue
+          writeFileSync(file + '.br', brotliCompressSync(data, {
+            params: { [constants.BROTLI_PARAM_QUALITY]: constants.BROTLI_MAX_QUALITY },
+          }))
+          writeFileSync(file + '.gz', gzipSync(data, { level: 9 }))
+        }
+      }
+      walk(outDir)
+      // 保留占位文件，未构建前端时 go build 也能通过
+      writeFileSync(join(outDir, '.gitkeep'), '')
+    },
+  }
+}
 
 // https://vite.dev/config/
 export default defineConfig({
-  plugins: [vue()],
+  plugins: [vue(), precompress()],
   resolve: {
     alias: {
       '@': '/src',
@@ -12,13 +49,16 @@ export default defineConfig({
   optimizeDeps: {
     include: ['three']
   },
+  build: {
+    outDir,
+    emptyOutDir: true,
+  },
   server: {
     proxy: {
-      // 匹配所有以 /api 开头的路径
+      // 开发时把 /api 转发给 Go 服务，接口本身就挂在 /api 下
       '/api': {
         target: 'http://localhost:8080',
         changeOrigin: true,
-        rewrite: (path) => path.replace(/^\/api/, ''),
         secure: false, // 如果是HTTPS目标，则设置为true
       }
     }